@@ -0,0 +1,13 @@
+// Package main is the entry point for the emailvalidator command-line tool.
+// It validates emails, lists of emails, typos and domains without running the HTTP server.
+package main
+
+import (
+	"os"
+
+	"emailvalidator/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.New().Run(os.Args[1:]))
+}
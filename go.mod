@@ -6,6 +6,9 @@ require (
 	github.com/prometheus/client_golang v1.19.0
 	github.com/redis/go-redis/v9 v9.7.3
 	github.com/stretchr/testify v1.8.4
+	golang.org/x/net v0.20.0
+	golang.org/x/sync v0.6.0
+	golang.org/x/text v0.14.0
 )
 
 require (
@@ -3,8 +3,15 @@
 package api
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"emailvalidator/internal/model"
@@ -41,6 +48,20 @@ var (
 	)
 )
 
+const (
+	// maxUploadSize limits the size of uploaded email lists
+	maxUploadSize = 32 << 20
+	// uploadMemoryLimit is how much of an upload is kept in memory before spilling to disk
+	uploadMemoryLimit = 8 << 20
+	// ndjsonContentType is the media type of streamed batch results
+	ndjsonContentType = "application/x-ndjson"
+	// streamWriteTimeout bounds the time to write each line of a streamed response
+	streamWriteTimeout = 10 * time.Second
+	// uploadReadTimeout bounds the time to receive an upload, which for large files on an ordinary
+	// uplink takes far longer than the server's read timeout
+	uploadReadTimeout = 2 * time.Minute
+)
+
 // Handler handles all HTTP requests
 type Handler struct {
 	emailService *service.EmailService
@@ -57,8 +78,11 @@ func NewHandler(emailService *service.EmailService) *Handler {
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/validate", h.HandleValidate)
 	mux.HandleFunc("/validate/batch", h.HandleBatchValidate)
+	mux.HandleFunc("/validate/upload", h.HandleUpload)
 	mux.HandleFunc("/typo-suggestions", h.HandleTypoSuggestions)
 	mux.HandleFunc("/status", h.HandleStatus)
+	mux.HandleFunc("/jobs", h.HandleJobs)
+	mux.HandleFunc("/jobs/", h.HandleJob)
 }
 
 // sendError sends a JSON error response
@@ -83,6 +107,10 @@ func (h *Handler) HandleValidate(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		req.Email = email
+		req.Profile = r.URL.Query().Get("profile")
+		req.Explain = queryBool(r, "explain")
+		req.Mode = r.URL.Query().Get("mode")
+		req.Checks = splitList(r.URL.Query()["checks"])
 	case http.MethodPost:
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			sendError(w, http.StatusBadRequest, "Invalid request body")
@@ -93,7 +121,16 @@ func (h *Handler) HandleValidate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result := h.emailService.ValidateEmail(req.Email)
+	result, err := h.emailService.ValidateEmailWithOptions(r.Context(), req.Email, service.ValidationOptions{
+		Profile: req.Profile,
+		Explain: req.Explain,
+		Mode:    req.Mode,
+		Checks:  req.Checks,
+	})
+	if err != nil {
+		sendValidationError(w, err)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(result); err != nil {
@@ -116,6 +153,10 @@ func (h *Handler) HandleBatchValidate(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		req.Emails = emails
+		req.Profile = r.URL.Query().Get("profile")
+		req.Explain = queryBool(r, "explain")
+		req.Mode = r.URL.Query().Get("mode")
+		req.Checks = splitList(r.URL.Query()["checks"])
 	case http.MethodPost:
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			sendError(w, http.StatusBadRequest, "Invalid request body")
@@ -126,7 +167,19 @@ func (h *Handler) HandleBatchValidate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result := h.emailService.ValidateEmails(req.Emails)
+	opts := service.ValidationOptions{Profile: req.Profile, Explain: req.Explain, Mode: req.Mode, Checks: req.Checks}
+	if acceptsNDJSON(r) {
+		h.streamBatchValidate(w, r, req.Emails, opts)
+		batchSize.Observe(float64(len(req.Emails)))
+		batchProcessingTime.Observe(time.Since(start).Seconds())
+		return
+	}
+
+	result, err := h.emailService.ValidateEmailsWithOptions(r.Context(), req.Emails, opts)
+	if err != nil {
+		sendValidationError(w, err)
+		return
+	}
 
 	batchSize.Observe(float64(len(req.Emails)))
 	batchProcessingTime.Observe(time.Since(start).Seconds())
@@ -137,6 +190,156 @@ func (h *Handler) HandleBatchValidate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// streamBatchValidate writes each validation result as an NDJSON line as soon as it is ready,
+// followed by a summary line. Every line is flushed and extends the write deadline,
+// so long batches aren't cut off by the server's write timeout.
+func (h *Handler) streamBatchValidate(w http.ResponseWriter, r *http.Request, emails []string, opts service.ValidationOptions) {
+	// Check the options before the status line is sent, so a bad profile or mode is still a 400
+	if err := h.emailService.CheckValidationOptions(opts); err != nil {
+		sendValidationError(w, err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	encoder := json.NewEncoder(w)
+	writeLine := func(v interface{}) error {
+		// Not every writer supports deadlines; the server timeout applies then
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	summary, err := h.emailService.ValidateEmailsStreamWithOptions(r.Context(), emails, opts, func(result model.EmailValidationResponse) error {
+		return writeLine(result)
+	})
+	if err != nil {
+		// The status line is already sent, so the client sees a truncated stream without a summary
+		return
+	}
+	_ = writeLine(model.BatchSummaryLine{Summary: summary})
+}
+
+// acceptsNDJSON reports whether the client asked for a newline-delimited JSON stream
+func acceptsNDJSON(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err == nil && mediaType == ndjsonContentType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HandleUpload handles multipart uploads of CSV or plain-text email lists.
+// The file is returned as CSV with status, score and reason columns appended.
+func (h *Handler) HandleUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Not every writer supports deadlines; the server timeouts apply then
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Now().Add(uploadReadTimeout))
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	err := r.ParseMultipartForm(uploadMemoryLimit)
+	// The server's write deadline counts from the request headers, so it may have passed during the upload
+	_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "Invalid multipart form or file too large")
+		return
+	}
+	defer func() {
+		_ = r.MultipartForm.RemoveAll()
+	}()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "File field is required")
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	opts := service.ValidationOptions{
+		Profile: r.FormValue("profile"),
+		Mode:    r.FormValue("mode"),
+		Checks:  splitList(r.MultipartForm.Value["checks"]),
+	}
+	name := strings.TrimSuffix(filepath.Base(header.Filename), filepath.Ext(header.Filename))
+	if name == "" || name == "." {
+		name = "emails"
+	}
+	out := &csvDownloadWriter{w: w, rc: rc, filename: name + "-validated.csv"}
+	if isPlainText(header) {
+		_, err = h.emailService.ValidateTextWithOptions(r.Context(), file, out, opts)
+	} else {
+		_, err = h.emailService.ValidateCSVWithOptions(r.Context(), file, out, r.FormValue("column"), opts)
+	}
+	switch {
+	case err != nil && !out.started:
+		// Not every writer supports deadlines; the server timeout applies then
+		_ = out.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		sendUploadError(w, err)
+	case err != nil:
+		// The file is partly sent, so the client sees it truncated
+		return
+	default:
+		out.start()
+	}
+}
+
+// csvDownloadWriter streams a validated file to the client as a CSV attachment.
+// The headers go out with the first chunk, so errors found before it still get an error status,
+// and the write deadline is extended for every chunk, as large files take longer than the server timeout
+type csvDownloadWriter struct {
+	w        http.ResponseWriter
+	rc       *http.ResponseController
+	filename string
+	started  bool
+}
+
+// start sends the headers of the download unless they are already sent
+func (d *csvDownloadWriter) start() {
+	if d.started {
+		return
+	}
+	d.started = true
+	d.w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	d.w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": d.filename}))
+	d.w.WriteHeader(http.StatusOK)
+}
+
+// Write sends a chunk of the file and flushes it to the client
+func (d *csvDownloadWriter) Write(p []byte) (int, error) {
+	d.start()
+	// Not every writer supports deadlines; the server timeout applies then
+	_ = d.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	n, err := d.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, d.rc.Flush()
+}
+
+// isPlainText reports whether an uploaded file is a plain-text list rather than CSV
+func isPlainText(header *multipart.FileHeader) bool {
+	if strings.EqualFold(filepath.Ext(header.Filename), ".txt") {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(header.Header.Get("Content-Type"))
+	return mediaType == "text/plain"
+}
+
 // HandleTypoSuggestions handles email typo suggestion requests
 func (h *Handler) HandleTypoSuggestions(w http.ResponseWriter, r *http.Request) {
 	var req model.TypoSuggestionRequest
@@ -159,7 +362,7 @@ func (h *Handler) HandleTypoSuggestions(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	result := h.emailService.GetTypoSuggestions(req.Email)
+	result := h.emailService.GetTypoSuggestions(r.Context(), req.Email)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(result); err != nil {
@@ -181,3 +384,154 @@ func (h *Handler) HandleStatus(w http.ResponseWriter, r *http.Request) {
 		sendError(w, http.StatusInternalServerError, "Failed to encode response")
 	}
 }
+
+// HandleJobs handles submissions of asynchronous batch validation jobs
+func (h *Handler) HandleJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.BatchValidationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	job, err := h.emailService.SubmitJobWithOptions(r.Context(), req.Emails, service.ValidationOptions{
+		Profile: req.Profile,
+		Explain: req.Explain,
+		Mode:    req.Mode,
+		Checks:  req.Checks,
+	})
+	if err != nil {
+		sendJobError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		sendError(w, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+// HandleJob handles progress (/jobs/{id}) and results (/jobs/{id}/results) requests of a job
+func (h *Handler) HandleJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/jobs/"), "/")
+	if id == "" {
+		sendError(w, http.StatusNotFound, "Job not found")
+		return
+	}
+
+	var result interface{}
+	var err error
+	switch rest {
+	case "":
+		result, err = h.emailService.GetJob(r.Context(), id)
+	case "results":
+		offset, limit, parseErr := parsePagination(r)
+		if parseErr != nil {
+			sendError(w, http.StatusBadRequest, parseErr.Error())
+			return
+		}
+		result, err = h.emailService.GetJobResults(r.Context(), id, offset, limit)
+	default:
+		sendError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	if err != nil {
+		sendJobError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		sendError(w, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+// queryBool reads an optional boolean query parameter such as explain=true; invalid values count as false
+func queryBool(r *http.Request, name string) bool {
+	value, _ := strconv.ParseBool(r.URL.Query().Get(name))
+	return value
+}
+
+// splitList splits repeated and comma-separated values, such as checks=syntax,typo&checks=mx, dropping empty ones
+func splitList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// parsePagination reads the optional offset and limit query parameters
+func parsePagination(r *http.Request) (int, int, error) {
+	query := r.URL.Query()
+	offset, limit := 0, 0
+
+	if value := query.Get("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, 0, errors.New("offset must be a non-negative integer")
+		}
+		offset = n
+	}
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return 0, 0, errors.New("limit must be a positive integer")
+		}
+		limit = n
+	}
+
+	return offset, limit, nil
+}
+
+// sendValidationError maps validation option errors to HTTP responses
+func sendValidationError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrUnknownProfile) || errors.Is(err, service.ErrInvalidChecks) {
+		sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sendError(w, http.StatusInternalServerError, "Failed to validate")
+}
+
+// sendUploadError maps errors of validating an uploaded file to HTTP responses.
+// Unusable files and options are client errors; anything else, such as a cancelled request, is not
+func sendUploadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrEmptyFile), errors.Is(err, service.ErrEmailColumnNotFound),
+		errors.Is(err, service.ErrInvalidCSV), errors.Is(err, bufio.ErrTooLong):
+		sendError(w, http.StatusBadRequest, err.Error())
+	default:
+		sendValidationError(w, err)
+	}
+}
+
+// sendJobError maps job service errors to HTTP responses
+func sendJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrJobNotFound):
+		sendError(w, http.StatusNotFound, "Job not found")
+	case errors.Is(err, service.ErrNoEmails), errors.Is(err, service.ErrTooManyEmails),
+		errors.Is(err, service.ErrUnknownProfile), errors.Is(err, service.ErrInvalidChecks):
+		sendError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrJobQueueFull):
+		w.Header().Set("Retry-After", "30")
+		sendError(w, http.StatusServiceUnavailable, "Job queue is full, try again later")
+	default:
+		sendError(w, http.StatusInternalServerError, "Failed to process job")
+	}
+}
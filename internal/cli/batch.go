@@ -0,0 +1,340 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"emailvalidator/internal/model"
+	"emailvalidator/internal/service"
+)
+
+// Input formats of the batch command
+const (
+	inputCSV    = "csv"
+	inputNDJSON = "ndjson"
+	inputText   = "text"
+)
+
+// batchChunkSize is the number of emails validated together by the batch command
+const batchChunkSize = 1000
+
+// runBatch validates a list of emails read from a file or standard input
+func (c *CLI) runBatch(args []string) (int, error) {
+	fs, common := c.newFlagSet("batch", "--in FILE --out FILE", "")
+	in := fs.String("in", "-", "input file, - for standard input")
+	out := fs.String("out", "-", "output file, - for standard output")
+	format := fs.String("format", "", "input format: csv, ndjson or text (default from the --in extension, text for standard input)")
+	column := fs.String("column", "", "email column of CSV input, by header name or 1-based position (default detected)")
+	fs.Lookup("output").Usage = "output format: json (one result per line), table or csv (default from the --out extension, table for standard output)"
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return ExitUsage, err
+	}
+
+	inputFormat := *format
+	if inputFormat == "" {
+		inputFormat = inputFormatFor(*in)
+	}
+	if inputFormat != inputCSV && inputFormat != inputNDJSON && inputFormat != inputText {
+		fmt.Fprintf(fs.Output(), "invalid --format %q\n", inputFormat)
+		fs.Usage()
+		return ExitUsage, errUsage
+	}
+	output := common.output
+	if output == "" {
+		output = outputFormatFor(*out)
+	}
+	if err := checkOutput(fs, output, outputTable, outputJSON, outputCSV); err != nil {
+		return ExitUsage, err
+	}
+
+	svc, err := c.service(common)
+	if err != nil {
+		return ExitUsage, err
+	}
+
+	reader := c.Stdin
+	if *in != "-" {
+		file, err := os.Open(*in)
+		if err != nil {
+			return ExitUsage, err
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		reader = file
+	}
+
+	opts := common.options()
+	var summary model.BatchSummary
+	err = c.writeOutput(*out, func(w io.Writer) error {
+		var err error
+		switch {
+		case output == outputCSV && inputFormat == inputCSV:
+			// Keep every original column and append the results
+			summary, err = svc.ValidateCSVWithOptions(context.Background(), reader, w, *column, opts)
+		case output == outputCSV && inputFormat == inputText:
+			summary, err = svc.ValidateTextWithOptions(context.Background(), reader, w, opts)
+		default:
+			var emails []string
+			emails, err = readEmails(reader, inputFormat, *column)
+			if err != nil {
+				return err
+			}
+			summary, err = validateEmails(svc, emails, opts, w, output)
+		}
+		return err
+	})
+	if err != nil {
+		return ExitUsage, err
+	}
+
+	fmt.Fprintln(c.Stderr, formatSummary(summary))
+	return summaryExitCode(summary), nil
+}
+
+// writeOutput calls write with the output file, or standard output for "-", and closes the file
+func (c *CLI) writeOutput(path string, write func(io.Writer) error) (err error) {
+	if path == "-" {
+		buffered := bufio.NewWriter(c.Stdout)
+		if err := write(buffered); err != nil {
+			return err
+		}
+		return buffered.Flush()
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	buffered := bufio.NewWriter(file)
+	if err := write(buffered); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// inputFormatFor picks the input format from the file extension
+func inputFormatFor(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return inputCSV
+	case ".ndjson", ".jsonl":
+		return inputNDJSON
+	default:
+		return inputText
+	}
+}
+
+// outputFormatFor picks the output format from the file extension
+func outputFormatFor(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return outputCSV
+	case ".json", ".ndjson", ".jsonl":
+		return outputJSON
+	default:
+		return outputTable
+	}
+}
+
+// readEmails reads every email of the input
+func readEmails(r io.Reader, format, column string) ([]string, error) {
+	switch format {
+	case inputCSV:
+		return readCSVEmails(r, column)
+	case inputNDJSON:
+		return readNDJSONEmails(r)
+	default:
+		return readTextEmails(r)
+	}
+}
+
+// readCSVEmails reads the email column of a CSV file
+func readCSVEmails(r io.Reader, column string) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, service.ErrEmptyFile
+	}
+	if len(records[0]) > 0 {
+		records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+	}
+
+	index, hasHeader, err := service.EmailColumn(records[0], column)
+	if err != nil {
+		return nil, err
+	}
+	if hasHeader {
+		records = records[1:]
+	}
+
+	emails := make([]string, len(records))
+	for i, record := range records {
+		if index < len(record) {
+			emails[i] = strings.TrimSpace(record[index])
+		}
+	}
+	return emails, nil
+}
+
+// readNDJSONEmails reads one email per line, given either as a JSON string or an object with an email field
+func readNDJSONEmails(r io.Reader) ([]string, error) {
+	var emails []string
+	decoder := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return emails, nil
+			}
+			return nil, fmt.Errorf("invalid NDJSON value %d: %w", line, err)
+		}
+
+		var email string
+		if err := json.Unmarshal(raw, &email); err != nil {
+			var record model.EmailValidationRequest
+			if err := json.Unmarshal(raw, &record); err != nil {
+				return nil, fmt.Errorf("invalid NDJSON value %d: expected a string or an object with an email field", line)
+			}
+			email = record.Email
+		}
+		emails = append(emails, strings.TrimSpace(email))
+	}
+}
+
+// readTextEmails reads one email per non-empty line
+func readTextEmails(r io.Reader) ([]string, error) {
+	var emails []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff")); line != "" {
+			emails = append(emails, line)
+		}
+	}
+	return emails, scanner.Err()
+}
+
+// validateEmails validates the emails in chunks and writes the results in input order
+func validateEmails(svc *service.EmailService, emails []string, opts service.ValidationOptions, w io.Writer, output string) (model.BatchSummary, error) {
+	start := time.Now()
+	summary := model.BatchSummary{StatusCounts: make(map[model.ValidationStatus]int)}
+
+	encoder := json.NewEncoder(w)
+	csvWriter := csv.NewWriter(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	switch output {
+	case outputCSV:
+		if err := csvWriter.Write([]string{"email", "status", "score", "reason"}); err != nil {
+			return summary, err
+		}
+	case outputTable:
+		if _, err := fmt.Fprintln(tw, "EMAIL\tSTATUS\tSCORE\tREASON"); err != nil {
+			return summary, err
+		}
+	}
+
+	// The status rule of each result picks its reason; JSON output only has an explanation when asked for
+	validateOpts := opts
+	if output != outputJSON {
+		validateOpts.Explain = true
+	}
+	for from := 0; from < len(emails); from += batchChunkSize {
+		to := min(from+batchChunkSize, len(emails))
+		response, err := svc.ValidateEmailsWithOptions(context.Background(), emails[from:to], validateOpts)
+		if err != nil {
+			return summary, err
+		}
+		results := response.Results
+
+		switch output {
+		case outputJSON:
+			for _, result := range results {
+				if err = encoder.Encode(result); err != nil {
+					break
+				}
+			}
+		case outputCSV:
+			for _, result := range results {
+				if err = csvWriter.Write([]string{result.Email, string(result.Status), strconv.Itoa(result.Score), service.ResultReason(result)}); err != nil {
+					break
+				}
+			}
+			csvWriter.Flush()
+			if err == nil {
+				err = csvWriter.Error()
+			}
+		default:
+			err = writeResultRows(tw, results, service.ResultReason)
+		}
+		if err != nil {
+			return summary, err
+		}
+
+		for _, result := range results {
+			summary.Total++
+			summary.StatusCounts[result.Status]++
+		}
+	}
+
+	if output == outputTable {
+		if err := tw.Flush(); err != nil {
+			return summary, err
+		}
+	}
+	summary.DurationMs = time.Since(start).Milliseconds()
+	return summary, nil
+}
+
+// formatSummary describes the outcome of a batch in one line
+func formatSummary(summary model.BatchSummary) string {
+	statuses := make([]string, 0, len(summary.StatusCounts))
+	for status := range summary.StatusCounts {
+		statuses = append(statuses, string(status))
+	}
+	sort.Strings(statuses)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Validated %d emails in %dms", summary.Total, summary.DurationMs)
+	for i, status := range statuses {
+		if i == 0 {
+			b.WriteString(":")
+		}
+		fmt.Fprintf(&b, " %s=%d", status, summary.StatusCounts[model.ValidationStatus(status)])
+	}
+	return b.String()
+}
+
+// summaryExitCode returns the exit code of the worst status in the batch
+func summaryExitCode(summary model.BatchSummary) int {
+	code := ExitOK
+	for status, count := range summary.StatusCounts {
+		if count > 0 {
+			code = worstExitCode(code, statusExitCode(status))
+		}
+	}
+	return code
+}
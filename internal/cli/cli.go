@@ -0,0 +1,258 @@
+// Package cli implements the emailvalidator command-line tool.
+// It runs the same validation service as the HTTP server without starting one.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"emailvalidator/internal/config"
+	"emailvalidator/internal/model"
+	"emailvalidator/internal/service"
+)
+
+// Exit codes reflecting the outcome of a command
+const (
+	// ExitOK means every result is deliverable
+	ExitOK = 0
+	// ExitInvalid means at least one result is undeliverable, or a typo was found
+	ExitInvalid = 1
+	// ExitUsage means the arguments were invalid or a file couldn't be read or written
+	ExitUsage = 2
+	// ExitRisky means at least one result can't be confirmed, such as a catch-all domain, and none is undeliverable
+	ExitRisky = 3
+)
+
+// Output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+// errUsage is returned when a command is called with invalid arguments
+var errUsage = errors.New("invalid usage")
+
+const usage = `Usage: emailvalidator <command> [flags] [arguments]
+
+Commands:
+  validate <email>              Validate a single email address (--explain breaks down the score)
+  batch --in FILE --out FILE    Validate a CSV, NDJSON or plain-text list of emails
+  typo <email>                  Suggest a correction for a mistyped email address
+  domain <domain>               Check whether a domain can receive email
+  update-tlds                   Download the current list of top-level domains from IANA
+
+Common flags:
+  --output json|table           Output format (batch also supports csv)
+  --concurrency N               Number of emails validated at the same time
+  --smtp                        Probe mail servers over SMTP to check that mailboxes exist
+  --profile NAME                Scoring profile, such as strict-signup or lenient-newsletter
+  --mode fast|standard|deep     Checks to run: no network, DNS, or DNS and SMTP (default every configured check)
+  --checks LIST                 Comma-separated checks to run instead of a mode, such as syntax,typo,disposable
+
+Exit codes:
+  0  every result is deliverable
+  1  at least one result is undeliverable, or a typo was found
+  2  invalid usage or file error
+  3  at least one result can't be confirmed (catch-all, risky, unknown)
+
+Run 'emailvalidator <command> --help' for the flags of a command.
+`
+
+// CLI runs emailvalidator commands
+type CLI struct {
+	// NewService builds the validation service from the configuration
+	NewService func(cfg config.Config) (*service.EmailService, error)
+	Stdin      io.Reader
+	Stdout     io.Writer
+	Stderr     io.Writer
+}
+
+// New creates a new CLI using the standard streams and the configuration from the environment
+func New() *CLI {
+	return &CLI{
+		NewService: service.NewEmailServiceWithConfig,
+		Stdin:      os.Stdin,
+		Stdout:     os.Stdout,
+		Stderr:     os.Stderr,
+	}
+}
+
+// Run executes the command in args, without the program name, and returns the exit code
+func (c *CLI) Run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(c.Stderr, usage)
+		return ExitUsage
+	}
+
+	var err error
+	code := ExitOK
+	switch args[0] {
+	case "validate":
+		code, err = c.runValidate(args[1:])
+	case "batch":
+		code, err = c.runBatch(args[1:])
+	case "typo":
+		code, err = c.runTypo(args[1:])
+	case "domain":
+		code, err = c.runDomain(args[1:])
+	case "update-tlds":
+		code, err = c.runUpdateTLDs(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(c.Stdout, usage)
+		return ExitOK
+	default:
+		fmt.Fprintf(c.Stderr, "unknown command %q\n\n%s", args[0], usage)
+		return ExitUsage
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	if err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintf(c.Stderr, "emailvalidator %s: %v\n", args[0], err)
+		}
+		return ExitUsage
+	}
+	return code
+}
+
+// commonFlags holds the flags shared by every command
+type commonFlags struct {
+	output      string
+	concurrency int
+	smtp        bool
+	profile     string
+	mode        string
+	checks      string
+}
+
+// options returns the validation options selected by the flags
+func (f *commonFlags) options() service.ValidationOptions {
+	opts := service.ValidationOptions{Mode: f.mode}
+	for _, check := range strings.Split(f.checks, ",") {
+		if check = strings.TrimSpace(check); check != "" {
+			opts.Checks = append(opts.Checks, check)
+		}
+	}
+	return opts
+}
+
+// needsSMTP reports whether the flags select the SMTP mailbox check
+func (f *commonFlags) needsSMTP() bool {
+	if strings.EqualFold(f.mode, service.ModeDeep) {
+		return true
+	}
+	for _, check := range f.options().Checks {
+		if strings.EqualFold(check, model.CheckMailbox) {
+			return true
+		}
+	}
+	return false
+}
+
+// newFlagSet creates the flag set of a command with the common flags registered
+func (c *CLI) newFlagSet(name, arguments string, defaultOutput string) (*flag.FlagSet, *commonFlags) {
+	common := &commonFlags{}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.Stderr, "Usage: emailvalidator %s [flags] %s\n\nFlags:\n", name, arguments)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&common.output, "output", defaultOutput, "output format: json or table")
+	fs.IntVar(&common.concurrency, "concurrency", 0, "number of emails validated at the same time (default 4 per CPU)")
+	fs.BoolVar(&common.smtp, "smtp", false, "probe mail servers over SMTP to check that mailboxes exist")
+	fs.StringVar(&common.profile, "profile", "", "scoring profile (default from SCORING_PROFILE)")
+	fs.StringVar(&common.mode, "mode", "", "checks to run: fast, standard or deep (default every configured check)")
+	fs.StringVar(&common.checks, "checks", "", "comma-separated checks to run instead of a mode: "+strings.Join(service.SelectableChecks(), ","))
+	return fs, common
+}
+
+// parseArgs parses flags placed before or after the positional arguments and
+// checks the number of positional arguments
+func parseArgs(fs *flag.FlagSet, args []string, want int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(positional) != want {
+		fs.Usage()
+		return nil, errUsage
+	}
+	return positional, nil
+}
+
+// service builds the validation service with the command's flags applied
+func (c *CLI) service(common *commonFlags) (*service.EmailService, error) {
+	cfg := config.Load()
+	// Chunks of a large batch take longer than a single request, and there is no response to deliver in time
+	cfg.ValidationTimeout = 0
+	// Deep validation probes mailboxes, so it turns SMTP verification on like --smtp
+	if common.smtp || common.needsSMTP() {
+		cfg.SMTPEnabled = true
+	}
+	if common.profile != "" {
+		cfg.ScoringProfile = common.profile
+	}
+
+	svc, err := c.NewService(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if common.concurrency > 0 {
+		svc.SetConcurrency(common.concurrency)
+	}
+	return svc, nil
+}
+
+// checkOutput reports a usage error unless the output format is one of allowed
+func checkOutput(fs *flag.FlagSet, output string, allowed ...string) error {
+	for _, format := range allowed {
+		if output == format {
+			return nil
+		}
+	}
+	fmt.Fprintf(fs.Output(), "invalid --output %q\n", output)
+	fs.Usage()
+	return errUsage
+}
+
+// statusExitCode maps a validation status to an exit code
+func statusExitCode(status model.ValidationStatus) int {
+	switch status {
+	case model.ValidationStatusValid, model.ValidationStatusProbablyValid:
+		return ExitOK
+	case model.ValidationStatusCatchAll, model.ValidationStatusRisky, model.ValidationStatusUnknown:
+		return ExitRisky
+	default:
+		return ExitInvalid
+	}
+}
+
+// worstExitCode combines exit codes, with undeliverable results outranking unconfirmed ones
+func worstExitCode(a, b int) int {
+	if a == ExitInvalid || b == ExitInvalid {
+		return ExitInvalid
+	}
+	if a == ExitRisky || b == ExitRisky {
+		return ExitRisky
+	}
+	return ExitOK
+}
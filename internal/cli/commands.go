@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"emailvalidator/internal/model"
+)
+
+// runValidate validates a single email address
+func (c *CLI) runValidate(args []string) (int, error) {
+	fs, common := c.newFlagSet("validate", "<email>", outputTable)
+	explain := fs.Bool("explain", false, "break the score down into the points of each check and show the rule that set the status")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return ExitUsage, err
+	}
+	if err := checkOutput(fs, common.output, outputTable, outputJSON); err != nil {
+		return ExitUsage, err
+	}
+
+	svc, err := c.service(common)
+	if err != nil {
+		return ExitUsage, err
+	}
+	opts := common.options()
+	opts.Explain = *explain
+	result, err := svc.ValidateEmailWithOptions(context.Background(), positional[0], opts)
+	if err != nil {
+		return ExitUsage, err
+	}
+
+	if common.output == outputJSON {
+		err = writeJSON(c.Stdout, result)
+	} else {
+		err = writeFields(c.Stdout, [][2]string{
+			{"Email", result.Email},
+			{"Status", string(result.Status)},
+			{"Score", strconv.Itoa(result.Score)},
+			{"Syntax", strconv.FormatBool(result.Validations.Syntax)},
+			{"Domain exists", checkResult(result.Validations, model.CheckDomain, result.Validations.DomainExists)},
+			{"MX records", checkResult(result.Validations, model.CheckMX, result.Validations.MXRecords)},
+			{"Mailbox exists", checkResult(result.Validations, model.CheckMailbox, result.Validations.MailboxExists)},
+			{"Disposable", checkResult(result.Validations, model.CheckDisposable, result.Validations.IsDisposable)},
+			{"Role based", checkResult(result.Validations, model.CheckRoleBased, result.Validations.IsRoleBased)},
+			{"Catch-all", checkResult(result.Validations, model.CheckMailbox, result.Validations.IsCatchAll)},
+			{"Free provider", strconv.FormatBool(result.Validations.IsFreeProvider)},
+			{"Spoof suspect", checkResult(result.Validations, model.CheckSpoof, result.Validations.IsSpoofSuspect)},
+			{"Typo suggestion", result.TypoSuggestion},
+			{"Alias of", result.AliasOf},
+			{"Reasons", reasonCodes(result.Reasons)},
+		})
+		if err == nil && result.Explanation != nil {
+			err = writeExplanation(c.Stdout, result.Explanation)
+		}
+	}
+	if err != nil {
+		return ExitUsage, err
+	}
+
+	return statusExitCode(result.Status), nil
+}
+
+// runTypo suggests a correction for a mistyped email address
+func (c *CLI) runTypo(args []string) (int, error) {
+	fs, common := c.newFlagSet("typo", "<email>", outputTable)
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return ExitUsage, err
+	}
+	if err := checkOutput(fs, common.output, outputTable, outputJSON); err != nil {
+		return ExitUsage, err
+	}
+
+	svc, err := c.service(common)
+	if err != nil {
+		return ExitUsage, err
+	}
+	result := svc.GetTypoSuggestions(context.Background(), positional[0])
+
+	if common.output == outputJSON {
+		err = writeJSON(c.Stdout, result)
+	} else {
+		fields := [][2]string{
+			{"Email", result.Email},
+			{"Typo suggestion", result.TypoSuggestion},
+		}
+		if result.TypoSuggestion != "" {
+			fields = append(fields, [2]string{"Confidence", strconv.FormatFloat(result.Confidence, 'f', 2, 64)})
+		}
+		for _, alternative := range result.Suggestions[min(1, len(result.Suggestions)):] {
+			fields = append(fields, [2]string{"Alternative", fmt.Sprintf("%s (%.2f)", alternative.Email, alternative.Confidence)})
+		}
+		err = writeFields(c.Stdout, fields)
+	}
+	if err != nil {
+		return ExitUsage, err
+	}
+
+	if result.TypoSuggestion != "" {
+		return ExitInvalid, nil
+	}
+	return ExitOK, nil
+}
+
+// runDomain checks whether a domain can receive email
+func (c *CLI) runDomain(args []string) (int, error) {
+	fs, common := c.newFlagSet("domain", "<domain>", outputTable)
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return ExitUsage, err
+	}
+	if err := checkOutput(fs, common.output, outputTable, outputJSON); err != nil {
+		return ExitUsage, err
+	}
+
+	svc, err := c.service(common)
+	if err != nil {
+		return ExitUsage, err
+	}
+	result := svc.ValidateDomain(context.Background(), positional[0])
+
+	if common.output == outputJSON {
+		err = writeJSON(c.Stdout, result)
+	} else {
+		err = writeFields(c.Stdout, [][2]string{
+			{"Domain", result.Domain},
+			{"Domain exists", strconv.FormatBool(result.DomainExists)},
+			{"MX records", strconv.FormatBool(result.MXRecords)},
+			{"Disposable", strconv.FormatBool(result.IsDisposable)},
+			{"Free provider", strconv.FormatBool(result.IsFreeProvider)},
+			{"Reserved", strconv.FormatBool(result.IsReserved)},
+		})
+	}
+	if err != nil {
+		return ExitUsage, err
+	}
+
+	if !result.DomainExists || !result.MXRecords || result.IsDisposable {
+		return ExitInvalid, nil
+	}
+	return ExitOK, nil
+}
+
+// reasonCodes lists the reason codes of a result, separated by commas
+func reasonCodes(reasons []model.Reason) string {
+	codes := make([]string, len(reasons))
+	for i, reason := range reasons {
+		codes[i] = reason.Code
+	}
+	return strings.Join(codes, ", ")
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// writeFields writes name and value pairs as an aligned table, skipping empty values
+func writeFields(w io.Writer, fields [][2]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(tw, "%s:\t%s\n", field[0], field[1]); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// checkResult formats the result of a check, or "skipped" if it didn't run
+func checkResult(validations model.ValidationResults, check string, value bool) string {
+	if !validations.Ran(check) {
+		return "skipped"
+	}
+	return strconv.FormatBool(value)
+}
+
+// writeExplanation writes the points of each check and the rule that set the status as a table
+func writeExplanation(w io.Writer, explanation *model.ScoreExplanation) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintf(tw, "\nScore breakdown (profile %s):\n", explanation.Profile); err != nil {
+		return err
+	}
+	for _, contribution := range explanation.Contributions {
+		if _, err := fmt.Fprintf(tw, "  %s\t%+d\t%s\n", contribution.Check, contribution.Points, contribution.Detail); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(tw, "Status rule:\t%s\t%s\n", explanation.StatusRule.Rule, explanation.StatusRule.Message); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+// writeResultRows writes validation results as table rows
+func writeResultRows(tw *tabwriter.Writer, results []model.EmailValidationResponse, reason func(model.EmailValidationResponse) string) error {
+	for _, result := range results {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", result.Email, result.Status, result.Score, reason(result)); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"emailvalidator/pkg/validator"
+)
+
+const (
+	// tldDownloadTimeout bounds the download of the TLD list
+	tldDownloadTimeout = 30 * time.Second
+	// tldMaxListSize bounds the size of the downloaded TLD list, which is about 10 KB
+	tldMaxListSize = 1 << 20
+)
+
+// runUpdateTLDs replaces the TLD list used by syntax validation with the one published by IANA
+func (c *CLI) runUpdateTLDs(args []string) (int, error) {
+	fs := flag.NewFlagSet("update-tlds", flag.ContinueOnError)
+	fs.SetOutput(c.Stderr)
+	fs.Usage = func() {
+		fmt.Fprint(c.Stderr, "Usage: emailvalidator update-tlds [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	url := fs.String("url", validator.TLDListURL, "URL of the TLD list in the IANA format")
+	out := fs.String("out", "", "file to write the TLD list to (default the tlds.txt of the config directory)")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return ExitUsage, err
+	}
+
+	path := *out
+	if path == "" {
+		var err error
+		if path, err = validator.TLDListPath(); err != nil {
+			return ExitUsage, err
+		}
+	}
+
+	list, err := downloadTLDList(*url)
+	if err != nil {
+		return ExitUsage, err
+	}
+	tlds, err := validator.ParseTLDList(strings.NewReader(list))
+	if err != nil {
+		return ExitUsage, fmt.Errorf("invalid TLD list from %s: %w", *url, err)
+	}
+	if err := writeFileAtomic(path, list); err != nil {
+		return ExitUsage, err
+	}
+
+	fmt.Fprintf(c.Stdout, "Wrote %d TLDs to %s\n", len(tlds), path)
+	return ExitOK, nil
+}
+
+// downloadTLDList fetches the TLD list at url
+func downloadTLDList(url string) (string, error) {
+	client := &http.Client{Timeout: tldDownloadTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading %s: %s", url, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, tldMaxListSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(body) > tldMaxListSize {
+		return "", fmt.Errorf("downloading %s: list larger than %d bytes", url, tldMaxListSize)
+	}
+	return string(body), nil
+}
+
+// writeFileAtomic replaces the file at path with content, so a running server never reads a partial list
+func writeFileAtomic(path, content string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,172 @@
+// Package config loads the runtime configuration of the email validator service.
+// Settings are read from environment variables so they can be set per deployment.
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"emailvalidator/pkg/validator"
+)
+
+// Config holds the service configuration
+type Config struct {
+	// SMTPEnabled turns on SMTP mailbox probing for mailbox_exists
+	SMTPEnabled bool
+	// SMTP holds the SMTP probing settings
+	SMTP validator.SMTPConfig
+	// DNS holds the upstream DNS servers queried instead of the system resolver; without servers
+	// the system resolver is used
+	DNS validator.ResolverConfig
+	// DNSCache bounds how long domain and MX lookup results are cached, for results that were found
+	// and for failed lookups separately
+	DNSCache validator.CacheTTLConfig
+	// RedisURL points at the Redis instance shared by replicas for domain lookup results.
+	// Empty keeps the domain cache in process only
+	RedisURL string
+	// Jobs holds the asynchronous batch job settings
+	Jobs JobConfig
+	// TypoKeyboardWeighted makes typo suggestions treat neighbouring keys on a QWERTY keyboard as likelier slips
+	TypoKeyboardWeighted bool
+	// AllowedReservedDomains are reserved domains, such as "test", validated like any other domain
+	// instead of being reported as RESERVED_DOMAIN
+	AllowedReservedDomains []string
+	// ScoringProfile names the scoring profile used when a request doesn't pick one
+	ScoringProfile string
+	// ScoringProfilesFile is the JSON file defining the scoring profiles.
+	// Empty uses scoring_profiles.json in the config directory
+	ScoringProfilesFile string
+	// ValidationTimeout bounds single and batch validations; lookups still running then are abandoned
+	// and their results marked as timed out. 0 leaves them bounded only by the request
+	ValidationTimeout time.Duration
+}
+
+// JobConfig holds the settings of asynchronous batch validation jobs
+type JobConfig struct {
+	// Workers is the number of jobs processed at the same time
+	Workers int
+	// QueueSize is the number of jobs that can wait for a worker before submissions are rejected
+	QueueSize int
+	// MaxEmails is the largest number of emails accepted in a single job
+	MaxEmails int
+	// Store selects where jobs and results are kept: "memory" or "redis"
+	Store string
+	// TTL is how long a job and its results are kept after its last update
+	TTL time.Duration
+}
+
+// Job store backends
+const (
+	JobStoreMemory = "memory"
+	JobStoreRedis  = "redis"
+)
+
+// Default returns the configuration used when no environment overrides are present
+func Default() Config {
+	return Config{
+		SMTPEnabled: false,
+		SMTP:        validator.DefaultSMTPConfig(),
+		DNS:         validator.DefaultResolverConfig(),
+		DNSCache:    validator.DefaultCacheTTLConfig(),
+		Jobs: JobConfig{
+			Workers:   2,
+			QueueSize: 100,
+			MaxEmails: 100000,
+			Store:     JobStoreMemory,
+			TTL:       24 * time.Hour,
+		},
+		TypoKeyboardWeighted: true,
+		ScoringProfile:       validator.DefaultProfileName,
+		// Below the write timeout of the server, so timed out results still reach the client
+		ValidationTimeout: 8 * time.Second,
+	}
+}
+
+// Load returns the default configuration overridden by environment variables
+func Load() Config {
+	cfg := Default()
+
+	cfg.SMTPEnabled = getBool("SMTP_VERIFY_ENABLED", cfg.SMTPEnabled)
+	cfg.SMTP.HeloName = getString("SMTP_HELO_NAME", cfg.SMTP.HeloName)
+	cfg.SMTP.MailFrom = getString("SMTP_MAIL_FROM", cfg.SMTP.MailFrom)
+	cfg.SMTP.Port = getString("SMTP_PORT", cfg.SMTP.Port)
+	cfg.SMTP.ConnectTimeout = getDuration("SMTP_CONNECT_TIMEOUT", cfg.SMTP.ConnectTimeout)
+	cfg.SMTP.CommandTimeout = getDuration("SMTP_COMMAND_TIMEOUT", cfg.SMTP.CommandTimeout)
+	cfg.SMTP.MaxRetries = getInt("SMTP_MAX_RETRIES", cfg.SMTP.MaxRetries)
+	cfg.SMTP.RetryBackoff = getDuration("SMTP_RETRY_BACKOFF", cfg.SMTP.RetryBackoff)
+	cfg.SMTP.MaxTrackedRetries = getInt("SMTP_MAX_TRACKED_RETRIES", cfg.SMTP.MaxTrackedRetries)
+	cfg.DNS.Servers = getList("DNS_SERVERS", cfg.DNS.Servers)
+	cfg.DNS.Protocol = getString("DNS_PROTOCOL", cfg.DNS.Protocol)
+	cfg.DNS.Timeout = getDuration("DNS_TIMEOUT", cfg.DNS.Timeout)
+	cfg.DNS.Retries = getInt("DNS_RETRIES", cfg.DNS.Retries)
+	cfg.DNSCache.MinTTL = getDuration("DNS_CACHE_MIN_TTL", cfg.DNSCache.MinTTL)
+	cfg.DNSCache.MaxTTL = getDuration("DNS_CACHE_MAX_TTL", cfg.DNSCache.MaxTTL)
+	cfg.DNSCache.NegativeMinTTL = getDuration("DNS_CACHE_NEGATIVE_MIN_TTL", cfg.DNSCache.NegativeMinTTL)
+	cfg.DNSCache.NegativeMaxTTL = getDuration("DNS_CACHE_NEGATIVE_MAX_TTL", cfg.DNSCache.NegativeMaxTTL)
+	cfg.RedisURL = getString("REDIS_URL", cfg.RedisURL)
+	cfg.Jobs.Workers = getInt("JOB_WORKERS", cfg.Jobs.Workers)
+	cfg.Jobs.QueueSize = getInt("JOB_QUEUE_SIZE", cfg.Jobs.QueueSize)
+	cfg.Jobs.MaxEmails = getInt("JOB_MAX_EMAILS", cfg.Jobs.MaxEmails)
+	cfg.Jobs.Store = getString("JOB_STORE", cfg.Jobs.Store)
+	cfg.Jobs.TTL = getDuration("JOB_TTL", cfg.Jobs.TTL)
+	cfg.TypoKeyboardWeighted = getBool("TYPO_KEYBOARD_WEIGHTED", cfg.TypoKeyboardWeighted)
+	cfg.AllowedReservedDomains = getList("ALLOWED_RESERVED_DOMAINS", cfg.AllowedReservedDomains)
+	cfg.ScoringProfile = getString("SCORING_PROFILE", cfg.ScoringProfile)
+	cfg.ScoringProfilesFile = getString("SCORING_PROFILES_FILE", cfg.ScoringProfilesFile)
+	cfg.ValidationTimeout = getDuration("VALIDATION_TIMEOUT", cfg.ValidationTimeout)
+
+	return cfg
+}
+
+// getString returns the value of the environment variable or the fallback if unset
+func getString(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// getList returns the comma-separated values of the environment variable or the fallback if unset
+func getList(key string, fallback []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// getBool returns the environment variable parsed as a bool or the fallback if unset or invalid
+func getBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getInt returns the environment variable parsed as an int or the fallback if unset or invalid
+func getInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getDuration returns the environment variable parsed as a duration or the fallback if unset or invalid
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
@@ -2,6 +2,11 @@
 // It defines the request/response models for the API endpoints and internal data representations.
 package model
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // ValidationStatus represents the status of an email validation
 type ValidationStatus string
 
@@ -13,23 +18,131 @@ const (
 	ValidationStatusMissingEmail  ValidationStatus = "MISSING_EMAIL"
 	ValidationStatusInvalidFormat ValidationStatus = "INVALID_FORMAT"
 	ValidationStatusInvalidDomain ValidationStatus = "INVALID_DOMAIN"
+	ValidationStatusReserved      ValidationStatus = "RESERVED_DOMAIN"
 	ValidationStatusNoMXRecords   ValidationStatus = "NO_MX_RECORDS"
 	ValidationStatusDisposable    ValidationStatus = "DISPOSABLE"
+	ValidationStatusCatchAll      ValidationStatus = "CATCH_ALL"
+	ValidationStatusRisky         ValidationStatus = "RISKY"
+	ValidationStatusUnknown       ValidationStatus = "UNKNOWN"
 )
 
 // ValidationResults represents the results of various validation checks
 type ValidationResults struct {
-	Syntax        bool `json:"syntax"`
-	DomainExists  bool `json:"domain_exists"`
-	MXRecords     bool `json:"mx_records"`
-	MailboxExists bool `json:"mailbox_exists"`
-	IsDisposable  bool `json:"is_disposable"`
-	IsRoleBased   bool `json:"is_role_based"`
+	Syntax         bool `json:"syntax"`
+	DomainExists   bool `json:"domain_exists"`
+	MXRecords      bool `json:"mx_records"`
+	MailboxExists  bool `json:"mailbox_exists"`
+	IsDisposable   bool `json:"is_disposable"`
+	IsRoleBased    bool `json:"is_role_based"`
+	IsCatchAll     bool `json:"is_catch_all"`
+	IsFreeProvider bool `json:"is_free_provider"`
+	IsSpoofSuspect bool `json:"is_spoof_suspect"`
+	// Skipped lists the checks the request left out. Their results are false here and null in JSON
+	Skipped []string `json:"-"`
+}
+
+// Ran reports whether the check was run rather than skipped
+func (v ValidationResults) Ran(check string) bool {
+	for _, skipped := range v.Skipped {
+		if skipped == check {
+			return false
+		}
+	}
+	return true
+}
+
+// validationResultsJSON is the JSON form of ValidationResults, where skipped checks are null
+type validationResultsJSON struct {
+	Syntax         bool  `json:"syntax"`
+	DomainExists   *bool `json:"domain_exists"`
+	MXRecords      *bool `json:"mx_records"`
+	MailboxExists  *bool `json:"mailbox_exists"`
+	IsDisposable   *bool `json:"is_disposable"`
+	IsRoleBased    *bool `json:"is_role_based"`
+	IsCatchAll     *bool `json:"is_catch_all"`
+	IsFreeProvider bool  `json:"is_free_provider"`
+	IsSpoofSuspect *bool `json:"is_spoof_suspect"`
+}
+
+// MarshalJSON writes the results of skipped checks as null
+func (v ValidationResults) MarshalJSON() ([]byte, error) {
+	result := func(check string, value bool) *bool {
+		if !v.Ran(check) {
+			return nil
+		}
+		return &value
+	}
+	return json.Marshal(validationResultsJSON{
+		Syntax:         v.Syntax,
+		DomainExists:   result(CheckDomain, v.DomainExists),
+		MXRecords:      result(CheckMX, v.MXRecords),
+		MailboxExists:  result(CheckMailbox, v.MailboxExists),
+		IsDisposable:   result(CheckDisposable, v.IsDisposable),
+		IsRoleBased:    result(CheckRoleBased, v.IsRoleBased),
+		IsCatchAll:     result(CheckMailbox, v.IsCatchAll),
+		IsFreeProvider: v.IsFreeProvider,
+		IsSpoofSuspect: result(CheckSpoof, v.IsSpoofSuspect),
+	})
+}
+
+// UnmarshalJSON reads results written by MarshalJSON, recording null results as skipped checks
+func (v *ValidationResults) UnmarshalJSON(data []byte) error {
+	var raw validationResultsJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*v = ValidationResults{Syntax: raw.Syntax, IsFreeProvider: raw.IsFreeProvider}
+	result := func(check string, value *bool) bool {
+		if value == nil {
+			if v.Ran(check) {
+				v.Skipped = append(v.Skipped, check)
+			}
+			return false
+		}
+		return *value
+	}
+	v.DomainExists = result(CheckDomain, raw.DomainExists)
+	v.MXRecords = result(CheckMX, raw.MXRecords)
+	v.MailboxExists = result(CheckMailbox, raw.MailboxExists)
+	v.IsDisposable = result(CheckDisposable, raw.IsDisposable)
+	v.IsRoleBased = result(CheckRoleBased, raw.IsRoleBased)
+	v.IsCatchAll = result(CheckMailbox, raw.IsCatchAll)
+	v.IsSpoofSuspect = result(CheckSpoof, raw.IsSpoofSuspect)
+	return nil
+}
+
+// Names of the checks that can report a Reason
+const (
+	CheckSyntax     = "syntax"
+	CheckDomain     = "domain"
+	CheckMX         = "mx"
+	CheckDisposable = "disposable"
+	CheckRoleBased  = "role_based"
+	CheckMailbox    = "mailbox"
+	CheckTypo       = "typo"
+	CheckSpoof      = "spoof"
+)
+
+// Reason explains the outcome of a single check.
+// Code is stable and meant for programs; Message is meant for people and may change.
+type Reason struct {
+	Check   string `json:"check"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 // EmailValidationRequest represents a request to validate a single email
 type EmailValidationRequest struct {
 	Email string `json:"email"`
+	// Profile names the scoring profile; empty uses the configured default
+	Profile string `json:"profile,omitempty"`
+	// Explain adds a breakdown of the score and status to the response
+	Explain bool `json:"explain,omitempty"`
+	// Mode picks a preset of checks: fast, standard or deep. Empty runs every configured check
+	Mode string `json:"mode,omitempty"`
+	// Checks lists the checks to run instead of a mode; syntax always runs
+	Checks []string `json:"checks,omitempty"`
 }
 
 // EmailValidationResponse represents the response for email validation
@@ -40,11 +153,52 @@ type EmailValidationResponse struct {
 	Status         ValidationStatus  `json:"status"`
 	AliasOf        string            `json:"aliasOf,omitempty"`        // Optional field to indicate if email is an alias
 	TypoSuggestion string            `json:"typoSuggestion,omitempty"` // Optional field for typo suggestion
+	Pending        bool              `json:"pending,omitempty"`        // Set when a mailbox check retry is scheduled after a temporary SMTP failure
+	TimedOut       bool              `json:"timedOut,omitempty"`       // Set when lookups didn't finish before the deadline of the request
+	Reasons        []Reason          `json:"reasons,omitempty"`        // Why checks failed or lowered the score, in the order the checks ran
+	// EmailUnicode and EmailASCII hold the email with its domain in Unicode and in ASCII (xn--) form.
+	// The local part is the same in both. Both are set once the syntax is valid
+	EmailUnicode string `json:"emailUnicode,omitempty"`
+	EmailASCII   string `json:"emailAscii,omitempty"`
+	// RequiresSMTPUTF8 is set when the local part isn't ASCII, so only mail servers supporting SMTPUTF8 can deliver to it
+	RequiresSMTPUTF8 bool `json:"requiresSmtpUtf8,omitempty"`
+	// Explanation breaks down the score and status; only set when requested
+	Explanation *ScoreExplanation `json:"explanation,omitempty"`
+}
+
+// ScoreExplanation breaks a score down into the points of each check and penalty,
+// which add up to the score, and names the rule that set the status
+type ScoreExplanation struct {
+	Profile       string              `json:"profile"`
+	Contributions []ScoreContribution `json:"contributions"`
+	StatusRule    StatusRule          `json:"statusRule"`
+}
+
+// ScoreContribution is the number of points a check or penalty added to or removed from the score
+type ScoreContribution struct {
+	Check  string `json:"check"`
+	Points int    `json:"points"`
+	Detail string `json:"detail"`
+}
+
+// StatusRule names the rule that set the status. Rule is stable and meant for programs;
+// Message is meant for people and may change
+type StatusRule struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 // BatchValidationRequest represents a request to validate multiple emails
 type BatchValidationRequest struct {
 	Emails []string `json:"emails"`
+	// Profile names the scoring profile; empty uses the configured default
+	Profile string `json:"profile,omitempty"`
+	// Explain adds a breakdown of the score and status to every result
+	Explain bool `json:"explain,omitempty"`
+	// Mode picks a preset of checks: fast, standard or deep. Empty runs every configured check
+	Mode string `json:"mode,omitempty"`
+	// Checks lists the checks to run instead of a mode; syntax always runs
+	Checks []string `json:"checks,omitempty"`
 }
 
 // BatchValidationResponse represents the response for batch email validation
@@ -52,6 +206,28 @@ type BatchValidationResponse struct {
 	Results []EmailValidationResponse `json:"results"`
 }
 
+// DomainValidationResponse represents the result of validating a domain on its own
+type DomainValidationResponse struct {
+	Domain         string `json:"domain"`
+	DomainExists   bool   `json:"domain_exists"`
+	MXRecords      bool   `json:"mx_records"`
+	IsDisposable   bool   `json:"is_disposable"`
+	IsFreeProvider bool   `json:"is_free_provider"`
+	IsReserved     bool   `json:"is_reserved"` // Set for reserved and special-use domains, which are never looked up
+}
+
+// BatchSummary summarises a streamed batch validation
+type BatchSummary struct {
+	Total        int                      `json:"total"`
+	StatusCounts map[ValidationStatus]int `json:"status_counts"`
+	DurationMs   int64                    `json:"duration_ms"`
+}
+
+// BatchSummaryLine is the last line of a streamed batch validation response
+type BatchSummaryLine struct {
+	Summary BatchSummary `json:"summary"`
+}
+
 // TypoSuggestionRequest represents a request for email typo suggestions
 type TypoSuggestionRequest struct {
 	Email string `json:"email"`
@@ -59,8 +235,16 @@ type TypoSuggestionRequest struct {
 
 // TypoSuggestionResponse represents the response for email typo suggestions
 type TypoSuggestionResponse struct {
-	Email          string `json:"email"`
-	TypoSuggestion string `json:"typoSuggestion,omitempty"`
+	Email          string           `json:"email"`
+	TypoSuggestion string           `json:"typoSuggestion,omitempty"`
+	Confidence     float64          `json:"confidence,omitempty"`  // Confidence in TypoSuggestion, from 0 to 1
+	Suggestions    []TypoSuggestion `json:"suggestions,omitempty"` // Every likely correction, best first
+}
+
+// TypoSuggestion represents a ranked correction of a mistyped email address
+type TypoSuggestion struct {
+	Email      string  `json:"email"`
+	Confidence float64 `json:"confidence"`
 }
 
 // APIStatus represents the current status of the API
@@ -76,3 +260,38 @@ type CreditInfo struct {
 	RemainingCredits int `json:"remaining_credits"`
 	TotalCredits     int `json:"total_credits"`
 }
+
+// JobStatus represents the lifecycle state of an asynchronous batch validation job
+type JobStatus string
+
+// Possible job statuses
+const (
+	JobStatusQueued    JobStatus = "QUEUED"
+	JobStatusRunning   JobStatus = "RUNNING"
+	JobStatusCompleted JobStatus = "COMPLETED"
+	JobStatusFailed    JobStatus = "FAILED"
+)
+
+// Job represents an asynchronous batch validation job and its progress
+type Job struct {
+	ID           string                   `json:"id"`
+	Status       JobStatus                `json:"status"`
+	Total        int                      `json:"total"`
+	Processed    int                      `json:"processed"`
+	StatusCounts map[ValidationStatus]int `json:"status_counts"`
+	Error        string                   `json:"error,omitempty"`
+	CreatedAt    time.Time                `json:"created_at"`
+	UpdatedAt    time.Time                `json:"updated_at"`
+	CompletedAt  *time.Time               `json:"completed_at,omitempty"`
+}
+
+// JobResultsResponse represents a page of results of an asynchronous batch validation job
+type JobResultsResponse struct {
+	JobID      string                    `json:"job_id"`
+	Status     JobStatus                 `json:"status"`
+	Offset     int                       `json:"offset"`
+	Limit      int                       `json:"limit"`
+	Processed  int                       `json:"processed"`
+	Results    []EmailValidationResponse `json:"results"`
+	NextOffset *int                      `json:"next_offset,omitempty"` // Set when more results are available
+}
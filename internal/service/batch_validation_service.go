@@ -5,19 +5,37 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"emailvalidator/internal/model"
 	"emailvalidator/internal/utils"
+	"emailvalidator/pkg/validator"
 )
 
 // BatchValidationService handles batch email validation operations
 type BatchValidationService struct {
 	emailRuleValidator   EmailRuleValidator
 	domainValidationSvc  DomainValidationService
+	mailboxVerifier      MailboxVerifier
+	freeProviders        FreeProviderDetector
+	reservedDomains      ReservedDomainDetector
+	scoring              *validator.ScoringPolicy
 	metricsCollector     MetricsCollector
 	maxConcurrentWorkers int
 }
 
+// domainValidation holds the domain checks shared by every email of a domain
+type domainValidation struct {
+	DomainExists bool
+	MXRecords    bool
+	IsDisposable bool
+	// DomainReason and MXReason are the reason codes of failed domain and MX checks
+	DomainReason string
+	MXReason     string
+	// TimedOut is set when the checks didn't finish before the deadline of the request
+	TimedOut bool
+}
+
 // NewBatchValidationService creates a new instance of BatchValidationService
 func NewBatchValidationService(
 	ruleValidator EmailRuleValidator,
@@ -27,13 +45,59 @@ func NewBatchValidationService(
 	return &BatchValidationService{
 		emailRuleValidator:   ruleValidator,
 		domainValidationSvc:  domainValidationSvc,
+		scoring:              defaultScoringPolicy,
 		metricsCollector:     metricsCollector,
 		maxConcurrentWorkers: runtime.NumCPU() * 4,
 	}
 }
 
-// ValidateEmails performs validation on multiple email addresses concurrently
-func (s *BatchValidationService) ValidateEmails(emails []string) model.BatchValidationResponse {
+// SetMaxConcurrentWorkers sets the number of emails validated at the same time; values below 1 are ignored
+func (s *BatchValidationService) SetMaxConcurrentWorkers(workers int) {
+	if workers > 0 {
+		s.maxConcurrentWorkers = workers
+	}
+}
+
+// SetMailboxVerifier sets the SMTP mailbox verifier; nil disables mailbox probing
+func (s *BatchValidationService) SetMailboxVerifier(verifier MailboxVerifier) {
+	s.mailboxVerifier = verifier
+}
+
+// SetFreeProviderDetector sets the free email provider detector; nil disables detection
+func (s *BatchValidationService) SetFreeProviderDetector(detector FreeProviderDetector) {
+	s.freeProviders = detector
+}
+
+// SetReservedDomainDetector sets the reserved domain detector; nil validates reserved domains like any other
+func (s *BatchValidationService) SetReservedDomainDetector(detector ReservedDomainDetector) {
+	s.reservedDomains = detector
+}
+
+// SetScoringPolicy sets the scoring profiles results are scored with
+func (s *BatchValidationService) SetScoringPolicy(policy *validator.ScoringPolicy) {
+	s.scoring = policy
+}
+
+// ValidateEmails performs validation on multiple email addresses concurrently with the default scoring profile
+func (s *BatchValidationService) ValidateEmails(ctx context.Context, emails []string) model.BatchValidationResponse {
+	// The default profile always exists, so there is no error to report
+	response, _ := s.ValidateEmailsWithOptions(ctx, emails, ValidationOptions{})
+	return response
+}
+
+// ValidateEmailsWithOptions performs validation on multiple email addresses concurrently with the given options.
+// Lookups stop when ctx is done, and the results they leave unfinished are marked as timed out.
+// It fails with ErrUnknownProfile if the options pick a scoring profile that isn't configured
+func (s *BatchValidationService) ValidateEmailsWithOptions(ctx context.Context, emails []string, opts ValidationOptions) (model.BatchValidationResponse, error) {
+	settings, err := resolveOptions(s.scoring, opts, s.mailboxVerifier != nil)
+	if err != nil {
+		return model.BatchValidationResponse{}, err
+	}
+	return s.validateEmails(ctx, emails, settings), nil
+}
+
+// validateEmails validates the emails, checking each domain once, and scores them with the settings
+func (s *BatchValidationService) validateEmails(ctx context.Context, emails []string, settings validationSettings) model.BatchValidationResponse {
 	if len(emails) == 0 {
 		return model.BatchValidationResponse{Results: []model.EmailValidationResponse{}}
 	}
@@ -41,51 +105,185 @@ func (s *BatchValidationService) ValidateEmails(emails []string) model.BatchVali
 	// Group emails by domain
 	emailsByDomain := s.groupEmailsByDomain(emails)
 
-	// Process domain validations
-	domainResults := s.processDomainValidations(emailsByDomain)
+	// Process the selected domain validations
+	domainResults := s.processDomainValidations(ctx, emailsByDomain, settings.checks.domainChecks())
 
 	// Process individual emails
-	response := s.processEmails(emails, emailsByDomain, domainResults)
+	response := s.processEmails(ctx, emails, emailsByDomain, domainResults, settings)
 
 	return response
 }
 
-func (s *BatchValidationService) groupEmailsByDomain(emails []string) map[string][]string {
-	emailsByDomain := make(map[string][]string)
-	for _, email := range emails {
-		if email == "" {
-			continue
+// ValidateEmailsStream validates multiple email addresses concurrently and passes each result to emit
+// as soon as it is ready, so results arrive in completion order rather than request order.
+// Emails are validated as soon as their domain has been checked, without waiting for the other domains.
+// Validation stops at the first emit error or when ctx is cancelled.
+func (s *BatchValidationService) ValidateEmailsStream(
+	ctx context.Context,
+	emails []string,
+	emit func(model.EmailValidationResponse) error,
+) (model.BatchSummary, error) {
+	return s.ValidateEmailsStreamWithOptions(ctx, emails, ValidationOptions{}, emit)
+}
+
+// ValidateEmailsStreamWithOptions is ValidateEmailsStream with the given options.
+// Nothing is emitted if the options pick a scoring profile that isn't configured
+func (s *BatchValidationService) ValidateEmailsStreamWithOptions(
+	ctx context.Context,
+	emails []string,
+	opts ValidationOptions,
+	emit func(model.EmailValidationResponse) error,
+) (model.BatchSummary, error) {
+	start := time.Now()
+	summary := model.BatchSummary{StatusCounts: make(map[model.ValidationStatus]int)}
+	settings, err := resolveOptions(s.scoring, opts, s.mailboxVerifier != nil)
+	if err != nil {
+		return summary, err
+	}
+	if len(emails) == 0 {
+		return summary, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type emailJob struct {
+		email         string
+		domainResults map[string]domainValidation
+	}
+	jobs := make(chan emailJob)
+	results := make(chan model.EmailValidationResponse)
+	send := func(job emailJob) bool {
+		select {
+		case jobs <- job:
+			return true
+		case <-ctx.Done():
+			return false
 		}
+	}
 
-		parts := strings.Split(email, "@")
-		if len(parts) != 2 {
+	// Queue emails without a domain right away and the others once their domain is checked
+	var producers sync.WaitGroup
+	emailsByDomain := s.groupEmailsByDomain(emails)
+	producers.Add(1 + len(emailsByDomain))
+	go func() {
+		defer producers.Done()
+		for _, email := range emails {
+			if _, ok := s.checkableDomain(email); !ok && !send(emailJob{email: email}) {
+				return
+			}
+		}
+	}()
+	for domain, domainEmails := range emailsByDomain {
+		go func(d string, domainEmails []string) {
+			defer producers.Done()
+			domainResults := map[string]domainValidation{d: checkDomain(ctx, s.domainValidationSvc, d, settings.checks.domainChecks())}
+			for _, email := range domainEmails {
+				if !send(emailJob{email: email, domainResults: domainResults}) {
+					return
+				}
+			}
+		}(domain, domainEmails)
+	}
+	go func() {
+		producers.Wait()
+		close(jobs)
+	}()
+
+	// Start workers
+	workerCount := utils.MinInt(len(emails), s.maxConcurrentWorkers)
+	var workers sync.WaitGroup
+	workers.Add(workerCount)
+	for i := 0; i < workerCount; i++ {
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				select {
+				case results <- s.validateSingleEmail(ctx, job.email, job.domainResults, settings):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	// Emit results as they complete, draining the rest once emitting has failed
+	var emitErr error
+	for result := range results {
+		if emitErr != nil {
+			continue
+		}
+		if err := emit(result); err != nil {
+			emitErr = err
+			cancel()
 			continue
 		}
+		summary.Total++
+		summary.StatusCounts[result.Status]++
+	}
+	summary.DurationMs = time.Since(start).Milliseconds()
 
-		domain := parts[1]
+	if emitErr != nil {
+		return summary, emitErr
+	}
+	return summary, ctx.Err()
+}
+
+func (s *BatchValidationService) groupEmailsByDomain(emails []string) map[string][]string {
+	emailsByDomain := make(map[string][]string)
+	for _, email := range emails {
+		domain, ok := s.checkableDomain(email)
+		if !ok {
+			continue
+		}
 		emailsByDomain[domain] = append(emailsByDomain[domain], email)
 	}
 	return emailsByDomain
 }
 
-func (s *BatchValidationService) processDomainValidations(emailsByDomain map[string][]string) map[string]struct {
-	DomainExists bool
-	MXRecords    bool
-	IsDisposable bool
-} {
-	ctx := context.Background()
-	domainResults := make(map[string]struct {
-		DomainExists bool
-		MXRecords    bool
-		IsDisposable bool
-	})
+// checkableDomain returns the domain of an email whose domain needs DNS checks.
+// Emails failing syntax validation, such as those with an unknown TLD, and emails at reserved domains
+// are never looked up
+func (s *BatchValidationService) checkableDomain(email string) (string, bool) {
+	domain, ok := emailDomain(email)
+	if !ok || !s.emailRuleValidator.ValidateSyntax(email) {
+		return "", false
+	}
+	domain = asciiDomain(domain)
+	if isReservedDomain(s.reservedDomains, domain) {
+		return "", false
+	}
+	return domain, true
+}
+
+// emailDomain returns the domain of an email, if it has exactly one @
+func emailDomain(email string) (string, bool) {
+	if email == "" {
+		return "", false
+	}
+
+	parts := strings.Split(email, "@")
+	if len(parts) != 2 {
+		return "", false
+	}
+	return parts[1], true
+}
+
+func (s *BatchValidationService) processDomainValidations(
+	ctx context.Context,
+	emailsByDomain map[string][]string,
+	checks DomainChecks,
+) map[string]domainValidation {
+	domainResults := make(map[string]domainValidation)
 
 	var wg sync.WaitGroup
 	resultChan := make(chan struct {
-		domain       string
-		domainExists bool
-		hasMX        bool
-		isDisposable bool
+		domain string
+		result domainValidation
 	}, len(emailsByDomain))
 
 	// Process domains concurrently
@@ -93,13 +291,10 @@ func (s *BatchValidationService) processDomainValidations(emailsByDomain map[str
 		wg.Add(1)
 		go func(d string) {
 			defer wg.Done()
-			exists, hasMX, isDisposable := s.domainValidationSvc.ValidateDomainConcurrently(ctx, d)
 			resultChan <- struct {
-				domain       string
-				domainExists bool
-				hasMX        bool
-				isDisposable bool
-			}{d, exists, hasMX, isDisposable}
+				domain string
+				result domainValidation
+			}{d, checkDomain(ctx, s.domainValidationSvc, d, checks)}
 		}(domain)
 	}
 
@@ -111,24 +306,18 @@ func (s *BatchValidationService) processDomainValidations(emailsByDomain map[str
 
 	// Collect domain validation results
 	for result := range resultChan {
-		domainResults[result.domain] = struct {
-			DomainExists bool
-			MXRecords    bool
-			IsDisposable bool
-		}{result.domainExists, result.hasMX, result.isDisposable}
+		domainResults[result.domain] = result.result
 	}
 
 	return domainResults
 }
 
 func (s *BatchValidationService) processEmails(
+	ctx context.Context,
 	emails []string,
 	emailsByDomain map[string][]string,
-	domainResults map[string]struct {
-		DomainExists bool
-		MXRecords    bool
-		IsDisposable bool
-	},
+	domainResults map[string]domainValidation,
+	settings validationSettings,
 ) model.BatchValidationResponse {
 	var response model.BatchValidationResponse
 	resultsMap := make(map[string]model.EmailValidationResponse)
@@ -142,7 +331,7 @@ func (s *BatchValidationService) processEmails(
 	wg.Add(workerCount)
 
 	for i := 0; i < workerCount; i++ {
-		go s.emailValidationWorker(&wg, jobs, results, emailsByDomain, domainResults)
+		go s.emailValidationWorker(ctx, &wg, jobs, results, emailsByDomain, domainResults, settings)
 	}
 
 	// Send jobs
@@ -171,68 +360,84 @@ func (s *BatchValidationService) processEmails(
 }
 
 func (s *BatchValidationService) emailValidationWorker(
+	ctx context.Context,
 	wg *sync.WaitGroup,
 	jobs <-chan string,
 	results chan<- model.EmailValidationResponse,
 	emailsByDomain map[string][]string,
-	domainResults map[string]struct {
-		DomainExists bool
-		MXRecords    bool
-		IsDisposable bool
-	},
+	domainResults map[string]domainValidation,
+	settings validationSettings,
 ) {
 	defer wg.Done()
 
 	for email := range jobs {
-		response := s.validateSingleEmail(email, domainResults)
+		response := s.validateSingleEmail(ctx, email, domainResults, settings)
 		results <- response
 	}
 }
 
 func (s *BatchValidationService) validateSingleEmail(
+	ctx context.Context,
 	email string,
-	domainResults map[string]struct {
-		DomainExists bool
-		MXRecords    bool
-		IsDisposable bool
-	},
+	domainResults map[string]domainValidation,
+	settings validationSettings,
 ) model.EmailValidationResponse {
 	response := model.EmailValidationResponse{
 		Email:       email,
-		Validations: model.ValidationResults{},
+		Validations: model.ValidationResults{Skipped: settings.checks.skipped()},
 	}
 
 	if email == "" {
-		response.Status = model.ValidationStatusMissingEmail
+		setEarlyStatus(settings, &response, model.ValidationStatusMissingEmail, StatusRuleMissingEmail)
+		response.Reasons = []model.Reason{newReason(model.CheckSyntax, validator.ReasonEmptyEmail)}
 		return response
 	}
 
 	parts := strings.Split(email, "@")
 	if len(parts) != 2 {
-		response.Status = model.ValidationStatusInvalidFormat
+		setEarlyStatus(settings, &response, model.ValidationStatusInvalidFormat, StatusRuleInvalidSyntax)
+		response.Reasons = []model.Reason{syntaxReason(s.emailRuleValidator, email)}
 		return response
 	}
 
-	domain := parts[1]
 	response.Validations.Syntax = s.emailRuleValidator.ValidateSyntax(email)
 	if !response.Validations.Syntax {
-		response.Status = model.ValidationStatusInvalidFormat
+		setEarlyStatus(settings, &response, model.ValidationStatusInvalidFormat, StatusRuleInvalidSyntax)
+		response.Reasons = []model.Reason{syntaxReason(s.emailRuleValidator, email)}
+		return response
+	}
+	setAddressForms(&response)
+	domain := asciiDomain(parts[1])
+
+	// Reserved domains never receive email, so they are reported without any DNS lookup
+	if isReservedDomain(s.reservedDomains, domain) {
+		setEarlyStatus(settings, &response, model.ValidationStatusReserved, StatusRuleReservedDomain)
+		response.Reasons = []model.Reason{newReason(model.CheckDomain, validator.ReasonReservedDomain)}
 		return response
 	}
 
-	// Get domain validation results
-	domainValidation := domainResults[domain]
-	response.Validations.DomainExists = domainValidation.DomainExists
-	response.Validations.MXRecords = domainValidation.MXRecords
-	response.Validations.IsDisposable = domainValidation.IsDisposable
-	response.Validations.IsRoleBased = s.emailRuleValidator.IsRoleBased(email)
-	response.Validations.MailboxExists = response.Validations.MXRecords
+	// Get domain validation results, leaving skipped checks false
+	checks := settings.checks
+	domainResult := domainResults[domain]
+	response.Validations.DomainExists = domainResult.DomainExists
+	response.Validations.MXRecords = domainResult.MXRecords
+	response.Validations.IsDisposable = domainResult.IsDisposable
+	response.Validations.IsRoleBased = checks.runs(model.CheckRoleBased) && s.emailRuleValidator.IsRoleBased(email)
+	response.Validations.IsFreeProvider = isFreeProvider(s.freeProviders, domain)
+	response.Validations.IsSpoofSuspect = checks.runs(model.CheckSpoof) && isSpoofSuspect(s.emailRuleValidator, domain)
+	var mailbox mailboxCheck
+	if checks.runs(model.CheckMailbox) {
+		mailbox = verifyMailbox(ctx, s.mailboxVerifier, email, response.Validations.MXRecords)
+	}
+	response.Validations.MailboxExists = mailbox.exists
+	response.Validations.IsCatchAll = mailbox.catchAll
+	response.Pending = mailbox.pending
 
-	// Always check for typo suggestions
-	suggestions := s.emailRuleValidator.GetTypoSuggestions(email)
-	if len(suggestions) > 0 {
-		response.TypoSuggestion = suggestions[0]
+	// Check for typo suggestions unless skipped
+	if checks.runs(model.CheckTypo) {
+		response.TypoSuggestion = typoSuggestion(ctx, s.emailRuleValidator, email, checks)
 	}
+	response.TimedOut = domainResult.TimedOut || mailbox.timedOut
 
 	// Detect if email is an alias
 	if canonicalEmail := s.emailRuleValidator.DetectAlias(email); canonicalEmail != "" && canonicalEmail != email {
@@ -240,44 +445,14 @@ func (s *BatchValidationService) validateSingleEmail(
 	}
 
 	// Calculate score
-	validationMap := map[string]bool{
-		"syntax":         response.Validations.Syntax,
-		"domain_exists":  response.Validations.DomainExists,
-		"mx_records":     response.Validations.MXRecords,
-		"mailbox_exists": response.Validations.MailboxExists,
-		"is_disposable":  response.Validations.IsDisposable,
-		"is_role_based":  response.Validations.IsRoleBased,
-	}
-	response.Score = s.emailRuleValidator.CalculateScore(validationMap)
-
-	// Reduce score if there's a typo suggestion
-	if response.TypoSuggestion != "" {
-		response.Score = max(0, response.Score-20) // Ensure score doesn't go below 0
-	}
+	explanation := scoreResponse(settings, &response)
 
 	// Record validation score
 	s.metricsCollector.RecordValidationScore("overall", float64(response.Score))
+	response.Reasons = validationReasons(&response, domainResult, mailbox)
 
 	// Set status
-	response.Status = s.determineValidationStatus(&response)
+	setStatus(settings, &response, mailbox, explanation)
 
 	return response
 }
-
-func (s *BatchValidationService) determineValidationStatus(response *model.EmailValidationResponse) model.ValidationStatus {
-	switch {
-	case !response.Validations.DomainExists:
-		return model.ValidationStatusInvalidDomain
-	case !response.Validations.MXRecords:
-		response.Score = 40 // Override score for no MX records case
-		return model.ValidationStatusNoMXRecords
-	case response.Validations.IsDisposable:
-		return model.ValidationStatusDisposable
-	case response.Score >= 90:
-		return model.ValidationStatusValid
-	case response.Score >= 70:
-		return model.ValidationStatusProbablyValid
-	default:
-		return model.ValidationStatusInvalid
-	}
-}
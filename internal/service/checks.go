@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"emailvalidator/internal/model"
+)
+
+// Validation modes, each running a preset selection of checks
+const (
+	// ModeFast runs only the checks that need no network access
+	ModeFast = "fast"
+	// ModeStandard adds the DNS checks of the domain to the fast checks
+	ModeStandard = "standard"
+	// ModeDeep adds SMTP probing of the mailbox to the standard checks
+	ModeDeep = "deep"
+)
+
+// ErrInvalidChecks is returned when a request picks an unknown mode or check, or a check that can't run
+var ErrInvalidChecks = errors.New("invalid check selection")
+
+// selectableChecks are the checks a request can pick, named like the checks of reasons
+var selectableChecks = []string{
+	model.CheckSyntax,
+	model.CheckDomain,
+	model.CheckMX,
+	model.CheckDisposable,
+	model.CheckRoleBased,
+	model.CheckMailbox,
+	model.CheckTypo,
+	model.CheckSpoof,
+}
+
+// SelectableChecks returns the names of the checks a request can pick
+func SelectableChecks() []string {
+	return append([]string(nil), selectableChecks...)
+}
+
+// modeChecks holds the checks run by each mode
+var modeChecks = map[string][]string{
+	ModeFast: {model.CheckSyntax, model.CheckDisposable, model.CheckRoleBased, model.CheckTypo, model.CheckSpoof},
+	ModeStandard: {
+		model.CheckSyntax, model.CheckDomain, model.CheckMX, model.CheckDisposable,
+		model.CheckRoleBased, model.CheckTypo, model.CheckSpoof,
+	},
+	ModeDeep: {
+		model.CheckSyntax, model.CheckDomain, model.CheckMX, model.CheckDisposable,
+		model.CheckRoleBased, model.CheckMailbox, model.CheckTypo, model.CheckSpoof,
+	},
+}
+
+// checkSelection holds the checks a validation runs; a nil selection runs every configured check
+type checkSelection map[string]bool
+
+// runs reports whether the selection includes the check
+func (c checkSelection) runs(check string) bool {
+	return c == nil || c[check]
+}
+
+// skipped returns the selectable checks left out of the selection, or nil when none are
+func (c checkSelection) skipped() []string {
+	var skipped []string
+	for _, check := range selectableChecks {
+		if !c.runs(check) {
+			skipped = append(skipped, check)
+		}
+	}
+	return skipped
+}
+
+// domainChecks returns the domain lookups the selection needs
+func (c checkSelection) domainChecks() DomainChecks {
+	return DomainChecks{
+		Exists:     c.runs(model.CheckDomain),
+		MX:         c.runs(model.CheckMX),
+		Disposable: c.runs(model.CheckDisposable),
+	}
+}
+
+// selectChecks resolves the mode or checks of the options into a selection.
+// smtp tells whether mailbox probing is configured, which the mailbox check needs
+func selectChecks(opts ValidationOptions, smtp bool) (checkSelection, error) {
+	if opts.Mode != "" && len(opts.Checks) > 0 {
+		return nil, fmt.Errorf("%w: pick either a mode or checks", ErrInvalidChecks)
+	}
+
+	names := opts.Checks
+	if opts.Mode != "" {
+		var ok bool
+		if names, ok = modeChecks[strings.ToLower(opts.Mode)]; !ok {
+			return nil, fmt.Errorf("%w: unknown mode %q, available: %s, %s, %s", ErrInvalidChecks, opts.Mode, ModeFast, ModeStandard, ModeDeep)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	// Every other check depends on the syntax, so it always runs
+	selection := checkSelection{model.CheckSyntax: true}
+	for _, name := range names {
+		check := strings.ToLower(strings.TrimSpace(name))
+		if !isSelectableCheck(check) {
+			return nil, fmt.Errorf("%w: unknown check %q, available: %s", ErrInvalidChecks, name, strings.Join(selectableChecks, ", "))
+		}
+		selection[check] = true
+	}
+
+	if selection[model.CheckMailbox] {
+		if !smtp {
+			return nil, fmt.Errorf("%w: the mailbox check needs SMTP verification, which is disabled", ErrInvalidChecks)
+		}
+		// Mailboxes are probed at the mail exchangers of the domain
+		selection[model.CheckMX] = true
+	}
+	return selection, nil
+}
+
+// isSelectableCheck reports whether a request can pick the check
+func isSelectableCheck(check string) bool {
+	for _, known := range selectableChecks {
+		if check == known {
+			return true
+		}
+	}
+	return false
+}
@@ -3,8 +3,43 @@ package service
 import (
 	"context"
 	"sync"
+
+	"emailvalidator/pkg/validator"
 )
 
+// DomainCheck holds the results of the domain validation checks
+type DomainCheck struct {
+	Exists       bool
+	HasMX        bool
+	IsDisposable bool
+	// DomainReason is the reason code of a failed existence check
+	DomainReason string
+	// MXReason is the reason code of a failed MX records check
+	MXReason string
+	// TimedOut is set when ctx was done before the checks finished, leaving their results unreliable
+	TimedOut bool
+}
+
+// DomainChecks selects the domain validation checks to run
+type DomainChecks struct {
+	Exists     bool
+	MX         bool
+	Disposable bool
+}
+
+// AllDomainChecks selects every domain validation check
+var AllDomainChecks = DomainChecks{Exists: true, MX: true, Disposable: true}
+
+// any reports whether at least one check is selected
+func (c DomainChecks) any() bool {
+	return c.Exists || c.MX || c.Disposable
+}
+
+// lookups reports whether the selected checks need DNS lookups, which a deadline can cut short
+func (c DomainChecks) lookups() bool {
+	return c.Exists || c.MX
+}
+
 // ConcurrentDomainValidationService handles concurrent domain validation operations
 type ConcurrentDomainValidationService struct {
 	domainValidator DomainValidator
@@ -19,127 +54,87 @@ func NewConcurrentDomainValidationService(validator DomainValidator) *Concurrent
 
 // ValidateDomainConcurrently runs domain validation checks concurrently
 func (s *ConcurrentDomainValidationService) ValidateDomainConcurrently(ctx context.Context, domain string) (exists, hasMX, isDisposable bool) {
-	// Check if context is already done before starting
-	select {
-	case <-ctx.Done():
-		return false, false, false
-	default:
-		// Continue with validation
+	check := s.CheckDomainConcurrently(ctx, domain)
+	return check.Exists, check.HasMX, check.IsDisposable
+}
+
+// CheckDomainConcurrently runs domain validation checks concurrently and reports why failed checks failed.
+// Validators that can't explain their failures get generic reason codes.
+func (s *ConcurrentDomainValidationService) CheckDomainConcurrently(ctx context.Context, domain string) DomainCheck {
+	return s.CheckSelectedDomainConcurrently(ctx, domain, AllDomainChecks)
+}
+
+// CheckSelectedDomainConcurrently is CheckDomainConcurrently running only the selected checks.
+// The lookups of the other checks are skipped, and their results are false without a reason.
+// Lookups stop when ctx is done; the results are then dropped and marked as timed out
+func (s *ConcurrentDomainValidationService) CheckSelectedDomainConcurrently(ctx context.Context, domain string, checks DomainChecks) DomainCheck {
+	// Check if context is already done before starting any lookup
+	if ctx.Err() != nil && checks.lookups() {
+		return timedOutDomainCheck(checks)
 	}
 
-	var wg sync.WaitGroup
-	wg.Add(3)
+	reasonValidator, explains := s.domainValidator.(DomainReasonValidator)
 
-	// Channel for collecting validation results
-	results := make(chan struct {
-		validationType string
-		isValid        bool
-	}, 3)
+	var check DomainCheck
+	var wg sync.WaitGroup
 
 	// Run domain existence check
-	go func() {
-		defer wg.Done()
-		select {
-		case <-ctx.Done():
-			results <- struct {
-				validationType string
-				isValid        bool
-			}{"domain_exists", false}
-		default:
-			isValid := s.domainValidator.ValidateDomain(domain)
-			// Check context again after validation
-			select {
-			case <-ctx.Done():
-				results <- struct {
-					validationType string
-					isValid        bool
-				}{"domain_exists", false}
-			default:
-				results <- struct {
-					validationType string
-					isValid        bool
-				}{"domain_exists", isValid}
+	if checks.Exists {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if explains {
+				check.Exists, check.DomainReason = reasonValidator.CheckDomain(ctx, domain)
+			} else if check.Exists = s.domainValidator.ValidateDomain(ctx, domain); !check.Exists {
+				check.DomainReason = validator.ReasonNXDomain
 			}
-		}
-	}()
+		}()
+	}
 
 	// Run MX records check
-	go func() {
-		defer wg.Done()
-		select {
-		case <-ctx.Done():
-			results <- struct {
-				validationType string
-				isValid        bool
-			}{"mx_records", false}
-		default:
-			isValid := s.domainValidator.ValidateMXRecords(domain)
-			// Check context again after validation
-			select {
-			case <-ctx.Done():
-				results <- struct {
-					validationType string
-					isValid        bool
-				}{"mx_records", false}
-			default:
-				results <- struct {
-					validationType string
-					isValid        bool
-				}{"mx_records", isValid}
+	if checks.MX {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if explains {
+				check.HasMX, check.MXReason = reasonValidator.CheckMXRecords(ctx, domain)
+			} else if check.HasMX = s.domainValidator.ValidateMXRecords(ctx, domain); !check.HasMX {
+				check.MXReason = validator.ReasonNoMXRecords
 			}
-		}
-	}()
+		}()
+	}
 
 	// Run disposable domain check
-	go func() {
-		defer wg.Done()
-		select {
-		case <-ctx.Done():
-			results <- struct {
-				validationType string
-				isValid        bool
-			}{"is_disposable", false}
-		default:
-			isValid := s.domainValidator.IsDisposable(domain)
-			// Check context again after validation
-			select {
-			case <-ctx.Done():
-				results <- struct {
-					validationType string
-					isValid        bool
-				}{"is_disposable", false}
-			default:
-				results <- struct {
-					validationType string
-					isValid        bool
-				}{"is_disposable", isValid}
-			}
-		}
-	}()
-
-	// Close results channel after all goroutines complete
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// Collect validation results
-	for result := range results {
-		switch result.validationType {
-		case "domain_exists":
-			exists = result.isValid
-		case "mx_records":
-			hasMX = result.isValid
-		case "is_disposable":
-			isDisposable = result.isValid
-		}
+	if checks.Disposable {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			check.IsDisposable = s.domainValidator.IsDisposable(domain)
+		}()
 	}
 
-	// Final check if context was canceled
-	select {
-	case <-ctx.Done():
-		return false, false, false
-	default:
-		return exists, hasMX, isDisposable
+	wg.Wait()
+
+	// Results finished after ctx was done are dropped like those cut short
+	if ctx.Err() != nil && checks.lookups() {
+		return timedOutDomainCheck(checks)
+	}
+
+	// A domain that exists but has no MX answer lacks MX records rather than being missing
+	if check.Exists && check.MXReason == validator.ReasonNXDomain {
+		check.MXReason = validator.ReasonNoMXRecords
+	}
+	return check
+}
+
+// timedOutDomainCheck is the result of selected checks that didn't finish before ctx was done
+func timedOutDomainCheck(checks DomainChecks) DomainCheck {
+	check := DomainCheck{TimedOut: true}
+	if checks.Exists {
+		check.DomainReason = validator.ReasonDNSTimeout
+	}
+	if checks.MX {
+		check.MXReason = validator.ReasonDNSTimeout
 	}
+	return check
 }
@@ -4,12 +4,16 @@ package service
 
 import (
 	"context"
+	"io"
+	"log"
 	"runtime"
 	"strings"
 	"sync/atomic"
 	"time"
 
+	"emailvalidator/internal/config"
 	"emailvalidator/internal/model"
+	"emailvalidator/pkg/cache"
 	"emailvalidator/pkg/validator"
 )
 
@@ -18,29 +22,92 @@ type EmailService struct {
 	emailRuleValidator  EmailRuleValidator
 	domainValidator     DomainValidator
 	domainValidationSvc DomainValidationService
+	mailboxVerifier     MailboxVerifier
+	freeProviders       FreeProviderDetector
+	reservedDomains     ReservedDomainDetector
+	scoring             *validator.ScoringPolicy
 	batchValidationSvc  *BatchValidationService
+	jobSvc              *JobService
 	metricsCollector    MetricsCollector
+	validationTimeout   time.Duration
 	startTime           time.Time
 	requests            int64
 }
 
-// NewEmailService creates a new instance of EmailService
+// NewEmailService creates a new instance of EmailService with the default configuration
 func NewEmailService() (*EmailService, error) {
+	return NewEmailServiceWithConfig(config.Default())
+}
+
+// NewEmailServiceWithConfig creates a new instance of EmailService from the given configuration
+func NewEmailServiceWithConfig(cfg config.Config) (*EmailService, error) {
 	emailValidator, err := validator.NewEmailValidator()
 	if err != nil {
 		return nil, err
 	}
+	if len(cfg.DNS.Servers) > 0 {
+		resolver, err := validator.NewUpstreamResolver(cfg.DNS)
+		if err != nil {
+			return nil, err
+		}
+		emailValidator.SetResolver(resolver)
+	}
+	emailValidator.SetCacheTTLConfig(cfg.DNSCache)
+
+	var redisCache cache.Cache
+	if cfg.RedisURL != "" {
+		c, err := cache.NewRedisCache(cfg.RedisURL)
+		if err != nil {
+			log.Printf("Warning: Redis unavailable, domain cache stays in process: %v", err)
+		} else {
+			redisCache = c
+			emailValidator.SetRemoteCache(redisCache)
+		}
+	}
+
+	scoring, err := loadScoringPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	emailValidator.SetTypoKeyboardWeighted(cfg.TypoKeyboardWeighted)
+	emailValidator.AllowReservedDomains(cfg.AllowedReservedDomains...)
+
+	var mailboxVerifier MailboxVerifier
+	if cfg.SMTPEnabled {
+		emailValidator.EnableSMTPVerification(cfg.SMTP)
+		mailboxVerifier = emailValidator
+	}
 
 	metricsAdapter := NewMetricsAdapter()
 	domainValidationSvc := NewConcurrentDomainValidationService(emailValidator)
 	batchValidationSvc := NewBatchValidationService(emailValidator, domainValidationSvc, metricsAdapter)
+	batchValidationSvc.SetMailboxVerifier(mailboxVerifier)
+	batchValidationSvc.SetFreeProviderDetector(emailValidator)
+	batchValidationSvc.SetReservedDomainDetector(emailValidator)
+	batchValidationSvc.SetScoringPolicy(scoring)
+
+	var jobStore JobStore = NewMemoryJobStore(cfg.Jobs.TTL)
+	if cfg.Jobs.Store == config.JobStoreRedis {
+		if redisCache != nil {
+			jobStore = NewCacheJobStore(redisCache, cfg.Jobs.TTL)
+		} else {
+			log.Printf("Warning: Redis unavailable, batch jobs are kept in process")
+		}
+	}
 
 	return &EmailService{
 		emailRuleValidator:  emailValidator,
 		domainValidator:     emailValidator,
 		domainValidationSvc: domainValidationSvc,
+		mailboxVerifier:     mailboxVerifier,
+		freeProviders:       emailValidator,
+		reservedDomains:     emailValidator,
+		scoring:             scoring,
 		batchValidationSvc:  batchValidationSvc,
+		jobSvc:              NewJobService(batchValidationSvc, jobStore, cfg.Jobs),
 		metricsCollector:    metricsAdapter,
+		validationTimeout:   cfg.ValidationTimeout,
 		startTime:           time.Now(),
 	}, nil
 }
@@ -51,6 +118,8 @@ func NewEmailServiceWithDeps(validator interface{}) *EmailService {
 	// Type assertion to get the required interfaces
 	var emailRuleValidator EmailRuleValidator
 	var domainValidator DomainValidator
+	var freeProviders FreeProviderDetector
+	var reservedDomains ReservedDomainDetector
 
 	// Try to cast to the required interfaces
 	if v, ok := validator.(EmailRuleValidator); ok {
@@ -59,65 +128,135 @@ func NewEmailServiceWithDeps(validator interface{}) *EmailService {
 	if v, ok := validator.(DomainValidator); ok {
 		domainValidator = v
 	}
+	if v, ok := validator.(FreeProviderDetector); ok {
+		freeProviders = v
+	}
+	if v, ok := validator.(ReservedDomainDetector); ok {
+		reservedDomains = v
+	}
 
 	metricsAdapter := NewMetricsAdapter()
 	domainValidationSvc := NewConcurrentDomainValidationService(domainValidator)
 	batchValidationSvc := NewBatchValidationService(emailRuleValidator, domainValidationSvc, metricsAdapter)
+	batchValidationSvc.SetFreeProviderDetector(freeProviders)
+	batchValidationSvc.SetReservedDomainDetector(reservedDomains)
+	jobConfig := config.Default().Jobs
 
 	return &EmailService{
 		emailRuleValidator:  emailRuleValidator,
 		domainValidator:     domainValidator,
 		domainValidationSvc: domainValidationSvc,
+		freeProviders:       freeProviders,
+		reservedDomains:     reservedDomains,
+		scoring:             defaultScoringPolicy,
 		batchValidationSvc:  batchValidationSvc,
+		jobSvc:              NewJobService(batchValidationSvc, NewMemoryJobStore(jobConfig.TTL), jobConfig),
 		metricsCollector:    metricsAdapter,
 		startTime:           time.Now(),
 	}
 }
 
-// ValidateEmail performs all validation checks on a single email
-func (s *EmailService) ValidateEmail(email string) model.EmailValidationResponse {
+// loadScoringPolicy loads the scoring profiles from the configured file, or the config directory,
+// and selects the configured default profile
+func loadScoringPolicy(cfg config.Config) (*validator.ScoringPolicy, error) {
+	var scoring *validator.ScoringPolicy
+	var err error
+	if cfg.ScoringProfilesFile != "" {
+		scoring, err = validator.LoadScoringPolicy(cfg.ScoringProfilesFile)
+	} else {
+		scoring, err = validator.NewScoringPolicy()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ScoringProfile != "" {
+		if err := scoring.SetDefaultProfile(cfg.ScoringProfile); err != nil {
+			return nil, err
+		}
+	}
+	return scoring, nil
+}
+
+// ValidateEmail performs all validation checks on a single email with the default scoring profile
+func (s *EmailService) ValidateEmail(ctx context.Context, email string) model.EmailValidationResponse {
+	// The default profile always exists, so there is no error to report
+	response, _ := s.ValidateEmailWithOptions(ctx, email, ValidationOptions{})
+	return response
+}
+
+// ValidateEmailWithOptions performs all validation checks on a single email with the given options.
+// Lookups stop when ctx is done or the validation timeout passes, and the response is then marked as timed out.
+// It fails with ErrUnknownProfile if the options pick a scoring profile that isn't configured
+func (s *EmailService) ValidateEmailWithOptions(ctx context.Context, email string, opts ValidationOptions) (model.EmailValidationResponse, error) {
 	atomic.AddInt64(&s.requests, 1)
 
+	settings, err := resolveOptions(s.scoring, opts, s.mailboxVerifier != nil)
+	if err != nil {
+		return model.EmailValidationResponse{}, err
+	}
+	ctx, cancel := s.withValidationTimeout(ctx)
+	defer cancel()
+
 	response := model.EmailValidationResponse{
 		Email:       email,
-		Validations: model.ValidationResults{},
+		Validations: model.ValidationResults{Skipped: settings.checks.skipped()},
 	}
 
 	if email == "" {
-		response.Status = model.ValidationStatusMissingEmail
-		return response
+		setEarlyStatus(settings, &response, model.ValidationStatusMissingEmail, StatusRuleMissingEmail)
+		response.Reasons = []model.Reason{newReason(model.CheckSyntax, validator.ReasonEmptyEmail)}
+		return response, nil
 	}
 
 	// Validate syntax first
 	response.Validations.Syntax = s.emailRuleValidator.ValidateSyntax(email)
 	if !response.Validations.Syntax {
-		response.Status = model.ValidationStatusInvalidFormat
-		return response
+		setEarlyStatus(settings, &response, model.ValidationStatusInvalidFormat, StatusRuleInvalidSyntax)
+		response.Reasons = []model.Reason{syntaxReason(s.emailRuleValidator, email)}
+		return response, nil
 	}
 
 	// Extract domain and validate
 	parts := strings.Split(email, "@")
 	if len(parts) != 2 {
-		response.Status = model.ValidationStatusInvalidFormat
-		return response
+		setEarlyStatus(settings, &response, model.ValidationStatusInvalidFormat, StatusRuleInvalidSyntax)
+		response.Reasons = []model.Reason{syntaxReason(s.emailRuleValidator, email)}
+		return response, nil
+	}
+	setAddressForms(&response)
+	domain := asciiDomain(parts[1])
+
+	// Reserved domains never receive email, so they are reported without any DNS lookup
+	if isReservedDomain(s.reservedDomains, domain) {
+		setEarlyStatus(settings, &response, model.ValidationStatusReserved, StatusRuleReservedDomain)
+		response.Reasons = []model.Reason{newReason(model.CheckDomain, validator.ReasonReservedDomain)}
+		return response, nil
 	}
-	domain := parts[1]
-
-	// Perform domain validations concurrently
-	exists, hasMX, isDisposable := s.domainValidationSvc.ValidateDomainConcurrently(context.Background(), domain)
 
-	// Set validation results
-	response.Validations.DomainExists = exists
-	response.Validations.MXRecords = hasMX
-	response.Validations.IsDisposable = isDisposable
-	response.Validations.IsRoleBased = s.emailRuleValidator.IsRoleBased(email)
-	response.Validations.MailboxExists = hasMX
+	// Perform the selected domain validations concurrently
+	domainResult := checkDomain(ctx, s.domainValidationSvc, domain, settings.checks.domainChecks())
+
+	// Set validation results, leaving skipped checks false
+	checks := settings.checks
+	response.Validations.DomainExists = domainResult.DomainExists
+	response.Validations.MXRecords = domainResult.MXRecords
+	response.Validations.IsDisposable = domainResult.IsDisposable
+	response.Validations.IsRoleBased = checks.runs(model.CheckRoleBased) && s.emailRuleValidator.IsRoleBased(email)
+	response.Validations.IsFreeProvider = isFreeProvider(s.freeProviders, domain)
+	response.Validations.IsSpoofSuspect = checks.runs(model.CheckSpoof) && isSpoofSuspect(s.emailRuleValidator, domain)
+	var mailbox mailboxCheck
+	if checks.runs(model.CheckMailbox) {
+		mailbox = verifyMailbox(ctx, s.mailboxVerifier, email, domainResult.MXRecords)
+	}
+	response.Validations.MailboxExists = mailbox.exists
+	response.Validations.IsCatchAll = mailbox.catchAll
+	response.Pending = mailbox.pending
 
-	// Always check for typo suggestions
-	suggestions := s.emailRuleValidator.GetTypoSuggestions(email)
-	if len(suggestions) > 0 {
-		response.TypoSuggestion = suggestions[0]
+	// Check for typo suggestions unless skipped
+	if checks.runs(model.CheckTypo) {
+		response.TypoSuggestion = typoSuggestion(ctx, s.emailRuleValidator, email, checks)
 	}
+	response.TimedOut = domainResult.TimedOut || mailbox.timedOut
 
 	// Detect if email is an alias
 	if canonicalEmail := s.emailRuleValidator.DetectAlias(email); canonicalEmail != "" && canonicalEmail != email {
@@ -125,57 +264,158 @@ func (s *EmailService) ValidateEmail(email string) model.EmailValidationResponse
 	}
 
 	// Calculate score
-	validationMap := map[string]bool{
-		"syntax":         response.Validations.Syntax,
-		"domain_exists":  response.Validations.DomainExists,
-		"mx_records":     response.Validations.MXRecords,
-		"mailbox_exists": response.Validations.MailboxExists,
-		"is_disposable":  response.Validations.IsDisposable,
-		"is_role_based":  response.Validations.IsRoleBased,
-	}
-	response.Score = s.emailRuleValidator.CalculateScore(validationMap)
-
-	// Reduce score if there's a typo suggestion
-	if response.TypoSuggestion != "" {
-		response.Score = max(0, response.Score-20) // Ensure score doesn't go below 0
-	}
+	explanation := scoreResponse(settings, &response)
 
 	// Record validation score
 	s.metricsCollector.RecordValidationScore("overall", float64(response.Score))
+	response.Reasons = validationReasons(&response, domainResult, mailbox)
 
 	// Set status based on validations
-	switch {
-	case !response.Validations.DomainExists:
-		response.Status = model.ValidationStatusInvalidDomain
-	case !response.Validations.MXRecords:
-		response.Status = model.ValidationStatusNoMXRecords
-		response.Score = 40 // Override score for no MX records case
-	case response.Validations.IsDisposable:
-		response.Status = model.ValidationStatusDisposable
-	case response.Score >= 90:
-		response.Status = model.ValidationStatusValid
-	case response.Score >= 70:
-		response.Status = model.ValidationStatusProbablyValid
-	default:
-		response.Status = model.ValidationStatusInvalid
+	setStatus(settings, &response, mailbox, explanation)
+
+	return response, nil
+}
+
+// withValidationTimeout bounds ctx by the validation timeout, if there is one
+func (s *EmailService) withValidationTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.validationTimeout <= 0 {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeout(ctx, s.validationTimeout)
+}
 
-	return response
+// CheckValidationOptions reports whether the options can be used, such as whether their scoring profile exists
+func (s *EmailService) CheckValidationOptions(opts ValidationOptions) error {
+	_, err := resolveOptions(s.scoring, opts, s.mailboxVerifier != nil)
+	return err
+}
+
+// ValidateDomain performs the domain checks of email validation on a domain alone.
+// Lookups stop when ctx is done or the validation timeout passes
+func (s *EmailService) ValidateDomain(ctx context.Context, domain string) model.DomainValidationResponse {
+	atomic.AddInt64(&s.requests, 1)
+	domain = asciiDomain(strings.ToLower(strings.TrimSpace(domain)))
+	if isReservedDomain(s.reservedDomains, domain) {
+		return model.DomainValidationResponse{Domain: domain, IsReserved: true}
+	}
+
+	ctx, cancel := s.withValidationTimeout(ctx)
+	defer cancel()
+	exists, hasMX, isDisposable := s.domainValidationSvc.ValidateDomainConcurrently(ctx, domain)
+	return model.DomainValidationResponse{
+		Domain:         domain,
+		DomainExists:   exists,
+		MXRecords:      hasMX,
+		IsDisposable:   isDisposable,
+		IsFreeProvider: isFreeProvider(s.freeProviders, domain),
+	}
 }
 
 // ValidateEmails performs validation on multiple email addresses concurrently
-func (s *EmailService) ValidateEmails(emails []string) model.BatchValidationResponse {
+func (s *EmailService) ValidateEmails(ctx context.Context, emails []string) model.BatchValidationResponse {
+	// The default profile always exists, so there is no error to report
+	response, _ := s.ValidateEmailsWithOptions(ctx, emails, ValidationOptions{})
+	return response
+}
+
+// ValidateEmailsWithOptions performs validation on multiple email addresses concurrently with the given options.
+// Lookups stop when ctx is done or the validation timeout passes, and unfinished results are marked as timed out
+func (s *EmailService) ValidateEmailsWithOptions(ctx context.Context, emails []string, opts ValidationOptions) (model.BatchValidationResponse, error) {
 	atomic.AddInt64(&s.requests, 1)
-	return s.batchValidationSvc.ValidateEmails(emails)
+	ctx, cancel := s.withValidationTimeout(ctx)
+	defer cancel()
+	return s.batchValidationSvc.ValidateEmailsWithOptions(ctx, emails, opts)
+}
+
+// ValidateEmailsStream validates multiple email addresses concurrently, passing each result to emit as it completes
+func (s *EmailService) ValidateEmailsStream(ctx context.Context, emails []string, emit func(model.EmailValidationResponse) error) (model.BatchSummary, error) {
+	return s.ValidateEmailsStreamWithOptions(ctx, emails, ValidationOptions{}, emit)
+}
+
+// ValidateEmailsStreamWithOptions is ValidateEmailsStream with the given options
+func (s *EmailService) ValidateEmailsStreamWithOptions(
+	ctx context.Context,
+	emails []string,
+	opts ValidationOptions,
+	emit func(model.EmailValidationResponse) error,
+) (model.BatchSummary, error) {
+	atomic.AddInt64(&s.requests, 1)
+	return s.batchValidationSvc.ValidateEmailsStreamWithOptions(ctx, emails, opts, emit)
+}
+
+// ValidateCSV validates the email column of a CSV file and writes it back with result columns appended
+func (s *EmailService) ValidateCSV(ctx context.Context, r io.Reader, w io.Writer, column string) (model.BatchSummary, error) {
+	return s.ValidateCSVWithOptions(ctx, r, w, column, ValidationOptions{})
+}
+
+// ValidateCSVWithOptions is ValidateCSV with the given options, stopping when ctx is done
+func (s *EmailService) ValidateCSVWithOptions(
+	ctx context.Context,
+	r io.Reader,
+	w io.Writer,
+	column string,
+	opts ValidationOptions,
+) (model.BatchSummary, error) {
+	atomic.AddInt64(&s.requests, 1)
+	return s.batchValidationSvc.ValidateCSVWithOptions(ctx, r, w, column, opts)
+}
+
+// ValidateText validates a plain-text file with one email per line and writes the results as CSV
+func (s *EmailService) ValidateText(ctx context.Context, r io.Reader, w io.Writer) (model.BatchSummary, error) {
+	return s.ValidateTextWithOptions(ctx, r, w, ValidationOptions{})
+}
+
+// ValidateTextWithOptions is ValidateText with the given options, stopping when ctx is done
+func (s *EmailService) ValidateTextWithOptions(ctx context.Context, r io.Reader, w io.Writer, opts ValidationOptions) (model.BatchSummary, error) {
+	atomic.AddInt64(&s.requests, 1)
+	return s.batchValidationSvc.ValidateTextWithOptions(ctx, r, w, opts)
+}
+
+// SubmitJob queues an asynchronous validation of the emails and returns the created job
+func (s *EmailService) SubmitJob(ctx context.Context, emails []string) (model.Job, error) {
+	return s.SubmitJobWithOptions(ctx, emails, ValidationOptions{})
+}
+
+// SubmitJobWithOptions queues an asynchronous validation of the emails with the given options
+func (s *EmailService) SubmitJobWithOptions(ctx context.Context, emails []string, opts ValidationOptions) (model.Job, error) {
+	atomic.AddInt64(&s.requests, 1)
+	return s.jobSvc.SubmitWithOptions(ctx, emails, opts)
+}
+
+// GetJob returns the progress of an asynchronous validation job
+func (s *EmailService) GetJob(ctx context.Context, id string) (model.Job, error) {
+	atomic.AddInt64(&s.requests, 1)
+	return s.jobSvc.Get(ctx, id)
+}
+
+// GetJobResults returns a page of the results of an asynchronous validation job
+func (s *EmailService) GetJobResults(ctx context.Context, id string, offset, limit int) (model.JobResultsResponse, error) {
+	atomic.AddInt64(&s.requests, 1)
+	return s.jobSvc.Results(ctx, id, offset, limit)
 }
 
 // GetTypoSuggestions returns suggestions for possible email typos
-func (s *EmailService) GetTypoSuggestions(email string) model.TypoSuggestionResponse {
+func (s *EmailService) GetTypoSuggestions(ctx context.Context, email string) model.TypoSuggestionResponse {
 	atomic.AddInt64(&s.requests, 1)
-	suggestions := s.emailRuleValidator.GetTypoSuggestions(email)
 	response := model.TypoSuggestionResponse{
 		Email: email,
 	}
+
+	if ranker, ok := s.emailRuleValidator.(TypoRanker); ok {
+		for _, suggestion := range ranker.RankTypoSuggestions(ctx, email) {
+			response.Suggestions = append(response.Suggestions, model.TypoSuggestion{
+				Email:      suggestion.Email,
+				Confidence: suggestion.Confidence,
+			})
+		}
+		if len(response.Suggestions) > 0 {
+			response.TypoSuggestion = response.Suggestions[0].Email
+			response.Confidence = response.Suggestions[0].Confidence
+		}
+		return response
+	}
+
+	suggestions := s.emailRuleValidator.GetTypoSuggestions(ctx, email)
 	if len(suggestions) > 0 {
 		response.TypoSuggestion = suggestions[0]
 	}
@@ -199,6 +439,95 @@ func (s *EmailService) GetAPIStatus() model.APIStatus {
 	}
 }
 
+// isFreeProvider reports whether the domain belongs to a free email provider, if detection is configured
+func isFreeProvider(detector FreeProviderDetector, domain string) bool {
+	return detector != nil && detector.IsFreeProvider(domain)
+}
+
+// asciiDomain returns the ASCII form of the domain used by DNS and the domain lists,
+// or the domain as is if it can't be converted
+func asciiDomain(domain string) string {
+	if ascii, err := validator.ToASCIIDomain(domain); err == nil {
+		return ascii
+	}
+	return domain
+}
+
+// setAddressForms fills in the Unicode and ASCII forms of a syntactically valid email
+// and whether it needs SMTPUTF8
+func setAddressForms(response *model.EmailValidationResponse) {
+	if unicodeForm, asciiForm, ok := validator.EmailForms(response.Email); ok {
+		response.EmailUnicode = unicodeForm
+		response.EmailASCII = asciiForm
+	}
+	response.RequiresSMTPUTF8 = validator.RequiresSMTPUTF8(response.Email)
+}
+
+// typoSuggestion returns the likeliest correction of the email, or "" if there is none. Without the
+// MX check selected it looks nothing up, if the rule validator can suggest offline
+func typoSuggestion(ctx context.Context, ruleValidator EmailRuleValidator, email string, checks checkSelection) string {
+	if ranker, ok := ruleValidator.(OfflineTypoRanker); ok && !checks.runs(model.CheckMX) {
+		if suggestions := ranker.RankTypoSuggestionsOffline(email); len(suggestions) > 0 {
+			return suggestions[0].Email
+		}
+		return ""
+	}
+	if suggestions := ruleValidator.GetTypoSuggestions(ctx, email); len(suggestions) > 0 {
+		return suggestions[0]
+	}
+	return ""
+}
+
+// isSpoofSuspect reports whether the domain imitates another one, if the rule validator can tell
+func isSpoofSuspect(ruleValidator EmailRuleValidator, domain string) bool {
+	checker, ok := ruleValidator.(SpoofChecker)
+	return ok && checker.IsSpoofSuspect(domain)
+}
+
+// isReservedDomain reports whether the domain is reserved or special-use, if detection is configured
+func isReservedDomain(detector ReservedDomainDetector, domain string) bool {
+	return detector != nil && detector.IsReservedDomain(domain)
+}
+
+// mailboxCheck holds the outcome of an SMTP mailbox check as used for scoring and status
+type mailboxCheck struct {
+	exists   bool
+	catchAll bool
+	// rejected is set when the mail exchanger definitively refused the recipient
+	rejected bool
+	// unknown is set when the mail exchanger only answered with temporary failures
+	unknown bool
+	// timedOut is set when the probe was cut short by the deadline of the request
+	timedOut bool
+	// pending is set when a retry of the probe is still scheduled
+	pending bool
+}
+
+// verifyMailbox probes the mailbox when a verifier is configured and the domain accepts mail.
+// Without a conclusive probe the result falls back to whether the domain has MX records.
+// The probe stops when ctx is done
+func verifyMailbox(ctx context.Context, verifier MailboxVerifier, email string, hasMX bool) mailboxCheck {
+	if verifier == nil || !hasMX {
+		return mailboxCheck{exists: hasMX}
+	}
+	if ctx.Err() != nil {
+		return mailboxCheck{exists: hasMX, timedOut: true}
+	}
+
+	result := verifier.VerifyMailbox(ctx, email)
+	if ctx.Err() != nil {
+		return mailboxCheck{exists: hasMX, timedOut: true}
+	}
+	if result.Inconclusive() {
+		return mailboxCheck{
+			exists:  hasMX,
+			unknown: result.Temporary(),
+			pending: result.Pending,
+		}
+	}
+	return mailboxCheck{exists: result.Exists, catchAll: result.CatchAll, rejected: result.Permanent()}
+}
+
 // SetDomainValidationService sets the domain validation service (for testing)
 func (s *EmailService) SetDomainValidationService(svc DomainValidationService) {
 	s.domainValidationSvc = svc
@@ -214,6 +543,54 @@ func (s *EmailService) SetBatchValidationService(svc *BatchValidationService) {
 	s.batchValidationSvc = svc
 }
 
+// SetValidationTimeout sets how long single and batch validations may take before their unfinished
+// lookups are abandoned; 0 leaves them bounded only by the request
+func (s *EmailService) SetValidationTimeout(timeout time.Duration) {
+	s.validationTimeout = timeout
+}
+
+// SetConcurrency sets the number of emails validated at the same time within a batch
+func (s *EmailService) SetConcurrency(workers int) {
+	s.batchValidationSvc.SetMaxConcurrentWorkers(workers)
+}
+
+// SetJobService sets the asynchronous job service (for testing)
+func (s *EmailService) SetJobService(svc *JobService) {
+	s.jobSvc = svc
+}
+
+// SetMailboxVerifier sets the SMTP mailbox verifier used by single and batch validation
+func (s *EmailService) SetMailboxVerifier(verifier MailboxVerifier) {
+	s.mailboxVerifier = verifier
+	if s.batchValidationSvc != nil {
+		s.batchValidationSvc.SetMailboxVerifier(verifier)
+	}
+}
+
+// SetFreeProviderDetector sets the free email provider detector used by single and batch validation
+func (s *EmailService) SetFreeProviderDetector(detector FreeProviderDetector) {
+	s.freeProviders = detector
+	if s.batchValidationSvc != nil {
+		s.batchValidationSvc.SetFreeProviderDetector(detector)
+	}
+}
+
+// SetReservedDomainDetector sets the reserved domain detector used by single and batch validation
+func (s *EmailService) SetReservedDomainDetector(detector ReservedDomainDetector) {
+	s.reservedDomains = detector
+	if s.batchValidationSvc != nil {
+		s.batchValidationSvc.SetReservedDomainDetector(detector)
+	}
+}
+
+// SetScoringPolicy sets the scoring profiles used by single and batch validation
+func (s *EmailService) SetScoringPolicy(policy *validator.ScoringPolicy) {
+	s.scoring = policy
+	if s.batchValidationSvc != nil {
+		s.batchValidationSvc.SetScoringPolicy(policy)
+	}
+}
+
 // SetEmailRuleValidator sets the email rule validator (for testing)
 func (s *EmailService) SetEmailRuleValidator(validator EmailRuleValidator) {
 	s.emailRuleValidator = validator
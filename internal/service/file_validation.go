@@ -0,0 +1,274 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"emailvalidator/internal/model"
+)
+
+// fileValidationChunkSize is the number of rows validated together while streaming a file
+const fileValidationChunkSize = 1000
+
+var (
+	// ErrEmptyFile is returned when an uploaded file has no rows
+	ErrEmptyFile = errors.New("file is empty")
+	// ErrEmailColumnNotFound is returned when the email column can't be detected or doesn't exist
+	ErrEmailColumnNotFound = errors.New("email column not found")
+	// ErrInvalidCSV is returned when an uploaded CSV file can't be parsed
+	ErrInvalidCSV = errors.New("invalid CSV")
+)
+
+// resultColumns are appended to every row of a validated file
+var resultColumns = []string{"status", "score", "reason"}
+
+// emailColumnNames are header names recognised as the email column, in order of preference
+var emailColumnNames = []string{"email", "email address", "email_address", "e-mail", "e-mail address", "mail"}
+
+// statusReasons explains each validation status in the reason column
+var statusReasons = map[model.ValidationStatus]string{
+	model.ValidationStatusValid:         "email address is valid",
+	model.ValidationStatusProbablyValid: "email address is probably valid",
+	model.ValidationStatusInvalid:       "email address failed validation checks",
+	model.ValidationStatusMissingEmail:  "email address is missing",
+	model.ValidationStatusInvalidFormat: "email address syntax is invalid",
+	model.ValidationStatusInvalidDomain: "domain does not exist",
+	model.ValidationStatusReserved:      "domain is reserved and never receives email",
+	model.ValidationStatusNoMXRecords:   "domain does not accept email",
+	model.ValidationStatusDisposable:    "domain is a disposable email provider",
+	model.ValidationStatusCatchAll:      "domain accepts any recipient",
+	model.ValidationStatusRisky:         "domain accepts any recipient and the address scored low",
+	model.ValidationStatusUnknown:       "mail server temporarily refused the check",
+}
+
+// ruleReasons explains the status rules that set a status for a reason other than its usual one,
+// such as UNKNOWN when validation timed out rather than the mail server deferring the check
+var ruleReasons = map[string]string{
+	StatusRuleTimedOut:        "validation did not finish before the deadline",
+	StatusRuleMailboxRejected: "mail server rejected the mailbox",
+}
+
+// ValidateCSV validates the email column of a CSV file and writes the file back with
+// status, score and reason columns appended to every row.
+// column selects the email column by header name or 1-based position; empty detects it.
+// Nothing is written when the column can't be resolved, so callers can still report the error.
+func (s *BatchValidationService) ValidateCSV(ctx context.Context, r io.Reader, w io.Writer, column string) (model.BatchSummary, error) {
+	return s.ValidateCSVWithOptions(ctx, r, w, column, ValidationOptions{})
+}
+
+// ValidateCSVWithOptions is ValidateCSV with the given options, stopping lookups when ctx is done.
+// Nothing is written if the options pick a scoring profile that isn't configured
+func (s *BatchValidationService) ValidateCSVWithOptions(
+	ctx context.Context,
+	r io.Reader,
+	w io.Writer,
+	column string,
+	opts ValidationOptions,
+) (model.BatchSummary, error) {
+	start := time.Now()
+	summary := model.BatchSummary{StatusCounts: make(map[model.ValidationStatus]int)}
+	settings, err := resolveOptions(s.scoring, opts, s.mailboxVerifier != nil)
+	if err != nil {
+		return summary, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	first, err := reader.Read()
+	if err == io.EOF {
+		return summary, ErrEmptyFile
+	}
+	if err != nil {
+		return summary, fmt.Errorf("%w: %w", ErrInvalidCSV, err)
+	}
+	if len(first) > 0 {
+		first[0] = strings.TrimPrefix(first[0], "\ufeff")
+	}
+
+	index, hasHeader, err := EmailColumn(first, column)
+	if err != nil {
+		return summary, err
+	}
+
+	writer := csv.NewWriter(w)
+	rows := make([][]string, 0, fileValidationChunkSize)
+	if hasHeader {
+		if err := writer.Write(append(first, resultColumns...)); err != nil {
+			return summary, err
+		}
+	} else {
+		rows = append(rows, first)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return summary, fmt.Errorf("%w: %w", ErrInvalidCSV, err)
+		}
+
+		rows = append(rows, record)
+		if len(rows) == fileValidationChunkSize {
+			if err := s.writeValidatedRows(ctx, writer, rows, index, settings, &summary); err != nil {
+				return summary, err
+			}
+			rows = rows[:0]
+		}
+	}
+
+	err = s.writeValidatedRows(ctx, writer, rows, index, settings, &summary)
+	summary.DurationMs = time.Since(start).Milliseconds()
+	return summary, err
+}
+
+// ValidateText validates a plain-text file with one email per line and writes the results as CSV
+func (s *BatchValidationService) ValidateText(ctx context.Context, r io.Reader, w io.Writer) (model.BatchSummary, error) {
+	return s.ValidateTextWithOptions(ctx, r, w, ValidationOptions{})
+}
+
+// ValidateTextWithOptions is ValidateText with the given options, stopping lookups when ctx is done.
+// Nothing is written if the options pick a scoring profile that isn't configured
+func (s *BatchValidationService) ValidateTextWithOptions(ctx context.Context, r io.Reader, w io.Writer, opts ValidationOptions) (model.BatchSummary, error) {
+	start := time.Now()
+	summary := model.BatchSummary{StatusCounts: make(map[model.ValidationStatus]int)}
+	settings, err := resolveOptions(s.scoring, opts, s.mailboxVerifier != nil)
+	if err != nil {
+		return summary, err
+	}
+	scanner := bufio.NewScanner(r)
+	writer := csv.NewWriter(w)
+	rows := make([][]string, 0, fileValidationChunkSize)
+	headerWritten := false
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" {
+			continue
+		}
+
+		if !headerWritten {
+			if err := writer.Write(append([]string{"email"}, resultColumns...)); err != nil {
+				return summary, err
+			}
+			headerWritten = true
+		}
+
+		rows = append(rows, []string{line})
+		if len(rows) == fileValidationChunkSize {
+			if err := s.writeValidatedRows(ctx, writer, rows, 0, settings, &summary); err != nil {
+				return summary, err
+			}
+			rows = rows[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return summary, err
+	}
+	if !headerWritten {
+		return summary, ErrEmptyFile
+	}
+
+	err = s.writeValidatedRows(ctx, writer, rows, 0, settings, &summary)
+	summary.DurationMs = time.Since(start).Milliseconds()
+	return summary, err
+}
+
+// writeValidatedRows validates the email column of the rows, writes them with the result columns
+// and adds their statuses to the summary
+func (s *BatchValidationService) writeValidatedRows(
+	ctx context.Context,
+	writer *csv.Writer,
+	rows [][]string,
+	index int,
+	settings validationSettings,
+	summary *model.BatchSummary,
+) error {
+	// Stop once the client is gone rather than timing out every remaining row
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	emails := make([]string, len(rows))
+	for i, row := range rows {
+		if index < len(row) {
+			emails[i] = strings.TrimSpace(row[index])
+		}
+	}
+
+	// The status rule of each result picks its reason
+	settings.explain = true
+	response := s.validateEmails(ctx, emails, settings)
+	for i, row := range rows {
+		result := response.Results[i]
+		row = append(row, string(result.Status), strconv.Itoa(result.Score), ResultReason(result))
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+		summary.Total++
+		summary.StatusCounts[result.Status]++
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// StatusReason returns a short explanation of a validation status
+func StatusReason(status model.ValidationStatus) string {
+	return statusReasons[status]
+}
+
+// ResultReason returns a short explanation of the status of a result, telling apart the rules that
+// can set it when the result is explained
+func ResultReason(result model.EmailValidationResponse) string {
+	if result.Explanation != nil {
+		if reason, ok := ruleReasons[result.Explanation.StatusRule.Rule]; ok {
+			return reason
+		}
+	}
+	return StatusReason(result.Status)
+}
+
+// EmailColumn finds the email column from the first row of a CSV file.
+// column selects it by header name or 1-based position; empty detects it from the header or the data.
+// It reports whether the first row is a header rather than data.
+func EmailColumn(first []string, column string) (int, bool, error) {
+	if column != "" {
+		for i, name := range first {
+			if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(column)) {
+				return i, true, nil
+			}
+		}
+		if n, err := strconv.Atoi(column); err == nil && n >= 1 && n <= len(first) {
+			return n - 1, !strings.Contains(first[n-1], "@"), nil
+		}
+		return 0, false, fmt.Errorf("%w: %q", ErrEmailColumnNotFound, column)
+	}
+
+	for _, candidate := range emailColumnNames {
+		for i, name := range first {
+			if strings.EqualFold(strings.TrimSpace(name), candidate) {
+				return i, true, nil
+			}
+		}
+	}
+
+	// Without a recognised header, the first row is data if one of its cells holds an address
+	for i, value := range first {
+		if strings.Contains(value, "@") {
+			return i, false, nil
+		}
+	}
+
+	return 0, false, ErrEmailColumnNotFound
+}
@@ -3,19 +3,21 @@ package service
 import (
 	"context"
 	"emailvalidator/internal/model"
+	"emailvalidator/pkg/validator"
 )
 
 // EmailValidator defines the contract for email validation operations
 type EmailValidator interface {
-	ValidateEmail(email string) model.EmailValidationResponse
-	ValidateEmails(emails []string) model.BatchValidationResponse
-	GetTypoSuggestions(email string) model.TypoSuggestionResponse
+	ValidateEmail(ctx context.Context, email string) model.EmailValidationResponse
+	ValidateEmails(ctx context.Context, emails []string) model.BatchValidationResponse
+	GetTypoSuggestions(ctx context.Context, email string) model.TypoSuggestionResponse
 }
 
-// DomainValidator defines the contract for domain-specific validations
+// DomainValidator defines the contract for domain-specific validations.
+// Lookups stop when ctx is done
 type DomainValidator interface {
-	ValidateDomain(domain string) bool
-	ValidateMXRecords(domain string) bool
+	ValidateDomain(ctx context.Context, domain string) bool
+	ValidateMXRecords(ctx context.Context, domain string) bool
 	IsDisposable(domain string) bool
 }
 
@@ -23,8 +25,7 @@ type DomainValidator interface {
 type EmailRuleValidator interface {
 	ValidateSyntax(email string) bool
 	IsRoleBased(email string) bool
-	CalculateScore(validations map[string]bool) int
-	GetTypoSuggestions(email string) []string
+	GetTypoSuggestions(ctx context.Context, email string) []string
 	DetectAlias(email string) string
 }
 
@@ -45,3 +46,55 @@ type AliasDetector interface {
 	// Returns empty string if the email is not an alias
 	DetectAlias(email string) string
 }
+
+// MailboxVerifier defines the contract for probing whether a mailbox exists, stopping when ctx is done
+type MailboxVerifier interface {
+	VerifyMailbox(ctx context.Context, email string) validator.SMTPResult
+}
+
+// FreeProviderDetector defines the contract for detecting free email provider domains
+type FreeProviderDetector interface {
+	IsFreeProvider(domain string) bool
+}
+
+// ReservedDomainDetector defines the contract for detecting reserved and special-use domains
+type ReservedDomainDetector interface {
+	IsReservedDomain(domain string) bool
+}
+
+// SpoofChecker defines the contract for detecting domains that imitate other domains with lookalike characters
+type SpoofChecker interface {
+	IsSpoofSuspect(domain string) bool
+}
+
+// SyntaxDiagnoser defines the contract for explaining why an email's syntax is invalid
+type SyntaxDiagnoser interface {
+	// SyntaxReason returns the reason code of the first syntax rule the email breaks, or "" if it is valid
+	SyntaxReason(email string) string
+}
+
+// DomainReasonValidator defines the contract for domain validations that explain their failures
+type DomainReasonValidator interface {
+	CheckDomain(ctx context.Context, domain string) (bool, string)
+	CheckMXRecords(ctx context.Context, domain string) (bool, string)
+}
+
+// DomainCheckService defines the contract for concurrent domain validations that explain their failures
+type DomainCheckService interface {
+	CheckDomainConcurrently(ctx context.Context, domain string) DomainCheck
+}
+
+// SelectiveDomainCheckService defines the contract for concurrent domain validations that run only the selected checks
+type SelectiveDomainCheckService interface {
+	CheckSelectedDomainConcurrently(ctx context.Context, domain string, checks DomainChecks) DomainCheck
+}
+
+// TypoRanker defines the contract for typo suggestions that come with a confidence
+type TypoRanker interface {
+	RankTypoSuggestions(ctx context.Context, email string) []validator.TypoSuggestion
+}
+
+// OfflineTypoRanker defines the contract for typo suggestions that need no DNS lookups
+type OfflineTypoRanker interface {
+	RankTypoSuggestionsOffline(email string) []validator.TypoSuggestion
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"emailvalidator/internal/config"
+	"emailvalidator/internal/model"
+	"emailvalidator/internal/utils"
+	"emailvalidator/pkg/monitoring"
+)
+
+const (
+	// jobChunkSize is the number of emails validated and stored together
+	jobChunkSize = 100
+	// defaultJobResultsLimit is the page size used when none is requested
+	defaultJobResultsLimit = 100
+	// maxJobResultsLimit caps the page size of job results
+	maxJobResultsLimit = 1000
+)
+
+var (
+	// ErrNoEmails is returned when a job is submitted without emails
+	ErrNoEmails = errors.New("at least one email is required")
+	// ErrTooManyEmails is returned when a job exceeds the configured maximum size
+	ErrTooManyEmails = errors.New("too many emails in job")
+	// ErrJobQueueFull is returned when every worker is busy and the queue has no room left
+	ErrJobQueueFull = errors.New("job queue is full")
+)
+
+// jobRequest is a queued job waiting for a worker
+type jobRequest struct {
+	id       string
+	emails   []string
+	settings validationSettings
+	// saved receives the outcome of storing the job, which happens once it has its place in the queue
+	saved chan error
+}
+
+// JobService runs batch validations asynchronously on a bounded pool of workers
+type JobService struct {
+	batchValidationSvc *BatchValidationService
+	store              JobStore
+	workers            int
+	maxEmails          int
+	queue              chan jobRequest
+	startOnce          sync.Once
+}
+
+// NewJobService creates a new instance of JobService.
+// Workers are started on the first submission.
+func NewJobService(batchValidationSvc *BatchValidationService, store JobStore, cfg config.JobConfig) *JobService {
+	return &JobService{
+		batchValidationSvc: batchValidationSvc,
+		store:              store,
+		workers:            max(1, cfg.Workers),
+		maxEmails:          cfg.MaxEmails,
+		queue:              make(chan jobRequest, max(0, cfg.QueueSize)),
+	}
+}
+
+// Submit creates a job for the emails and queues it for validation with the default scoring profile
+func (s *JobService) Submit(ctx context.Context, emails []string) (model.Job, error) {
+	return s.SubmitWithOptions(ctx, emails, ValidationOptions{})
+}
+
+// SubmitWithOptions creates a job for the emails and queues it for validation with the given options
+func (s *JobService) SubmitWithOptions(ctx context.Context, emails []string, opts ValidationOptions) (model.Job, error) {
+	if len(emails) == 0 {
+		return model.Job{}, ErrNoEmails
+	}
+	if s.maxEmails > 0 && len(emails) > s.maxEmails {
+		return model.Job{}, fmt.Errorf("%w: %d exceeds the limit of %d", ErrTooManyEmails, len(emails), s.maxEmails)
+	}
+
+	settings, err := resolveOptions(s.batchValidationSvc.scoring, opts, s.batchValidationSvc.mailboxVerifier != nil)
+	if err != nil {
+		return model.Job{}, err
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return model.Job{}, err
+	}
+
+	now := time.Now().UTC()
+	job := model.Job{
+		ID:           id,
+		Status:       model.JobStatusQueued,
+		Total:        len(emails),
+		StatusCounts: make(map[model.ValidationStatus]int),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	s.startOnce.Do(s.startWorkers)
+
+	// Take a place in the queue before storing anything, so a rejected job leaves no record behind
+	// that the client never learns the ID of
+	req := jobRequest{id: id, emails: emails, settings: settings, saved: make(chan error, 1)}
+	select {
+	case s.queue <- req:
+	default:
+		monitoring.RecordBatchJob("rejected")
+		return model.Job{}, ErrJobQueueFull
+	}
+
+	err = s.store.SaveJob(ctx, job)
+	req.saved <- err
+	if err != nil {
+		return model.Job{}, err
+	}
+
+	monitoring.RecordBatchJob("submitted")
+	return job, nil
+}
+
+// Get returns the current state of a job
+func (s *JobService) Get(ctx context.Context, id string) (model.Job, error) {
+	return s.store.GetJob(ctx, id)
+}
+
+// Results returns a page of the results processed so far, starting at offset
+func (s *JobService) Results(ctx context.Context, id string, offset, limit int) (model.JobResultsResponse, error) {
+	job, err := s.store.GetJob(ctx, id)
+	if err != nil {
+		return model.JobResultsResponse{}, err
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = defaultJobResultsLimit
+	}
+	limit = utils.MinInt(limit, maxJobResultsLimit)
+
+	response := model.JobResultsResponse{
+		JobID:     job.ID,
+		Status:    job.Status,
+		Offset:    offset,
+		Limit:     limit,
+		Processed: job.Processed,
+		Results:   []model.EmailValidationResponse{},
+	}
+
+	end := utils.MinInt(offset+limit, job.Processed)
+	for index := offset / jobChunkSize; index*jobChunkSize < end; index++ {
+		chunk, err := s.store.GetChunk(ctx, id, index)
+		if err != nil {
+			return model.JobResultsResponse{}, err
+		}
+		start := index * jobChunkSize
+		from := max(offset-start, 0)
+		to := utils.MinInt(end-start, len(chunk))
+		if from < to {
+			response.Results = append(response.Results, chunk[from:to]...)
+		}
+	}
+
+	// Point at the next page while there are results left, including those not processed yet
+	if next := max(end, offset); next < job.Total {
+		response.NextOffset = &next
+	}
+
+	return response, nil
+}
+
+// startWorkers starts the pool of job workers
+func (s *JobService) startWorkers() {
+	for i := 0; i < s.workers; i++ {
+		go s.worker()
+	}
+}
+
+// worker processes queued jobs one at a time
+func (s *JobService) worker() {
+	for req := range s.queue {
+		s.process(req)
+	}
+}
+
+// process validates the emails of a job chunk by chunk, storing results and progress as it goes
+func (s *JobService) process(req jobRequest) {
+	ctx := context.Background()
+
+	// A job that couldn't be stored was never handed to the client
+	if err := <-req.saved; err != nil {
+		return
+	}
+
+	job, err := s.store.GetJob(ctx, req.id)
+	if err != nil {
+		log.Printf("Warning: job %s disappeared before processing: %v", req.id, err)
+		monitoring.RecordBatchJob("failed")
+		return
+	}
+
+	job.Status = model.JobStatusRunning
+	job.UpdatedAt = time.Now().UTC()
+	if err := s.store.SaveJob(ctx, job); err != nil {
+		s.fail(ctx, job, err)
+		return
+	}
+
+	for index := 0; index*jobChunkSize < len(req.emails); index++ {
+		start := index * jobChunkSize
+		end := utils.MinInt(start+jobChunkSize, len(req.emails))
+
+		result := s.batchValidationSvc.validateEmails(ctx, req.emails[start:end], req.settings)
+		if err := s.store.SaveChunk(ctx, job.ID, index, result.Results); err != nil {
+			s.fail(ctx, job, err)
+			return
+		}
+
+		for _, r := range result.Results {
+			job.StatusCounts[r.Status]++
+		}
+		job.Processed = end
+		job.UpdatedAt = time.Now().UTC()
+		if err := s.store.SaveJob(ctx, job); err != nil {
+			s.fail(ctx, job, err)
+			return
+		}
+	}
+
+	completedAt := time.Now().UTC()
+	job.Status = model.JobStatusCompleted
+	job.UpdatedAt = completedAt
+	job.CompletedAt = &completedAt
+	if err := s.store.SaveJob(ctx, job); err != nil {
+		log.Printf("Warning: failed to record completion of job %s: %v", job.ID, err)
+	}
+	monitoring.RecordBatchJob("completed")
+}
+
+// fail marks the job as failed with the given error
+func (s *JobService) fail(ctx context.Context, job model.Job, err error) {
+	log.Printf("Warning: job %s failed: %v", job.ID, err)
+
+	job.Status = model.JobStatusFailed
+	job.Error = err.Error()
+	job.UpdatedAt = time.Now().UTC()
+	if err := s.store.SaveJob(ctx, job); err != nil {
+		log.Printf("Warning: failed to record failure of job %s: %v", job.ID, err)
+	}
+	monitoring.RecordBatchJob("failed")
+}
+
+// newJobID returns a random job identifier
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"emailvalidator/internal/model"
+	"emailvalidator/pkg/cache"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrJobNotFound is returned when a job does not exist or has expired
+var ErrJobNotFound = errors.New("job not found")
+
+// JobStore defines the contract for persisting batch validation jobs and their results.
+// Results are stored in fixed-size chunks so they can be paged through without loading the whole job.
+type JobStore interface {
+	// SaveJob creates or replaces the job metadata
+	SaveJob(ctx context.Context, job model.Job) error
+	// GetJob returns the job metadata or ErrJobNotFound
+	GetJob(ctx context.Context, id string) (model.Job, error)
+	// SaveChunk stores the results of one chunk of the job
+	SaveChunk(ctx context.Context, id string, index int, results []model.EmailValidationResponse) error
+	// GetChunk returns the results of one chunk of the job or ErrJobNotFound
+	GetChunk(ctx context.Context, id string, index int) ([]model.EmailValidationResponse, error)
+}
+
+// memoryJob holds a job and its result chunks in memory
+type memoryJob struct {
+	job       model.Job
+	chunks    map[int][]model.EmailValidationResponse
+	expiresAt time.Time
+}
+
+// MemoryJobStore implements JobStore in process memory
+type MemoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*memoryJob
+	ttl  time.Duration
+}
+
+// NewMemoryJobStore creates a new in-memory job store that forgets jobs ttl after their last update
+func NewMemoryJobStore(ttl time.Duration) *MemoryJobStore {
+	return &MemoryJobStore{
+		jobs: make(map[string]*memoryJob),
+		ttl:  ttl,
+	}
+}
+
+// SaveJob creates or replaces the job metadata
+func (s *MemoryJobStore) SaveJob(ctx context.Context, job model.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired()
+	entry, ok := s.jobs[job.ID]
+	if !ok {
+		entry = &memoryJob{chunks: make(map[int][]model.EmailValidationResponse)}
+		s.jobs[job.ID] = entry
+	}
+	entry.job = copyJob(job)
+	entry.expiresAt = time.Now().Add(s.ttl)
+	return nil
+}
+
+// GetJob returns the job metadata or ErrJobNotFound
+func (s *MemoryJobStore) GetJob(ctx context.Context, id string) (model.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lookup(id)
+	if !ok {
+		return model.Job{}, ErrJobNotFound
+	}
+	return copyJob(entry.job), nil
+}
+
+// SaveChunk stores the results of one chunk of the job
+func (s *MemoryJobStore) SaveChunk(ctx context.Context, id string, index int, results []model.EmailValidationResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lookup(id)
+	if !ok {
+		return ErrJobNotFound
+	}
+	entry.chunks[index] = results
+	return nil
+}
+
+// GetChunk returns the results of one chunk of the job or ErrJobNotFound
+func (s *MemoryJobStore) GetChunk(ctx context.Context, id string, index int) ([]model.EmailValidationResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lookup(id)
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	results, ok := entry.chunks[index]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return results, nil
+}
+
+// lookup returns an unexpired job; the caller must hold the lock
+func (s *MemoryJobStore) lookup(id string) (*memoryJob, bool) {
+	entry, ok := s.jobs[id]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// removeExpired drops jobs past their expiry; the caller must hold the lock
+func (s *MemoryJobStore) removeExpired() {
+	now := time.Now()
+	for id, entry := range s.jobs {
+		if now.After(entry.expiresAt) {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// copyJob returns a copy of the job that shares no maps with the original,
+// so stored jobs aren't changed by callers updating their progress
+func copyJob(job model.Job) model.Job {
+	counts := make(map[model.ValidationStatus]int, len(job.StatusCounts))
+	for status, count := range job.StatusCounts {
+		counts[status] = count
+	}
+	job.StatusCounts = counts
+	return job
+}
+
+// CacheJobStore implements JobStore on top of a cache.Cache such as the Redis cache,
+// so jobs can be read from any replica
+type CacheJobStore struct {
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+// NewCacheJobStore creates a new job store backed by the given cache, expiring jobs after ttl
+func NewCacheJobStore(c cache.Cache, ttl time.Duration) *CacheJobStore {
+	return &CacheJobStore{
+		cache: c,
+		ttl:   ttl,
+	}
+}
+
+// SaveJob creates or replaces the job metadata
+func (s *CacheJobStore) SaveJob(ctx context.Context, job model.Job) error {
+	return s.cache.Set(ctx, jobKey(job.ID), job, s.ttl)
+}
+
+// GetJob returns the job metadata or ErrJobNotFound
+func (s *CacheJobStore) GetJob(ctx context.Context, id string) (model.Job, error) {
+	var job model.Job
+	if err := s.cache.Get(ctx, jobKey(id), &job); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return model.Job{}, ErrJobNotFound
+		}
+		return model.Job{}, err
+	}
+	return job, nil
+}
+
+// SaveChunk stores the results of one chunk of the job
+func (s *CacheJobStore) SaveChunk(ctx context.Context, id string, index int, results []model.EmailValidationResponse) error {
+	return s.cache.Set(ctx, jobChunkKey(id, index), results, s.ttl)
+}
+
+// GetChunk returns the results of one chunk of the job or ErrJobNotFound
+func (s *CacheJobStore) GetChunk(ctx context.Context, id string, index int) ([]model.EmailValidationResponse, error) {
+	var results []model.EmailValidationResponse
+	if err := s.cache.Get(ctx, jobChunkKey(id, index), &results); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return results, nil
+}
+
+// jobKey builds the cache key of the job metadata
+func jobKey(id string) string {
+	return "emailvalidator:job:" + id
+}
+
+// jobChunkKey builds the cache key of a chunk of job results
+func jobChunkKey(id string, index int) string {
+	return jobKey(id) + ":results:" + strconv.Itoa(index)
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+
+	"emailvalidator/internal/model"
+	"emailvalidator/pkg/validator"
+)
+
+// newReason builds the reason of a check from its code
+func newReason(check, code string) model.Reason {
+	return model.Reason{Check: check, Code: code, Message: validator.ReasonMessage(code)}
+}
+
+// syntaxReason explains why the email failed the syntax check
+func syntaxReason(ruleValidator EmailRuleValidator, email string) model.Reason {
+	if diagnoser, ok := ruleValidator.(SyntaxDiagnoser); ok {
+		if code := diagnoser.SyntaxReason(email); code != "" {
+			return newReason(model.CheckSyntax, code)
+		}
+	}
+	return newReason(model.CheckSyntax, validator.ReasonInvalidSyntax)
+}
+
+// checkDomain runs the selected domain checks, with reasons when the service can explain failures.
+// Services that can't select checks run all of them, and the results of the others are dropped.
+// Checks still running when ctx is done are marked as timed out
+func checkDomain(ctx context.Context, svc DomainValidationService, domain string, checks DomainChecks) domainValidation {
+	if !checks.any() {
+		return domainValidation{}
+	}
+	if selector, ok := svc.(SelectiveDomainCheckService); ok {
+		return newDomainValidation(selector.CheckSelectedDomainConcurrently(ctx, domain, checks))
+	}
+
+	var result domainValidation
+	if checker, ok := svc.(DomainCheckService); ok {
+		result = newDomainValidation(checker.CheckDomainConcurrently(ctx, domain))
+	} else {
+		exists, hasMX, isDisposable := svc.ValidateDomainConcurrently(ctx, domain)
+		result = domainValidation{DomainExists: exists, MXRecords: hasMX, IsDisposable: isDisposable}
+		if !exists {
+			result.DomainReason = validator.ReasonNXDomain
+		}
+		if !hasMX {
+			result.MXReason = validator.ReasonNoMXRecords
+		}
+	}
+
+	if !checks.Exists {
+		result.DomainExists, result.DomainReason = false, ""
+	}
+	if !checks.MX {
+		result.MXRecords, result.MXReason = false, ""
+	}
+	if !checks.Disposable {
+		result.IsDisposable = false
+	}
+	result.TimedOut = ctx.Err() != nil && checks.lookups()
+	return result
+}
+
+// newDomainValidation converts the outcome of the domain checks
+func newDomainValidation(check DomainCheck) domainValidation {
+	return domainValidation{
+		DomainExists: check.Exists,
+		MXRecords:    check.HasMX,
+		IsDisposable: check.IsDisposable,
+		DomainReason: check.DomainReason,
+		MXReason:     check.MXReason,
+		TimedOut:     check.TimedOut,
+	}
+}
+
+// validationReasons lists why the checks after syntax failed or lowered the score of a response
+func validationReasons(response *model.EmailValidationResponse, domain domainValidation, mailbox mailboxCheck) []model.Reason {
+	var reasons []model.Reason
+	if !response.Validations.DomainExists && domain.DomainReason != "" {
+		reasons = append(reasons, newReason(model.CheckDomain, domain.DomainReason))
+	}
+	// Skip the MX reason when it only repeats why the domain doesn't exist
+	if !response.Validations.MXRecords && domain.MXReason != "" &&
+		(response.Validations.DomainExists || domain.MXReason != domain.DomainReason) {
+		reasons = append(reasons, newReason(model.CheckMX, domain.MXReason))
+	}
+	if response.Validations.IsDisposable {
+		reasons = append(reasons, newReason(model.CheckDisposable, validator.ReasonDisposableDomain))
+	}
+	if response.Validations.IsRoleBased {
+		reasons = append(reasons, newReason(model.CheckRoleBased, validator.ReasonRoleBased))
+	}
+	switch {
+	case mailbox.rejected:
+		reasons = append(reasons, newReason(model.CheckMailbox, validator.ReasonMailboxNotFound))
+	case mailbox.catchAll:
+		reasons = append(reasons, newReason(model.CheckMailbox, validator.ReasonCatchAll))
+	case mailbox.timedOut:
+		reasons = append(reasons, newReason(model.CheckMailbox, validator.ReasonSMTPTimeout))
+	case mailbox.unknown:
+		reasons = append(reasons, newReason(model.CheckMailbox, validator.ReasonSMTPTemporaryFail))
+	}
+	if response.Validations.IsSpoofSuspect {
+		reasons = append(reasons, newReason(model.CheckSpoof, validator.ReasonSpoofSuspect))
+	}
+	if response.TypoSuggestion != "" {
+		reasons = append(reasons, newReason(model.CheckTypo, validator.ReasonPossibleTypo))
+	}
+	return reasons
+}
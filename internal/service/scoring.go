@@ -0,0 +1,225 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"emailvalidator/internal/model"
+	"emailvalidator/pkg/validator"
+)
+
+// ErrUnknownProfile is returned when a request picks a scoring profile that isn't configured
+var ErrUnknownProfile = errors.New("unknown scoring profile")
+
+// defaultScoringPolicy holds only the built-in default profile, for services built without configuration
+var defaultScoringPolicy = validator.NewScoringPolicyWithProfiles(nil)
+
+// Rules that set the status, as reported in score explanations
+const (
+	StatusRuleMissingEmail           = "missing_email"
+	StatusRuleTimedOut               = "timed_out"
+	StatusRuleInvalidSyntax          = "invalid_syntax"
+	StatusRuleReservedDomain         = "reserved_domain"
+	StatusRuleDomainNotFound         = "domain_not_found"
+	StatusRuleNoMXRecords            = "no_mx_records"
+	StatusRuleDisposable             = "disposable"
+	StatusRuleMailboxUnknown         = "mailbox_unknown"
+	StatusRuleMailboxRejected        = "mailbox_rejected"
+	StatusRuleCatchAll               = "catch_all"
+	StatusRuleCatchAllLowScore       = "catch_all_low_score"
+	StatusRuleValidThreshold         = "valid_threshold"
+	StatusRuleProbablyValidThreshold = "probably_valid_threshold"
+	StatusRuleBelowThresholds        = "below_thresholds"
+)
+
+// ValidationOptions holds the per-request settings of a validation
+type ValidationOptions struct {
+	// Profile names the scoring profile; empty uses the configured default
+	Profile string
+	// Explain adds a breakdown of the score and status to each response
+	Explain bool
+	// Mode picks a preset of checks, such as ModeFast; empty runs every configured check
+	Mode string
+	// Checks lists the checks to run instead of a mode
+	Checks []string
+}
+
+// validationSettings holds the options of a validation resolved against the service configuration
+type validationSettings struct {
+	profileName string
+	profile     validator.ScoringProfile
+	explain     bool
+	checks      checkSelection
+}
+
+// resolveOptions looks up the scoring profile and the checks picked by the options.
+// smtp tells whether mailbox probing is configured.
+// It fails with ErrUnknownProfile or ErrInvalidChecks if the options can't be used
+func resolveOptions(policy *validator.ScoringPolicy, opts ValidationOptions, smtp bool) (validationSettings, error) {
+	profile, ok := policy.Profile(opts.Profile)
+	if !ok {
+		return validationSettings{}, fmt.Errorf("%w %q, available: %s", ErrUnknownProfile, opts.Profile, strings.Join(policy.Names(), ", "))
+	}
+	checks, err := selectChecks(opts, smtp)
+	if err != nil {
+		return validationSettings{}, err
+	}
+
+	name := opts.Profile
+	if name == "" {
+		name = policy.DefaultProfile()
+	}
+	return validationSettings{profileName: name, profile: profile, explain: opts.Explain, checks: checks}, nil
+}
+
+// scoreChecks maps the checks weighted by scoring profiles to the checks a request can skip
+var scoreChecks = map[string]string{
+	"syntax":         model.CheckSyntax,
+	"domain_exists":  model.CheckDomain,
+	"mx_records":     model.CheckMX,
+	"mailbox_exists": model.CheckMailbox,
+	"is_disposable":  model.CheckDisposable,
+	"is_role_based":  model.CheckRoleBased,
+}
+
+// scoreResponse sets the score of a response from its validations, lowered by the penalties of its findings.
+// When checks were skipped, the points of the checks that ran are scaled to the full score.
+// It returns the explanation of the score, which the status is added to by setStatus
+func scoreResponse(settings validationSettings, response *model.EmailValidationResponse) *model.ScoreExplanation {
+	profile := settings.profile
+	explanation := &model.ScoreExplanation{Profile: settings.profileName}
+	add := func(check string, points int, detail string) {
+		response.Score += points
+		explanation.Contributions = append(explanation.Contributions, model.ScoreContribution{
+			Check:  check,
+			Points: points,
+			Detail: detail,
+		})
+	}
+	// penalize subtracts a penalty without going below 0
+	penalize := func(check string, penalty int, detail string) {
+		add(check, -min(penalty, response.Score), detail)
+	}
+
+	response.Score = 0
+	validations := map[string]bool{
+		"syntax":         response.Validations.Syntax,
+		"domain_exists":  response.Validations.DomainExists,
+		"mx_records":     response.Validations.MXRecords,
+		"mailbox_exists": response.Validations.MailboxExists,
+		"is_disposable":  response.Validations.IsDisposable,
+		"is_role_based":  response.Validations.IsRoleBased,
+	}
+	totalWeight := 0
+	for name := range validations {
+		totalWeight += profile.Weights[name]
+		if !response.Validations.Ran(scoreChecks[name]) {
+			delete(validations, name)
+		}
+	}
+
+	ranWeight := 0
+	for _, check := range profile.ScoreChecks(validations) {
+		outcome := "failed"
+		if check.Passed {
+			outcome = "passed"
+		}
+		add(check.Check, check.Points, fmt.Sprintf("%s, %d of %d points", outcome, check.Points, check.Weight))
+		ranWeight += check.Weight
+	}
+
+	// Scale the points to what they would be out of every weight, rounding to the nearest point
+	if ranWeight > 0 && ranWeight < totalWeight {
+		points := response.Score
+		scaled := (points*totalWeight + ranWeight/2) / ranWeight
+		add("skipped_checks", scaled-points, fmt.Sprintf("%d of %d points of the checks that ran, scaled to %d", points, ranWeight, totalWeight))
+	}
+
+	// Reduce score if there's a typo suggestion
+	if response.TypoSuggestion != "" {
+		penalize("typo", profile.Penalties.Typo, "possible typo of "+response.TypoSuggestion)
+	}
+
+	// Reduce score if the domain accepts any recipient, as the mailbox check proves nothing
+	if response.Validations.IsCatchAll {
+		penalize("catch_all", profile.Penalties.CatchAll, "domain accepts any recipient")
+	}
+
+	// Reduce score if the domain imitates another one, as the address may be used for phishing
+	if response.Validations.IsSpoofSuspect {
+		penalize("spoof", profile.Penalties.Spoof, "domain imitates another domain with lookalike characters")
+	}
+
+	return explanation
+}
+
+// setStatus sets the status of a scored response and records the rule that set it in the explanation.
+// Domains without MX records get the fixed score of the profile
+func setStatus(settings validationSettings, response *model.EmailValidationResponse, mailbox mailboxCheck, explanation *model.ScoreExplanation) {
+	thresholds := settings.profile.Thresholds
+	// status sets the status and its rule, explained by the reason of the status unless a message is given
+	status := func(status model.ValidationStatus, rule, message string) {
+		if message == "" {
+			message = StatusReason(status)
+		}
+		response.Status = status
+		explanation.StatusRule = model.StatusRule{Rule: rule, Message: message}
+	}
+
+	validations := response.Validations
+	switch {
+	case response.TimedOut:
+		// Checks cut short by the deadline failed without a real answer, so no other rule can be trusted
+		status(model.ValidationStatusUnknown, StatusRuleTimedOut, ruleReasons[StatusRuleTimedOut])
+	case !validations.DomainExists && validations.Ran(model.CheckDomain):
+		status(model.ValidationStatusInvalidDomain, StatusRuleDomainNotFound, "")
+	case !validations.MXRecords && validations.Ran(model.CheckMX):
+		override := settings.profile.NoMXScore - response.Score
+		response.Score = settings.profile.NoMXScore
+		explanation.Contributions = append(explanation.Contributions, model.ScoreContribution{
+			Check:  "no_mx_override",
+			Points: override,
+			Detail: fmt.Sprintf("domain has no MX records, score set to %d", settings.profile.NoMXScore),
+		})
+		status(model.ValidationStatusNoMXRecords, StatusRuleNoMXRecords, "")
+	case response.Validations.IsDisposable:
+		status(model.ValidationStatusDisposable, StatusRuleDisposable, "")
+	case mailbox.unknown:
+		status(model.ValidationStatusUnknown, StatusRuleMailboxUnknown, "")
+	case mailbox.rejected:
+		// A permanent refusal of the recipient is a definite answer, whatever the other checks scored
+		status(model.ValidationStatusInvalid, StatusRuleMailboxRejected, ruleReasons[StatusRuleMailboxRejected])
+	case response.Validations.IsCatchAll && response.Score >= thresholds.ProbablyValid:
+		status(model.ValidationStatusCatchAll, StatusRuleCatchAll,
+			fmt.Sprintf("domain accepts any recipient and score %d is at least %d", response.Score, thresholds.ProbablyValid))
+	case response.Validations.IsCatchAll:
+		status(model.ValidationStatusRisky, StatusRuleCatchAllLowScore,
+			fmt.Sprintf("domain accepts any recipient and score %d is below %d", response.Score, thresholds.ProbablyValid))
+	case response.Score >= thresholds.Valid:
+		status(model.ValidationStatusValid, StatusRuleValidThreshold,
+			fmt.Sprintf("score %d is at least the valid threshold %d", response.Score, thresholds.Valid))
+	case response.Score >= thresholds.ProbablyValid:
+		status(model.ValidationStatusProbablyValid, StatusRuleProbablyValidThreshold,
+			fmt.Sprintf("score %d is at least the probably valid threshold %d", response.Score, thresholds.ProbablyValid))
+	default:
+		status(model.ValidationStatusInvalid, StatusRuleBelowThresholds,
+			fmt.Sprintf("score %d is below the probably valid threshold %d", response.Score, thresholds.ProbablyValid))
+	}
+
+	if settings.explain {
+		response.Explanation = explanation
+	}
+}
+
+// setEarlyStatus sets the status of a response that failed before scoring, with the rule that set it
+func setEarlyStatus(settings validationSettings, response *model.EmailValidationResponse, status model.ValidationStatus, rule string) {
+	response.Status = status
+	if settings.explain {
+		response.Explanation = &model.ScoreExplanation{
+			Profile:       settings.profileName,
+			Contributions: []model.ScoreContribution{},
+			StatusRule:    model.StatusRule{Rule: rule, Message: StatusReason(status)},
+		}
+	}
+}
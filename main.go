@@ -9,13 +9,14 @@ import (
 	"time"
 
 	"emailvalidator/internal/api"
+	"emailvalidator/internal/config"
 	"emailvalidator/internal/service"
 	"emailvalidator/pkg/monitoring"
 )
 
 func main() {
 	// Create service instances
-	emailService, err := service.NewEmailService()
+	emailService, err := service.NewEmailServiceWithConfig(config.Load())
 	if err != nil {
 		log.Fatalf("Failed to initialize email service: %v", err)
 	}
@@ -43,8 +44,11 @@ func main() {
 	apiMux := http.NewServeMux()
 	apiMux.HandleFunc("/validate", handler.HandleValidate)
 	apiMux.HandleFunc("/validate/batch", handler.HandleBatchValidate)
+	apiMux.HandleFunc("/validate/upload", handler.HandleUpload)
 	apiMux.HandleFunc("/typo-suggestions", handler.HandleTypoSuggestions)
 	apiMux.HandleFunc("/status", handler.HandleStatus)
+	apiMux.HandleFunc("/jobs", handler.HandleJobs)
+	apiMux.HandleFunc("/jobs/", handler.HandleJob)
 
 	// Wrap API routes with monitoring
 	monitoredHandler := monitoring.MetricsMiddleware(apiMux)
@@ -39,8 +39,9 @@ func NewMockCache() *MockCache {
 }
 
 func (m *MockCache) Get(ctx context.Context, key string, dest interface{}) error {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	// Expired keys are deleted here, so this needs the write lock
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	data, exists := m.data[key]
 	if !exists {
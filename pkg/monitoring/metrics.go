@@ -58,6 +58,43 @@ var (
 		[]string{"lookup_type"},
 	)
 
+	// DNSLookupsCoalesced tracks lookups that joined one already in flight for the same domain and type
+	DNSLookupsCoalesced = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "email_validator_dns_lookups_coalesced_total",
+			Help: "Total number of DNS lookups answered by a concurrent lookup of the same domain",
+		},
+		[]string{"lookup_type"},
+	)
+
+	// SMTPProbeDuration tracks SMTP mailbox probe times by outcome
+	SMTPProbeDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "email_validator_smtp_probe_duration_seconds",
+			Help:    "SMTP mailbox probe duration in seconds",
+			Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 20},
+		},
+		[]string{"result"},
+	)
+
+	// SMTPRetries tracks background retries of temporarily rejected SMTP probes by outcome
+	SMTPRetries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "email_validator_smtp_retries_total",
+			Help: "Total number of SMTP probe retries after temporary failures",
+		},
+		[]string{"result"},
+	)
+
+	// BatchJobs tracks asynchronous batch validation jobs by lifecycle event
+	BatchJobs = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "email_validator_batch_jobs_total",
+			Help: "Total number of asynchronous batch validation jobs by event",
+		},
+		[]string{"event"},
+	)
+
 	// ActiveGoroutines tracks the number of active goroutines
 	ActiveGoroutines = promauto.NewGauge(
 		prometheus.GaugeOpts{
@@ -113,6 +150,26 @@ func RecordDNSLookup(lookupType string, duration time.Duration) {
 	DNSLookupDuration.WithLabelValues(lookupType).Observe(duration.Seconds())
 }
 
+// RecordCoalescedLookup records a DNS lookup that shared the result of a concurrent one
+func RecordCoalescedLookup(lookupType string) {
+	DNSLookupsCoalesced.WithLabelValues(lookupType).Inc()
+}
+
+// RecordSMTPProbe records the duration and outcome of an SMTP mailbox probe
+func RecordSMTPProbe(result string, duration time.Duration) {
+	SMTPProbeDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+// RecordSMTPRetry records the outcome of a background SMTP probe retry
+func RecordSMTPRetry(result string) {
+	SMTPRetries.WithLabelValues(result).Inc()
+}
+
+// RecordBatchJob records a lifecycle event of an asynchronous batch validation job
+func RecordBatchJob(event string) {
+	BatchJobs.WithLabelValues(event).Inc()
+}
+
 // UpdateGoroutineCount updates the active goroutine count
 func UpdateGoroutineCount(count float64) {
 	ActiveGoroutines.Set(count)
@@ -1,10 +1,5 @@
 package validator
 
-import (
-	"os"
-	"path/filepath"
-)
-
 // DisposableValidator handles disposable email validation
 type DisposableValidator struct {
 	disposableDomains map[string]struct{}
@@ -12,25 +7,12 @@ type DisposableValidator struct {
 
 // NewDisposableValidator creates a new instance of DisposableValidator using the config file
 func NewDisposableValidator() (*DisposableValidator, error) {
-	// Get the project root directory
-	projectRoot, err := os.Getwd()
+	path, err := configFilePath("disposable_domains.txt")
 	if err != nil {
 		return nil, err
 	}
 
-	// Keep going up until we find the config directory or hit the root
-	for {
-		if _, err := os.Stat(filepath.Join(projectRoot, "config")); err == nil {
-			break
-		}
-		parent := filepath.Dir(projectRoot)
-		if parent == projectRoot {
-			return nil, err
-		}
-		projectRoot = parent
-	}
-
-	reader := NewFileDomainReader(filepath.Join(projectRoot, "config", "disposable_domains.txt"))
+	reader := NewFileDomainReader(path)
 	return NewDisposableValidatorWithReader(reader)
 }
 
@@ -1,67 +1,50 @@
 package validator
 
 import (
+	"context"
 	"net"
 	"time"
 )
 
-// DNSResolver interface for making DNS lookups configurable and mockable
+// DNSResolver interface for making DNS lookups configurable and mockable.
+// Lookups stop when ctx is cancelled or its deadline passes. Along with the records they report how long
+// the answer may be cached: the lowest TTL of the answer records or, when the domain or the records are
+// missing, the negative caching TTL from the zone's SOA record (RFC 2308)
 type DNSResolver interface {
-	LookupHost(domain string) ([]string, error)
-	LookupMX(domain string) ([]*net.MX, error)
+	LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error)
+	LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error)
 }
 
-// DefaultResolver implements DNSResolver using net package
+// UnknownTTL is reported by a DNSResolver when the response didn't say how long it may be cached
+const UnknownTTL time.Duration = -1
+
+// DefaultResolver implements DNSResolver using the system resolver, which doesn't report TTLs
 type DefaultResolver struct {
 	timeout time.Duration
 }
 
 // LookupHost performs a DNS lookup for the given domain and returns a list of IP addresses.
-// It uses the system's default DNS resolver with the configured timeout.
-func (r *DefaultResolver) LookupHost(domain string) ([]string, error) {
-	resultChan := make(chan []string, 1)
-	errChan := make(chan error, 1)
-
-	go func() {
-		addrs, err := net.LookupHost(domain)
-		if err != nil {
-			errChan <- err
-			return
-		}
-		resultChan <- addrs
-	}()
-
-	select {
-	case addrs := <-resultChan:
-		return addrs, nil
-	case err := <-errChan:
-		return nil, err
-	case <-time.After(r.timeout):
-		return nil, net.ErrClosed
-	}
+// It uses the system's default DNS resolver, bounded by ctx and the configured timeout.
+func (r *DefaultResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	addresses, err := net.DefaultResolver.LookupHost(ctx, domain)
+	return addresses, UnknownTTL, err
 }
 
 // LookupMX performs a DNS lookup for MX records of the given domain.
 // It returns a list of mail servers responsible for handling email for the domain.
-func (r *DefaultResolver) LookupMX(domain string) ([]*net.MX, error) {
-	resultChan := make(chan []*net.MX, 1)
-	errChan := make(chan error, 1)
-
-	go func() {
-		mxs, err := net.LookupMX(domain)
-		if err != nil {
-			errChan <- err
-			return
-		}
-		resultChan <- mxs
-	}()
+func (r *DefaultResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	records, err := net.DefaultResolver.LookupMX(ctx, domain)
+	return records, UnknownTTL, err
+}
 
-	select {
-	case mxs := <-resultChan:
-		return mxs, nil
-	case err := <-errChan:
-		return nil, err
-	case <-time.After(r.timeout):
-		return nil, net.ErrClosed
+// withTimeout bounds ctx by the configured timeout, if there is one
+func (r *DefaultResolver) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.timeout <= 0 {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeout(ctx, r.timeout)
 }
@@ -1,73 +1,355 @@
 package validator
 
 import (
+	"context"
+	"errors"
+	"log"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"emailvalidator/pkg/cache"
+	"emailvalidator/pkg/monitoring"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// remoteCacheTimeout bounds every call to the shared cache so a slow backend can't stall lookups
+	remoteCacheTimeout = 100 * time.Millisecond
+	// remoteCacheRetryAfter is how long the shared cache is bypassed after it returns an error
+	remoteCacheRetryAfter = 30 * time.Second
+	// remoteCacheKeyPrefix namespaces domain lookup results in the shared cache.
+	// v2 entries carry the failure reason next to the result, v3 MX entries the full MX answer
+	remoteCacheKeyPrefix = "emailvalidator:domain:v3:"
+)
+
+// Kinds of cached domain results, also used in shared cache keys
+const (
+	cacheKindHost     = "host"
+	cacheKindMX       = "mx"
+	cacheKindCatchAll = "catch_all"
 )
 
+// CacheTTLConfig bounds how long domain lookup results are cached. Results are kept for the TTL of
+// their DNS answer clamped to these bounds. When the resolver doesn't report a TTL the cache duration
+// is used, capped by the maximum. A zero bound doesn't clamp
+type CacheTTLConfig struct {
+	// MinTTL and MaxTTL clamp how long domains and MX records that were found are kept
+	MinTTL time.Duration
+	MaxTTL time.Duration
+	// NegativeMinTTL and NegativeMaxTTL clamp how long missing domains and records are kept. Their TTL
+	// is the negative caching TTL from the zone's SOA record (RFC 2308)
+	NegativeMinTTL time.Duration
+	NegativeMaxTTL time.Duration
+}
+
+// DefaultCacheTTLConfig returns the default bounds of cached domain lookup results
+func DefaultCacheTTLConfig() CacheTTLConfig {
+	return CacheTTLConfig{
+		MinTTL:         time.Minute,
+		MaxTTL:         time.Hour,
+		NegativeMinTTL: 30 * time.Second,
+		NegativeMaxTTL: 15 * time.Minute,
+	}
+}
+
+// MXRecord is a mail exchanger of a cached MX answer
+type MXRecord struct {
+	Host string `json:"host"`
+	Pref uint16 `json:"pref"`
+}
+
+// MXAnswer is the outcome of an MX lookup as kept in the domain cache
+type MXAnswer struct {
+	// Records are the mail exchangers sorted by preference, empty for a null MX
+	Records []MXRecord `json:"records,omitempty"`
+	// NullMX is set when the domain refuses mail with a null MX record (RFC 7505)
+	NullMX bool `json:"nullMX,omitempty"`
+	// Reason is the reason code explaining why the domain has no usable MX records
+	Reason string `json:"reason,omitempty"`
+}
+
+// HasMX reports whether the domain has MX records accepting mail
+func (a MXAnswer) HasMX() bool {
+	return len(a.Records) > 0 && !a.NullMX && a.Reason == ""
+}
+
 // domainCache represents a cached domain lookup result
 type domainCache struct {
-	exists    bool
-	timestamp time.Time
+	value bool
+	// reason is the reason code explaining a failed lookup
+	reason  string
+	expires time.Time
+	// mx is the full answer of an MX lookup; entries stored with SetMX only carry value and reason
+	mx *MXAnswer
+}
+
+// remoteDomainCache is the representation of a domain lookup result in the shared cache
+type remoteDomainCache struct {
+	Value  bool   `json:"value"`
+	Reason string `json:"reason,omitempty"`
+	// Expires is the Unix time the result expires at, so replicas promoting it keep its TTL
+	Expires int64     `json:"expires,omitempty"`
+	MX      *MXAnswer `json:"mx,omitempty"`
 }
 
-// DomainCacheManager handles caching of domain validation results
+// DomainCacheManager handles caching of domain validation results.
+// Results are kept in process and, when a shared cache is attached, in that cache too,
+// so replicas can reuse each other's lookups. The shared cache is optional: its errors are
+// logged and the manager keeps working from the in-process tier alone.
 type DomainCacheManager struct {
-	cache         map[string]domainCache
+	caches        map[string]map[string]domainCache
 	cacheMutex    sync.RWMutex
 	cacheDuration time.Duration
+	ttl           CacheTTLConfig
+
+	remote          cache.Cache
+	remoteDownUntil atomic.Int64
 }
 
-// NewDomainCacheManager creates a new instance of DomainCacheManager
+// NewDomainCacheManager creates a new instance of DomainCacheManager with the default TTL bounds.
+// duration is how long results are kept when the resolver doesn't report a TTL; 0 disables caching
 func NewDomainCacheManager(duration time.Duration) *DomainCacheManager {
 	return &DomainCacheManager{
-		cache:         make(map[string]domainCache, 100), // Pre-allocate space for better performance
+		caches: map[string]map[string]domainCache{
+			cacheKindHost:     make(map[string]domainCache, 100), // Pre-allocate space for better performance
+			cacheKindMX:       make(map[string]domainCache, 100),
+			cacheKindCatchAll: make(map[string]domainCache),
+		},
 		cacheDuration: duration,
+		ttl:           DefaultCacheTTLConfig(),
 	}
 }
 
+// SetTTLConfig updates the bounds of how long results are cached.
+// Results already cached keep their expiry
+func (m *DomainCacheManager) SetTTLConfig(config CacheTTLConfig) {
+	m.cacheMutex.Lock()
+	m.ttl = config
+	m.cacheMutex.Unlock()
+}
+
+// SetRemoteCache attaches a shared cache used as the second tier behind the in-process cache
+func (m *DomainCacheManager) SetRemoteCache(remote cache.Cache) {
+	m.cacheMutex.Lock()
+	m.remote = remote
+	m.cacheMutex.Unlock()
+}
+
 // Get retrieves a cached domain validation result
 func (m *DomainCacheManager) Get(domain string) (bool, bool) {
+	entry, found := m.get(cacheKindHost, domain)
+	return entry.value, found
+}
+
+// GetWithReason retrieves a cached domain validation result and the reason code of a failure
+func (m *DomainCacheManager) GetWithReason(domain string) (bool, string, bool) {
+	entry, found := m.get(cacheKindHost, domain)
+	return entry.value, entry.reason, found
+}
+
+// Set stores a domain validation result in the cache
+func (m *DomainCacheManager) Set(domain string, exists bool) {
+	m.SetWithTTL(domain, exists, "", UnknownTTL)
+}
+
+// SetWithReason stores a domain validation result and the reason code of a failure in the cache
+func (m *DomainCacheManager) SetWithReason(domain string, exists bool, reason string) {
+	m.SetWithTTL(domain, exists, reason, UnknownTTL)
+}
+
+// SetWithTTL stores a domain validation result and the reason code of a failure for the TTL
+// of the DNS answer, clamped to the configured bounds. UnknownTTL uses the cache duration
+func (m *DomainCacheManager) SetWithTTL(domain string, exists bool, reason string, ttl time.Duration) {
+	m.set(cacheKindHost, domain, domainCache{value: exists, reason: reason}, m.lifetime(exists, ttl))
+}
+
+// GetMX retrieves a cached result of whether the domain has usable MX records
+func (m *DomainCacheManager) GetMX(domain string) (bool, bool) {
+	entry, found := m.get(cacheKindMX, domain)
+	return entry.value, found
+}
+
+// GetMXWithReason retrieves a cached MX result and the reason code of a failure
+func (m *DomainCacheManager) GetMXWithReason(domain string) (bool, string, bool) {
+	entry, found := m.get(cacheKindMX, domain)
+	return entry.value, entry.reason, found
+}
+
+// GetMXAnswer retrieves the cached MX answer of the domain, with its mail exchangers.
+// Results stored with SetMX or SetMXWithReason hold no answer and aren't found
+func (m *DomainCacheManager) GetMXAnswer(domain string) (MXAnswer, bool) {
+	entry, found := m.get(cacheKindMX, domain)
+	if !found || entry.mx == nil {
+		return MXAnswer{}, false
+	}
+	return *entry.mx, true
+}
+
+// SetMX stores whether the domain has usable MX records
+func (m *DomainCacheManager) SetMX(domain string, hasMX bool) {
+	m.SetMXWithReason(domain, hasMX, "")
+}
+
+// SetMXWithReason stores whether the domain has usable MX records and the reason code if it hasn't
+func (m *DomainCacheManager) SetMXWithReason(domain string, hasMX bool, reason string) {
+	m.set(cacheKindMX, domain, domainCache{value: hasMX, reason: reason}, m.lifetime(hasMX, UnknownTTL))
+}
+
+// SetMXAnswer stores the MX answer of the domain for the TTL of the DNS answer,
+// clamped to the configured bounds. UnknownTTL uses the cache duration
+func (m *DomainCacheManager) SetMXAnswer(domain string, answer MXAnswer, ttl time.Duration) {
+	hasMX := answer.HasMX()
+	entry := domainCache{value: hasMX, reason: answer.Reason, mx: &answer}
+	m.set(cacheKindMX, domain, entry, m.lifetime(hasMX, ttl))
+}
+
+// GetCatchAll retrieves a cached catch-all result for the domain
+func (m *DomainCacheManager) GetCatchAll(domain string) (bool, bool) {
+	entry, found := m.get(cacheKindCatchAll, domain)
+	return entry.value, found
+}
+
+// SetCatchAll stores whether the domain's mail exchanger accepts any recipient.
+// Either answer is a positive result of the probe, so both are kept within the positive bounds
+func (m *DomainCacheManager) SetCatchAll(domain string, catchAll bool) {
+	m.set(cacheKindCatchAll, domain, domainCache{value: catchAll}, m.lifetime(true, UnknownTTL))
+}
+
+// lifetime returns how long a result is kept: its TTL clamped to the positive or negative bounds,
+// or the cache duration capped by the maximum when the TTL is unknown.
+// It is 0, keeping nothing, while the cache duration is 0
+func (m *DomainCacheManager) lifetime(positive bool, ttl time.Duration) time.Duration {
 	m.cacheMutex.RLock()
-	cache, ok := m.cache[domain]
-	if !ok {
-		m.cacheMutex.RUnlock()
-		return false, false
+	duration := m.cacheDuration
+	bounds := m.ttl
+	m.cacheMutex.RUnlock()
+
+	if duration <= 0 {
+		return 0
+	}
+	minTTL, maxTTL := bounds.MinTTL, bounds.MaxTTL
+	if !positive {
+		minTTL, maxTTL = bounds.NegativeMinTTL, bounds.NegativeMaxTTL
+	}
+	if ttl < 0 {
+		ttl, minTTL = duration, 0
+	}
+	if maxTTL > 0 && ttl > maxTTL {
+		ttl = maxTTL
+	}
+	return max(ttl, minTTL)
+}
+
+// get looks up an unexpired entry in process first and then in the shared cache
+func (m *DomainCacheManager) get(kind, domain string) (domainCache, bool) {
+	m.cacheMutex.RLock()
+	entry, ok := m.caches[kind][domain]
+	remote := m.remote
+	m.cacheMutex.RUnlock()
+
+	now := time.Now()
+	if ok && now.Before(entry.expires) {
+		return entry, true
+	}
+
+	if remote == nil || !m.remoteAvailable() {
+		return domainCache{}, false
+	}
+
+	var value remoteDomainCache
+	ctx, cancel := context.WithTimeout(context.Background(), remoteCacheTimeout)
+	defer cancel()
+	if err := remote.Get(ctx, remoteCacheKey(kind, domain), &value); err != nil {
+		if !errors.Is(err, redis.Nil) {
+			m.remoteFailed("get", err)
+		}
+		monitoring.RecordCacheMiss("redis")
+		return domainCache{}, false
+	}
+	monitoring.RecordCacheHit("redis")
+
+	// Promote the shared result into the in-process tier until it expires in the shared cache.
+	// Results stored without an expiry are kept as long as a fresh lookup would be
+	expires := time.Unix(value.Expires, 0)
+	if value.Expires == 0 {
+		positive := value.Value || kind == cacheKindCatchAll
+		expires = now.Add(m.lifetime(positive, UnknownTTL))
 	}
+	if !now.Before(expires) {
+		return domainCache{}, false
+	}
+	entry = domainCache{value: value.Value, reason: value.Reason, expires: expires, mx: value.MX}
+	m.setLocal(kind, domain, entry)
+	return entry, true
+}
 
-	// Check expiration without allocating time.Time
-	if time.Since(cache.timestamp) > m.cacheDuration {
-		m.cacheMutex.RUnlock()
-		return false, false
+// set stores an entry in process and in the shared cache for the given lifetime
+func (m *DomainCacheManager) set(kind, domain string, entry domainCache, lifetime time.Duration) {
+	if lifetime <= 0 {
+		return
 	}
+	entry.expires = time.Now().Add(lifetime)
+	m.setLocal(kind, domain, entry)
 
+	m.cacheMutex.RLock()
+	remote := m.remote
 	m.cacheMutex.RUnlock()
-	return cache.exists, true
+
+	if remote == nil || !m.remoteAvailable() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteCacheTimeout)
+	defer cancel()
+	value := remoteDomainCache{Value: entry.value, Reason: entry.reason, Expires: entry.expires.Unix(), MX: entry.mx}
+	if err := remote.Set(ctx, remoteCacheKey(kind, domain), value, lifetime); err != nil {
+		m.remoteFailed("set", err)
+	}
 }
 
-// Set stores a domain validation result in the cache
-func (m *DomainCacheManager) Set(domain string, exists bool) {
+// setLocal stores an entry in the in-process tier only
+func (m *DomainCacheManager) setLocal(kind, domain string, entry domainCache) {
 	m.cacheMutex.Lock()
-	m.cache[domain] = domainCache{
-		exists:    exists,
-		timestamp: time.Now(),
-	}
+	m.caches[kind][domain] = entry
 	m.cacheMutex.Unlock()
 }
 
+// remoteAvailable reports whether the shared cache is currently being used
+func (m *DomainCacheManager) remoteAvailable() bool {
+	return time.Now().UnixNano() >= m.remoteDownUntil.Load()
+}
+
+// remoteFailed records a shared cache error and bypasses the shared cache for a while
+func (m *DomainCacheManager) remoteFailed(operation string, err error) {
+	monitoring.RecordCacheOperation("redis_"+operation, "error")
+	if m.remoteDownUntil.Swap(time.Now().Add(remoteCacheRetryAfter).UnixNano()) < time.Now().UnixNano() {
+		log.Printf("Warning: shared domain cache unavailable, using in-process cache only: %v", err)
+	}
+}
+
+// remoteCacheKey builds the shared cache key for a domain result
+func remoteCacheKey(kind, domain string) string {
+	return remoteCacheKeyPrefix + kind + ":" + domain
+}
+
 // ClearExpired removes expired entries from the cache
 func (m *DomainCacheManager) ClearExpired() {
 	m.cacheMutex.Lock()
 	now := time.Now()
-	for domain, cache := range m.cache {
-		if now.Sub(cache.timestamp) > m.cacheDuration {
-			delete(m.cache, domain)
+	for _, entries := range m.caches {
+		for domain, entry := range entries {
+			if !now.Before(entry.expires) {
+				delete(entries, domain)
+			}
 		}
 	}
 	m.cacheMutex.Unlock()
 }
 
-// SetDuration updates the cache duration
+// SetDuration updates how long results are kept when the resolver doesn't report a TTL.
+// 0 disables caching
 func (m *DomainCacheManager) SetDuration(duration time.Duration) {
 	m.cacheMutex.Lock()
 	m.cacheDuration = duration
@@ -2,11 +2,49 @@ package validator
 
 import (
 	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
-// DomainReader defines the interface for reading disposable domains
+// configDirEnv names the environment variable that points at the config directory
+const configDirEnv = "EMAIL_VALIDATOR_CONFIG_DIR"
+
+// configFilePath locates a file in the project's config directory, searching upwards
+// from the working directory so it also works when running tests from subdirectories.
+// EMAIL_VALIDATOR_CONFIG_DIR overrides the search.
+func configFilePath(name string) (string, error) {
+	// An explicit directory wins, so binaries can run outside the project tree
+	if dir := os.Getenv(configDirEnv); dir != "" {
+		return filepath.Join(dir, name), nil
+	}
+
+	// Get the project root directory
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	// Keep going up until we find the config directory or hit the root
+	for {
+		if _, err := os.Stat(filepath.Join(projectRoot, "config")); err == nil {
+			break
+		}
+		parent := filepath.Dir(projectRoot)
+		if parent == projectRoot {
+			return "", fmt.Errorf("config directory not found: %w", os.ErrNotExist)
+		}
+		projectRoot = parent
+	}
+
+	return filepath.Join(projectRoot, "config", name), nil
+}
+
+// DomainReader defines the interface for reading domain lists
 type DomainReader interface {
 	ReadDomains() ([]string, error)
 }
@@ -71,3 +109,50 @@ func NewStaticDomainReader(domains []string) *StaticDomainReader {
 func (r *StaticDomainReader) ReadDomains() ([]string, error) {
 	return r.domains, nil
 }
+
+// ProviderCSVReader implements DomainReader interface for the non-disposable domains of a
+// provider CSV file with domainName and isDisposable columns
+type ProviderCSVReader struct {
+	filePath string
+}
+
+// NewProviderCSVReader creates a new ProviderCSVReader instance
+func NewProviderCSVReader(filePath string) *ProviderCSVReader {
+	return &ProviderCSVReader{
+		filePath: filePath,
+	}
+}
+
+// ReadDomains reads the domains the file doesn't mark as disposable, skipping the header
+func (r *ProviderCSVReader) ReadDomains() ([]string, error) {
+	file, err := os.Open(r.filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	var domains []string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return domains, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		domain := strings.ToLower(strings.TrimSpace(record[0]))
+		if domain == "" || domain == "domainname" {
+			continue
+		}
+		if len(record) > 1 && strings.TrimSpace(record[1]) == "1" {
+			continue
+		}
+		domains = append(domains, domain)
+	}
+}
@@ -1,15 +1,29 @@
 package validator
 
 import (
+	"context"
+	"errors"
+	"net"
+	"sort"
 	"time"
 
 	"emailvalidator/pkg/monitoring"
+
+	"golang.org/x/sync/singleflight"
 )
 
-// DomainValidator handles domain existence validation
+// DomainValidator handles domain existence validation.
+// Concurrent lookups of the same domain and record type share a single resolution
 type DomainValidator struct {
 	resolver     DNSResolver
 	cacheManager *DomainCacheManager
+	lookups      singleflight.Group
+}
+
+// hostLookup is the result of a host lookup shared by coalesced callers
+type hostLookup struct {
+	exists bool
+	reason string
 }
 
 // NewDomainValidator creates a new instance of DomainValidator
@@ -21,51 +35,182 @@ func NewDomainValidator(resolver DNSResolver, cacheManager *DomainCacheManager)
 }
 
 // Validate checks if the domain exists
-func (v *DomainValidator) Validate(domain string) bool {
+func (v *DomainValidator) Validate(ctx context.Context, domain string) bool {
+	exists, _ := v.Check(ctx, domain)
+	return exists
+}
+
+// Check checks if the domain exists and returns the reason code if it doesn't.
+// Lookups cut short by ctx report DNS_TIMEOUT and, like other transient failures, aren't cached
+func (v *DomainValidator) Check(ctx context.Context, domain string) (bool, string) {
+	// Internationalized domains are looked up and cached by their ASCII form
+	domain = lookupDomain(domain)
+
 	// Check cache first
-	if exists, found := v.cacheManager.Get(domain); found {
+	if exists, reason, found := v.cacheManager.GetWithReason(domain); found {
 		monitoring.RecordCacheOperation("domain_lookup", "hit")
-		return exists
+		return exists, reason
 	}
 	monitoring.RecordCacheOperation("domain_lookup", "miss")
 
 	// Perform lookup
-	start := time.Now()
-	_, err := v.resolver.LookupHost(domain)
-	monitoring.RecordDNSLookup("host", time.Since(start))
-	exists := err == nil
+	result, ok := v.coalesce(ctx, "host", domain, func(ctx context.Context) interface{} {
+		start := time.Now()
+		_, ttl, err := v.resolver.LookupHost(ctx, domain)
+		monitoring.RecordDNSLookup("host", time.Since(start))
+		result := hostLookup{exists: err == nil}
+		if err != nil {
+			result.reason = dnsErrorReason(err)
+		}
+		if ctx.Err() != nil || !definiteAnswer(err) {
+			return result
+		}
 
-	// Update cache
-	v.cacheManager.Set(domain, exists)
+		// Update cache
+		v.cacheManager.SetWithTTL(domain, result.exists, result.reason, ttl)
 
-	// Periodically clean up expired cache entries
-	go v.cacheManager.ClearExpired()
+		// Periodically clean up expired cache entries
+		go v.cacheManager.ClearExpired()
 
-	return exists
+		return result
+	})
+	if !ok {
+		return false, dnsErrorReason(ctx.Err())
+	}
+	lookup := result.(hostLookup)
+	return lookup.exists, lookup.reason
 }
 
 // ValidateMX checks if the domain has valid MX records
-func (v *DomainValidator) ValidateMX(domain string) bool {
-	start := time.Now()
-	mxRecords, err := v.resolver.LookupMX(domain)
-	monitoring.RecordDNSLookup("mx", time.Since(start))
+func (v *DomainValidator) ValidateMX(ctx context.Context, domain string) bool {
+	hasMX, _ := v.CheckMX(ctx, domain)
+	return hasMX
+}
+
+// CheckMX checks if the domain has valid MX records and returns the reason code if it hasn't.
+// Lookups cut short by ctx report DNS_TIMEOUT and aren't cached
+func (v *DomainValidator) CheckMX(ctx context.Context, domain string) (bool, string) {
+	answer := v.ResolveMX(ctx, domain)
+	return answer.HasMX(), answer.Reason
+}
+
+// ResolveMX returns the domain's MX answer: its mail exchangers by preference, whether it publishes
+// a null MX and the reason code if it has no usable MX records. Answers are cached for their TTL;
+// lookups cut short by ctx report DNS_TIMEOUT and, like other transient failures, aren't cached
+func (v *DomainValidator) ResolveMX(ctx context.Context, domain string) MXAnswer {
+	// Internationalized domains are looked up and cached by their ASCII form
+	domain = lookupDomain(domain)
+
+	// Check cache first
+	if answer, found := v.cacheManager.GetMXAnswer(domain); found {
+		monitoring.RecordCacheOperation("mx_lookup", "hit")
+		return answer
+	}
+	monitoring.RecordCacheOperation("mx_lookup", "miss")
 
+	result, ok := v.coalesce(ctx, "mx", domain, func(ctx context.Context) interface{} {
+		start := time.Now()
+		mxRecords, ttl, err := v.resolver.LookupMX(ctx, domain)
+		monitoring.RecordDNSLookup("mx", time.Since(start))
+		answer := newMXAnswer(mxRecords, err)
+		if ctx.Err() != nil || !definiteAnswer(err) {
+			return answer
+		}
+
+		// Update cache
+		v.cacheManager.SetMXAnswer(domain, answer, ttl)
+
+		return answer
+	})
+	if !ok {
+		return MXAnswer{Reason: dnsErrorReason(ctx.Err())}
+	}
+	return result.(MXAnswer)
+}
+
+// coalesce runs lookup once for all concurrent callers asking for the same record type of the domain,
+// and returns its result with true. The shared lookup isn't cancelled when a caller goes away, but it
+// ends at the deadline of the caller that started it, who then gets false; callers that joined it and
+// still have time left then look up again. Once ctx is done callers stop waiting and get false
+func (v *DomainValidator) coalesce(
+	ctx context.Context, lookupType, domain string, lookup func(context.Context) interface{},
+) (interface{}, bool) {
+	for {
+		if ctx.Err() != nil {
+			return nil, false
+		}
+
+		started := false
+		results := v.lookups.DoChan(lookupType+":"+domain, func() (interface{}, error) {
+			started = true
+			shared, cancel := detachedContext(ctx)
+			defer cancel()
+			return lookup(shared), shared.Err()
+		})
+
+		select {
+		case result := <-results:
+			// started is only set by the caller whose lookup ran; the others joined it
+			if started {
+				if result.Err != nil {
+					// The lookup ended at the deadline of ctx, whose own timer may not have fired yet.
+					// Wait for it so callers see ctx done along with the timeout
+					<-ctx.Done()
+					return nil, false
+				}
+				return result.Val, true
+			}
+			monitoring.RecordCoalescedLookup(lookupType)
+			if result.Err == nil {
+				return result.Val, true
+			}
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+// definiteAnswer reports whether a lookup got an answer worth caching: records, or NXDOMAIN and NODATA
+// that say they are missing. Timeouts, SERVFAIL and other failures may pass with the next lookup
+func definiteAnswer(err error) bool {
+	var dnsErr *net.DNSError
+	return err == nil || errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// detachedContext returns a context that isn't cancelled with ctx but keeps its deadline
+func detachedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
+
+// newMXAnswer builds the MX answer of a lookup, with the reason code if none of the records accept mail
+func newMXAnswer(mxRecords []*net.MX, err error) MXAnswer {
 	// If there's an error in lookup, the domain doesn't have valid MX records
 	if err != nil {
-		return false
+		return MXAnswer{Reason: dnsErrorReason(err)}
 	}
 
 	// No MX records means the domain doesn't accept email
 	if len(mxRecords) == 0 {
-		return false
+		return MXAnswer{Reason: ReasonNoMXRecords}
 	}
 
 	// Check for null MX record (RFC 7505)
 	// A single MX record with "." as the host indicates the domain doesn't accept email
 	if len(mxRecords) == 1 && mxRecords[0].Host == "." {
-		return false
+		return MXAnswer{NullMX: true, Reason: ReasonNullMX}
 	}
 
 	// Otherwise, the domain has valid MX records
-	return true
+	records := make([]MXRecord, len(mxRecords))
+	for i, mx := range mxRecords {
+		records[i] = MXRecord{Host: mx.Host, Pref: mx.Pref}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Pref < records[j].Pref
+	})
+	return MXAnswer{Records: records}
 }
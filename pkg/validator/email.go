@@ -1,17 +1,27 @@
 package validator
 
 import (
+	"context"
 	"strings"
 	"time"
+
+	"emailvalidator/pkg/cache"
 )
 
 // EmailValidator provides methods for validating email addresses
 type EmailValidator struct {
-	syntaxValidator     *SyntaxValidator
-	domainValidator     *DomainValidator
-	roleValidator       *RoleValidator
-	disposableValidator *DisposableValidator
-	aliasDetector       *AliasDetector
+	syntaxValidator       *SyntaxValidator
+	domainValidator       *DomainValidator
+	roleValidator         *RoleValidator
+	disposableValidator   *DisposableValidator
+	freeProviderValidator *FreeProviderValidator
+	aliasDetector         *AliasDetector
+	typoSuggester         *TypoSuggester
+	tldValidator          *TLDValidator
+	tldCorrector          *TLDCorrector
+	reservedValidator     *ReservedDomainValidator
+	spoofDetector         *SpoofDetector
+	smtpVerifier          *SMTPVerifier
 }
 
 // NewEmailValidator creates a new instance of EmailValidator
@@ -24,12 +34,38 @@ func NewEmailValidator() (*EmailValidator, error) {
 		return nil, err
 	}
 
+	freeProviderValidator, err := NewFreeProviderValidator()
+	if err != nil {
+		return nil, err
+	}
+
+	typoSuggester, err := NewTypoSuggester()
+	if err != nil {
+		return nil, err
+	}
+
+	tldValidator, err := NewTLDValidator()
+	if err != nil {
+		return nil, err
+	}
+
+	spoofDetector, err := NewSpoofDetector(typoSuggester.Domains())
+	if err != nil {
+		return nil, err
+	}
+
 	return &EmailValidator{
-		syntaxValidator:     NewSyntaxValidator(),
-		domainValidator:     NewDomainValidator(resolver, cacheManager),
-		roleValidator:       NewRoleValidator(),
-		disposableValidator: disposableValidator,
-		aliasDetector:       NewAliasDetector(),
+		syntaxValidator:       NewSyntaxValidatorWithTLDs(tldValidator),
+		domainValidator:       NewDomainValidator(resolver, cacheManager),
+		roleValidator:         NewRoleValidator(),
+		disposableValidator:   disposableValidator,
+		freeProviderValidator: freeProviderValidator,
+		aliasDetector:         NewAliasDetector(),
+		typoSuggester:         typoSuggester,
+		tldValidator:          tldValidator,
+		tldCorrector:          NewTLDCorrector(tldValidator),
+		reservedValidator:     NewReservedDomainValidator(),
+		spoofDetector:         spoofDetector,
 	}, nil
 }
 
@@ -42,25 +78,69 @@ func NewEmailValidatorWithResolver(resolver DNSResolver) (*EmailValidator, error
 		return nil, err
 	}
 
+	freeProviderValidator, err := NewFreeProviderValidator()
+	if err != nil {
+		return nil, err
+	}
+
+	typoSuggester, err := NewTypoSuggester()
+	if err != nil {
+		return nil, err
+	}
+
+	tldValidator, err := NewTLDValidator()
+	if err != nil {
+		return nil, err
+	}
+
+	spoofDetector, err := NewSpoofDetector(typoSuggester.Domains())
+	if err != nil {
+		return nil, err
+	}
+
 	return &EmailValidator{
-		syntaxValidator:     NewSyntaxValidator(),
-		domainValidator:     NewDomainValidator(resolver, cacheManager),
-		roleValidator:       NewRoleValidator(),
-		disposableValidator: disposableValidator,
-		aliasDetector:       NewAliasDetector(),
+		syntaxValidator:       NewSyntaxValidatorWithTLDs(tldValidator),
+		domainValidator:       NewDomainValidator(resolver, cacheManager),
+		roleValidator:         NewRoleValidator(),
+		disposableValidator:   disposableValidator,
+		freeProviderValidator: freeProviderValidator,
+		aliasDetector:         NewAliasDetector(),
+		typoSuggester:         typoSuggester,
+		tldValidator:          tldValidator,
+		tldCorrector:          NewTLDCorrector(tldValidator),
+		reservedValidator:     NewReservedDomainValidator(),
+		spoofDetector:         spoofDetector,
 	}, nil
 }
 
 // SetResolver allows changing the DNS resolver
 func (v *EmailValidator) SetResolver(resolver DNSResolver) {
 	v.domainValidator = NewDomainValidator(resolver, v.domainValidator.cacheManager)
+	if v.smtpVerifier != nil {
+		v.smtpVerifier = NewSMTPVerifier(resolver, v.domainValidator.cacheManager, v.smtpVerifier.config)
+	}
+}
+
+// SetRemoteCache attaches a shared cache, such as Redis, behind the in-process domain cache
+func (v *EmailValidator) SetRemoteCache(remote cache.Cache) {
+	v.domainValidator.cacheManager.SetRemoteCache(remote)
+}
+
+// EnableSMTPVerification turns on SMTP mailbox probing with the given settings
+func (v *EmailValidator) EnableSMTPVerification(config SMTPConfig) {
+	v.smtpVerifier = NewSMTPVerifier(v.domainValidator.resolver, v.domainValidator.cacheManager, config)
 }
 
-// SetCacheDuration sets how long domain lookup results are cached
+// SetCacheDuration sets how long domain lookup results are cached when the resolver doesn't report a TTL
 func (v *EmailValidator) SetCacheDuration(duration time.Duration) {
 	v.domainValidator.cacheManager.SetDuration(duration)
 }
 
+// SetCacheTTLConfig sets the bounds the TTLs of cached domain lookup results are clamped to
+func (v *EmailValidator) SetCacheTTLConfig(config CacheTTLConfig) {
+	v.domainValidator.cacheManager.SetTTLConfig(config)
+}
+
 // ValidateSyntax checks if the email address format is valid
 func (v *EmailValidator) ValidateSyntax(email string) bool {
 	// Check maximum length (RFC 5321)
@@ -83,14 +163,38 @@ func (v *EmailValidator) ValidateSyntax(email string) bool {
 	return v.syntaxValidator.Validate(email)
 }
 
-// ValidateDomain checks if the domain exists
-func (v *EmailValidator) ValidateDomain(domain string) bool {
-	return v.domainValidator.Validate(domain)
+// SyntaxReason returns the reason code of the first syntax rule the email breaks, or "" if it is valid
+func (v *EmailValidator) SyntaxReason(email string) string {
+	return v.syntaxValidator.Diagnose(email)
+}
+
+// ValidateDomain checks if the domain exists, with a lookup that stops when ctx is done
+func (v *EmailValidator) ValidateDomain(ctx context.Context, domain string) bool {
+	return v.domainValidator.Validate(ctx, domain)
+}
+
+// CheckDomain checks if the domain exists and returns the reason code if it doesn't
+func (v *EmailValidator) CheckDomain(ctx context.Context, domain string) (bool, string) {
+	return v.domainValidator.Check(ctx, domain)
+}
+
+// ValidateMXRecords checks if the domain has valid MX records, with a lookup that stops when ctx is done
+func (v *EmailValidator) ValidateMXRecords(ctx context.Context, domain string) bool {
+	return v.domainValidator.ValidateMX(ctx, domain)
 }
 
-// ValidateMXRecords checks if the domain has valid MX records
-func (v *EmailValidator) ValidateMXRecords(domain string) bool {
-	return v.domainValidator.ValidateMX(domain)
+// CheckMXRecords checks if the domain has valid MX records and returns the reason code if it hasn't
+func (v *EmailValidator) CheckMXRecords(ctx context.Context, domain string) (bool, string) {
+	return v.domainValidator.CheckMX(ctx, domain)
+}
+
+// VerifyMailbox probes the domain's mail exchanger to check whether the mailbox exists.
+// The probe stops when ctx is done
+func (v *EmailValidator) VerifyMailbox(ctx context.Context, email string) SMTPResult {
+	if v.smtpVerifier == nil {
+		return SMTPResult{Err: ErrSMTPVerificationDisabled}
+	}
+	return v.smtpVerifier.Verify(ctx, email)
 }
 
 // IsDisposable checks if the email domain is from a disposable email provider
@@ -98,86 +202,90 @@ func (v *EmailValidator) IsDisposable(domain string) bool {
 	return v.disposableValidator.Validate(domain)
 }
 
+// IsReservedDomain checks if the domain is reserved or special-use, such as example.com or a .test domain
+func (v *EmailValidator) IsReservedDomain(domain string) bool {
+	return v.reservedValidator.Validate(domain)
+}
+
+// AllowReservedDomains stops treating the given reserved domains and their subdomains as reserved
+func (v *EmailValidator) AllowReservedDomains(domains ...string) {
+	v.reservedValidator.Allow(domains...)
+}
+
+// IsSpoofSuspect checks if the domain imitates a popular provider with lookalike characters
+// or mixes scripts within a label
+func (v *EmailValidator) IsSpoofSuspect(domain string) bool {
+	_, suspect := v.spoofDetector.Check(domain)
+	return suspect
+}
+
+// IsFreeProvider checks if the email domain belongs to a free email provider
+func (v *EmailValidator) IsFreeProvider(domain string) bool {
+	return v.freeProviderValidator.Validate(domain)
+}
+
 // IsRoleBased checks if the email address is role-based
 func (v *EmailValidator) IsRoleBased(email string) bool {
 	return v.roleValidator.Validate(email)
 }
 
-// CalculateScore calculates a score based on validation results
+// CalculateScore calculates a score based on validation results with the weights of the default scoring profile
 func (v *EmailValidator) CalculateScore(validations map[string]bool) int {
-	score := 0
-	weights := map[string]int{
-		"syntax":         20,
-		"domain_exists":  20,
-		"mx_records":     20,
-		"mailbox_exists": 20,
-		"is_disposable":  10,
-		"is_role_based":  10,
-	}
-
-	for check, weight := range weights {
-		passed, exists := validations[check]
-		if !exists {
-			continue
-		}
-
-		switch check {
-		case "is_disposable", "is_role_based":
-			// For negative checks, add points when false
-			if !passed {
-				score += weight
-			}
-		default:
-			// For positive checks, add points when true
-			if passed {
-				score += weight
-			}
-		}
-	}
-
-	return score
-}
-
-// GetTypoSuggestions returns possible corrections for common email typos
-func (v *EmailValidator) GetTypoSuggestions(email string) []string {
-	// Common domain corrections
-	commonDomains := map[string]string{
-		"gmial.com":  "gmail.com",
-		"gmal.com":   "gmail.com",
-		"gamil.com":  "gmail.com",
-		"gmai.com":   "gmail.com",
-		"gmail.co":   "gmail.com",
-		"gmail.cm":   "gmail.com",
-		"gmail.om":   "gmail.com",
-		"gmail.con":  "gmail.com",
-		"yaho.com":   "yahoo.com",
-		"yahooo.com": "yahoo.com",
-		"yahoo.co":   "yahoo.com",
-		"yahoo.cm":   "yahoo.com",
-		"hotmai.com": "hotmail.com",
-		"hotmal.com": "hotmail.com",
-		"hotmail.co": "hotmail.com",
-		"hotmail.cm": "hotmail.com",
-		"otmail.com": "hotmail.com",
-		"outlook.co": "outlook.com",
-		"outlook.cm": "outlook.com",
-		"outlok.com": "outlook.com",
+	return DefaultScoringProfile().Score(validations)
+}
+
+// GetTypoSuggestions returns possible corrections of a mistyped email domain, best first.
+// MX lookups stop when ctx is done
+func (v *EmailValidator) GetTypoSuggestions(ctx context.Context, email string) []string {
+	var suggestions []string
+	for _, suggestion := range v.RankTypoSuggestions(ctx, email) {
+		suggestions = append(suggestions, suggestion.Email)
 	}
+	return suggestions
+}
 
-	parts := strings.Split(email, "@")
-	if len(parts) != 2 {
+// RankTypoSuggestions returns possible corrections of a mistyped email domain with their confidence, best first.
+// Lookalikes of popular provider domains are corrected first, then near misses of them. Otherwise the TLD
+// is corrected, which needs MX lookups that stop when ctx is done. Known providers are left alone
+func (v *EmailValidator) RankTypoSuggestions(ctx context.Context, email string) []TypoSuggestion {
+	return v.rankTypoSuggestions(email, func(domain string) bool {
+		hasMX, _ := v.domainValidator.CheckMX(ctx, domain)
+		return hasMX
+	})
+}
+
+// RankTypoSuggestionsOffline ranks like RankTypoSuggestions without any DNS lookups, so TLDs are left uncorrected
+func (v *EmailValidator) RankTypoSuggestionsOffline(email string) []TypoSuggestion {
+	return v.rankTypoSuggestions(email, func(string) bool { return false })
+}
+
+// rankTypoSuggestions ranks the corrections of the email's domain, asking hasMX whether domains receive mail
+func (v *EmailValidator) rankTypoSuggestions(email string, hasMX func(domain string) bool) []TypoSuggestion {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
 		return nil
 	}
+	localPart, domain := email[:at], email[at+1:]
 
-	localPart, domain := parts[0], parts[1]
-	var suggestions []string
+	// A domain imitating a popular provider with lookalike characters can only mean that provider
+	if target, _ := v.spoofDetector.Check(domain); target != "" {
+		return []TypoSuggestion{{Email: localPart + "@" + target, Domain: target, Confidence: 1}}
+	}
 
-	// Check for common domain typos
-	if correctedDomain, exists := commonDomains[domain]; exists {
-		suggestions = append(suggestions, localPart+"@"+correctedDomain)
+	// Known providers are spelt right by definition. Receiving mail proves nothing, as typosquatters
+	// set up mail servers for the domains they register
+	if v.typoSuggester.IsProvider(domain) {
+		return nil
+	}
+	if suggestions := v.typoSuggester.Suggest(email); len(suggestions) > 0 {
+		return suggestions
 	}
+	return v.tldCorrector.Suggest(email, hasMX)
+}
 
-	return suggestions
+// SetTypoKeyboardWeighted sets whether typo suggestions treat neighbouring keys as likelier slips
+func (v *EmailValidator) SetTypoKeyboardWeighted(weighted bool) {
+	v.typoSuggester.SetKeyboardWeighted(weighted)
 }
 
 // DetectAlias checks if the email is an alias and returns the canonical email if it is
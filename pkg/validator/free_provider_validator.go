@@ -0,0 +1,45 @@
+package validator
+
+import "strings"
+
+// FreeProviderValidator handles free email provider detection, such as gmail.com or yahoo.com
+type FreeProviderValidator struct {
+	freeDomains map[string]struct{}
+}
+
+// NewFreeProviderValidator creates a new instance of FreeProviderValidator using the config file
+func NewFreeProviderValidator() (*FreeProviderValidator, error) {
+	path, err := configFilePath("free_email_providers.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	reader := NewFileDomainReader(path)
+	return NewFreeProviderValidatorWithReader(reader)
+}
+
+// NewFreeProviderValidatorWithDomains creates a new instance of FreeProviderValidator with a custom list of domains
+func NewFreeProviderValidatorWithDomains(domains []string) *FreeProviderValidator {
+	freeDomains := make(map[string]struct{}, len(domains))
+	for _, domain := range domains {
+		freeDomains[strings.ToLower(domain)] = struct{}{}
+	}
+	return &FreeProviderValidator{
+		freeDomains: freeDomains,
+	}
+}
+
+// NewFreeProviderValidatorWithReader creates a new instance of FreeProviderValidator using a DomainReader
+func NewFreeProviderValidatorWithReader(reader DomainReader) (*FreeProviderValidator, error) {
+	domains, err := reader.ReadDomains()
+	if err != nil {
+		return nil, err
+	}
+	return NewFreeProviderValidatorWithDomains(domains), nil
+}
+
+// Validate checks if the email domain belongs to a free email provider
+func (v *FreeProviderValidator) Validate(domain string) bool {
+	_, exists := v.freeDomains[strings.ToLower(domain)]
+	return exists
+}
@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// idnaProfile converts domains with IDNA2008 and the non-transitional UTS #46 mapping used by
+// browsers and mail servers, so ß and ς are kept rather than mapped to ss and σ
+var idnaProfile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.VerifyDNSLength(true),
+	idna.Transitional(false),
+)
+
+// ToASCIIDomain converts a domain to the ASCII form used in DNS, with internationalized labels
+// as xn-- A-labels. ASCII domains are only lowercased
+func ToASCIIDomain(domain string) (string, error) {
+	return idnaProfile.ToASCII(strings.TrimSuffix(domain, "."))
+}
+
+// ToUnicodeDomain converts a domain to the form shown to people, with xn-- labels decoded
+func ToUnicodeDomain(domain string) (string, error) {
+	return idnaProfile.ToUnicode(strings.TrimSuffix(domain, "."))
+}
+
+// lookupDomain returns the ASCII form of the domain for DNS lookups, or the domain itself
+// if it can't be converted so the lookup fails as it would have before
+func lookupDomain(domain string) string {
+	if ascii, err := ToASCIIDomain(domain); err == nil {
+		return ascii
+	}
+	return domain
+}
+
+// EmailForms returns the email with its domain in Unicode and in ASCII form. The local part is
+// kept as is in both, as it has no ASCII encoding. ok is false if the domain can't be converted
+func EmailForms(email string) (unicodeForm, asciiForm string, ok bool) {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return "", "", false
+	}
+	localPart, domain := email[:at], email[at+1:]
+
+	asciiDomain, err := ToASCIIDomain(domain)
+	if err != nil {
+		return "", "", false
+	}
+	unicodeDomain, err := ToUnicodeDomain(asciiDomain)
+	if err != nil {
+		return "", "", false
+	}
+	return localPart + "@" + unicodeDomain, localPart + "@" + asciiDomain, true
+}
+
+// RequiresSMTPUTF8 reports whether mail to the address needs the SMTPUTF8 extension (RFC 6531),
+// which is when its local part isn't ASCII. A Unicode domain alone can be sent in its ASCII form
+func RequiresSMTPUTF8(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	for i := 0; i < at; i++ {
+		if email[i] >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// validLocalPart reports whether the local part is a dot-atom of RFC 5322 atext extended with
+// the UTF-8 characters of RFC 6531. Dot placement is checked separately
+func validLocalPart(localPart string) bool {
+	for _, r := range localPart {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("!#$%&'*+-/=?^_`{|}~.", r):
+		case r >= utf8.RuneSelf && r != utf8.RuneError && unicode.IsPrint(r):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// Reason codes reported by the syntax check. They are part of the API and must not change.
+const (
+	ReasonEmptyEmail       = "EMPTY_EMAIL"
+	ReasonEmailTooLong     = "EMAIL_TOO_LONG"
+	ReasonQuotedString     = "QUOTED_STRING"
+	ReasonMissingAtSign    = "MISSING_AT_SIGN"
+	ReasonMultipleAtSigns  = "MULTIPLE_AT_SIGNS"
+	ReasonEmptyLocalPart   = "EMPTY_LOCAL_PART"
+	ReasonLocalPartTooLong = "LOCAL_PART_TOO_LONG"
+	ReasonLocalPartDot     = "LOCAL_PART_DOT_POSITION"
+	ReasonConsecutiveDots  = "CONSECUTIVE_DOTS"
+	ReasonEmptyDomain      = "EMPTY_DOMAIN"
+	ReasonDomainTooLong    = "DOMAIN_TOO_LONG"
+	ReasonBadDomainLabel   = "BAD_DOMAIN_LABEL"
+	ReasonUnknownTLD       = "UNKNOWN_TLD"
+	ReasonInvalidSyntax    = "INVALID_SYNTAX"
+)
+
+// Reason codes reported by the DNS checks. They are part of the API and must not change.
+const (
+	ReasonNXDomain    = "NXDOMAIN"
+	ReasonDNSTimeout  = "DNS_TIMEOUT"
+	ReasonServFail    = "SERVFAIL"
+	ReasonDNSError    = "DNS_ERROR"
+	ReasonNoMXRecords = "NO_MX_RECORDS"
+	ReasonNullMX      = "NULL_MX"
+)
+
+// Reason codes reported by the other checks. They are part of the API and must not change.
+const (
+	ReasonReservedDomain    = "RESERVED_DOMAIN"
+	ReasonDisposableDomain  = "DISPOSABLE_DOMAIN"
+	ReasonRoleBased         = "ROLE_BASED"
+	ReasonMailboxNotFound   = "MAILBOX_NOT_FOUND"
+	ReasonCatchAll          = "CATCH_ALL"
+	ReasonSMTPTemporaryFail = "SMTP_TEMPORARY_FAILURE"
+	ReasonSMTPTimeout       = "SMTP_TIMEOUT"
+	ReasonPossibleTypo      = "POSSIBLE_TYPO"
+	ReasonSpoofSuspect      = "SPOOF_SUSPECT"
+)
+
+// reasonMessages describes each reason code for people
+var reasonMessages = map[string]string{
+	ReasonEmptyEmail:        "email address is empty",
+	ReasonEmailTooLong:      "email address is longer than 254 characters",
+	ReasonQuotedString:      "quoted local parts are not accepted",
+	ReasonMissingAtSign:     "email address has no @",
+	ReasonMultipleAtSigns:   "email address has more than one @",
+	ReasonEmptyLocalPart:    "nothing before the @",
+	ReasonLocalPartTooLong:  "part before the @ is longer than 64 characters",
+	ReasonLocalPartDot:      "part before the @ starts or ends with a dot",
+	ReasonConsecutiveDots:   "email address has consecutive dots",
+	ReasonEmptyDomain:       "nothing after the @",
+	ReasonDomainTooLong:     "domain is longer than 255 characters",
+	ReasonBadDomainLabel:    "domain has an empty, too long or malformed label",
+	ReasonUnknownTLD:        "top-level domain does not exist",
+	ReasonInvalidSyntax:     "email address syntax is invalid",
+	ReasonNXDomain:          "domain does not exist",
+	ReasonDNSTimeout:        "DNS lookup timed out",
+	ReasonServFail:          "DNS server failed to answer",
+	ReasonDNSError:          "DNS lookup failed",
+	ReasonNoMXRecords:       "domain has no MX records",
+	ReasonNullMX:            "domain publishes a null MX and accepts no email",
+	ReasonReservedDomain:    "domain is reserved for testing, documentation or local use",
+	ReasonDisposableDomain:  "domain is a disposable email provider",
+	ReasonRoleBased:         "address belongs to a role rather than a person",
+	ReasonMailboxNotFound:   "mail server rejected the mailbox",
+	ReasonCatchAll:          "domain accepts any recipient",
+	ReasonSMTPTemporaryFail: "mail server temporarily refused the check",
+	ReasonSMTPTimeout:       "mail server check did not finish in time",
+	ReasonPossibleTypo:      "address looks like a typo",
+	ReasonSpoofSuspect:      "domain imitates another domain with lookalike characters or mixes scripts",
+}
+
+// ReasonMessage returns a short human-readable description of a reason code
+func ReasonMessage(code string) string {
+	return reasonMessages[code]
+}
+
+// dnsErrorReason classifies a DNS lookup error into a reason code
+func dnsErrorReason(err error) string {
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return ReasonNXDomain
+	case errors.As(err, &dnsErr) && dnsErr.IsTimeout,
+		errors.Is(err, context.DeadlineExceeded),
+		// A lookup cancelled with its request didn't finish in time either
+		errors.Is(err, context.Canceled):
+		return ReasonDNSTimeout
+	case errors.As(err, &dnsErr) && dnsErr.IsTemporary:
+		// The resolver reports SERVFAIL and REFUSED answers as temporary errors
+		return ReasonServFail
+	default:
+		return ReasonDNSError
+	}
+}
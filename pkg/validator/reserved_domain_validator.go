@@ -0,0 +1,85 @@
+package validator
+
+import "strings"
+
+// reservedDomains are the names reserved for documentation and testing by RFC 2606, the special-use
+// names of RFC 6761, and .local (RFC 6762) and .onion (RFC 7686). Their subdomains are reserved too
+var reservedDomains = []string{
+	"example",
+	"example.com",
+	"example.net",
+	"example.org",
+	"test",
+	"invalid",
+	"localhost",
+	"local",
+	"onion",
+}
+
+// specialUseTLDs are the reserved names that are TLDs of their own. They are missing from the root zone
+// but are still well-formed, so syntax validation accepts them and leaves them to ReservedDomainValidator
+var specialUseTLDs = func() map[string]struct{} {
+	tlds := make(map[string]struct{})
+	for _, domain := range reservedDomains {
+		if !strings.Contains(domain, ".") {
+			tlds[domain] = struct{}{}
+		}
+	}
+	return tlds
+}()
+
+// ReservedDomainValidator detects reserved and special-use domains, which never receive email
+// on the public internet and so don't need DNS checks
+type ReservedDomainValidator struct {
+	domains map[string]struct{}
+}
+
+// NewReservedDomainValidator creates a new instance of ReservedDomainValidator with the domains
+// reserved by RFC 2606 and RFC 6761, .local and .onion
+func NewReservedDomainValidator() *ReservedDomainValidator {
+	return NewReservedDomainValidatorWithDomains(reservedDomains)
+}
+
+// NewReservedDomainValidatorWithDomains creates a new instance of ReservedDomainValidator with a custom list of domains
+func NewReservedDomainValidatorWithDomains(domains []string) *ReservedDomainValidator {
+	v := &ReservedDomainValidator{domains: make(map[string]struct{}, len(domains))}
+	for _, domain := range domains {
+		v.domains[normalizeReservedDomain(domain)] = struct{}{}
+	}
+	return v
+}
+
+// Allow stops treating the given domains and their subdomains as reserved, such as "test" in
+// environments whose mail servers live under .test. It must be called before validating
+func (v *ReservedDomainValidator) Allow(domains ...string) {
+	for _, domain := range domains {
+		delete(v.domains, normalizeReservedDomain(domain))
+	}
+}
+
+// Validate checks if the domain is reserved or a subdomain of a reserved domain
+func (v *ReservedDomainValidator) Validate(domain string) bool {
+	domain = normalizeReservedDomain(domain)
+	for domain != "" {
+		if _, reserved := v.domains[domain]; reserved {
+			return true
+		}
+		dot := strings.Index(domain, ".")
+		if dot < 0 {
+			break
+		}
+		domain = domain[dot+1:]
+	}
+	return false
+}
+
+// normalizeReservedDomain lowercases the domain and strips surrounding spaces and dots
+func normalizeReservedDomain(domain string) string {
+	return strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// isSpecialUseTLD reports whether the TLD is a reserved name rather than an unknown one
+func isSpecialUseTLD(tld string) bool {
+	_, ok := specialUseTLDs[strings.ToLower(tld)]
+	return ok
+}
@@ -0,0 +1,246 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultProfileName is the scoring profile used when a request doesn't pick one
+const DefaultProfileName = "default"
+
+// scoreChecks are the checks weighted by a scoring profile. Checks named is_* are
+// negative: their points are awarded when the check is false
+var scoreChecks = []string{"syntax", "domain_exists", "mx_records", "mailbox_exists", "is_disposable", "is_role_based"}
+
+// ScoringProfile holds how validation results are turned into a score and a status
+type ScoringProfile struct {
+	// Weights are the points awarded by each check, adding up to 100
+	Weights map[string]int `json:"weights"`
+	// Penalties are subtracted from the score for findings that make the address doubtful
+	Penalties ScorePenalties `json:"penalties"`
+	// NoMXScore replaces the score of domains that exist but don't accept email
+	NoMXScore int `json:"no_mx_score"`
+	// Thresholds are the lowest scores of the VALID and PROBABLY_VALID statuses.
+	// Catch-all domains scoring below ProbablyValid are RISKY
+	Thresholds ScoreThresholds `json:"thresholds"`
+}
+
+// ScorePenalties holds the points subtracted from the score for doubtful findings
+type ScorePenalties struct {
+	Typo     int `json:"typo"`
+	CatchAll int `json:"catch_all"`
+	Spoof    int `json:"spoof"`
+}
+
+// ScoreThresholds holds the lowest scores of the positive statuses
+type ScoreThresholds struct {
+	Valid         int `json:"valid"`
+	ProbablyValid int `json:"probably_valid"`
+}
+
+// DefaultScoringProfile returns the built-in scoring profile
+func DefaultScoringProfile() ScoringProfile {
+	return ScoringProfile{
+		Weights: map[string]int{
+			"syntax":         20,
+			"domain_exists":  20,
+			"mx_records":     20,
+			"mailbox_exists": 20,
+			"is_disposable":  10,
+			"is_role_based":  10,
+		},
+		Penalties:  ScorePenalties{Typo: 20, CatchAll: 15, Spoof: 30},
+		NoMXScore:  40,
+		Thresholds: ScoreThresholds{Valid: 90, ProbablyValid: 70},
+	}
+}
+
+// CheckScore holds the points a weighted check awarded out of its weight
+type CheckScore struct {
+	Check  string
+	Weight int
+	Points int
+	// Passed is set when the check awarded its points, which for negative checks means it was false
+	Passed bool
+}
+
+// ScoreChecks returns the points awarded by each weighted check in validations, in a fixed order.
+// Checks missing from validations award no points and are left out
+func (p ScoringProfile) ScoreChecks(validations map[string]bool) []CheckScore {
+	var checks []CheckScore
+	for _, check := range scoreChecks {
+		weight, weighted := p.Weights[check]
+		value, exists := validations[check]
+		if !weighted || !exists {
+			continue
+		}
+
+		// For negative checks, add points when false
+		passed := value != strings.HasPrefix(check, "is_")
+		result := CheckScore{Check: check, Weight: weight, Passed: passed}
+		if passed {
+			result.Points = weight
+		}
+		checks = append(checks, result)
+	}
+	return checks
+}
+
+// Score adds up the points awarded by the weighted checks in validations
+func (p ScoringProfile) Score(validations map[string]bool) int {
+	score := 0
+	for _, check := range p.ScoreChecks(validations) {
+		score += check.Points
+	}
+	return score
+}
+
+// validate checks that the profile yields scores between 0 and 100 with ordered thresholds
+func (p ScoringProfile) validate() error {
+	total := 0
+	for check, weight := range p.Weights {
+		if !isScoreCheck(check) {
+			return fmt.Errorf("unknown check %q in weights", check)
+		}
+		if weight < 0 {
+			return fmt.Errorf("weight of %s is negative", check)
+		}
+		total += weight
+	}
+	if total != 100 {
+		return fmt.Errorf("weights add up to %d, want 100", total)
+	}
+	if p.Penalties.Typo < 0 || p.Penalties.CatchAll < 0 || p.Penalties.Spoof < 0 {
+		return errors.New("penalties can't be negative")
+	}
+	if p.NoMXScore < 0 || p.NoMXScore > 100 {
+		return fmt.Errorf("no_mx_score %d is outside 0-100", p.NoMXScore)
+	}
+	if p.Thresholds.ProbablyValid < 0 || p.Thresholds.ProbablyValid > p.Thresholds.Valid || p.Thresholds.Valid > 100 {
+		return errors.New("thresholds must satisfy 0 <= probably_valid <= valid <= 100")
+	}
+	return nil
+}
+
+// isScoreCheck reports whether check can be weighted
+func isScoreCheck(check string) bool {
+	for _, known := range scoreChecks {
+		if check == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ScoringPolicy holds the named scoring profiles a request can pick from
+type ScoringPolicy struct {
+	profiles       map[string]ScoringProfile
+	defaultProfile string
+}
+
+// NewScoringPolicy creates a new instance of ScoringPolicy using the profiles in the config directory
+func NewScoringPolicy() (*ScoringPolicy, error) {
+	path, err := configFilePath("scoring_profiles.json")
+	if err != nil {
+		return nil, err
+	}
+	return LoadScoringPolicy(path)
+}
+
+// LoadScoringPolicy creates a new instance of ScoringPolicy using the profiles in a JSON file
+func LoadScoringPolicy(path string) (*ScoringPolicy, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	profiles, err := ParseScoringProfiles(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewScoringPolicyWithProfiles(profiles), nil
+}
+
+// NewScoringPolicyWithProfiles creates a new instance of ScoringPolicy with custom profiles.
+// The built-in default profile is added unless profiles has one named "default"
+func NewScoringPolicyWithProfiles(profiles map[string]ScoringProfile) *ScoringPolicy {
+	policy := &ScoringPolicy{
+		profiles:       make(map[string]ScoringProfile, len(profiles)+1),
+		defaultProfile: DefaultProfileName,
+	}
+	for name, profile := range profiles {
+		policy.profiles[name] = profile
+	}
+	if _, ok := policy.profiles[DefaultProfileName]; !ok {
+		policy.profiles[DefaultProfileName] = DefaultScoringProfile()
+	}
+	return policy
+}
+
+// ParseScoringProfiles reads a JSON object mapping profile names to profiles.
+// Settings a profile leaves out, including single weights, keep their value from the default profile
+func ParseScoringProfiles(r io.Reader) (map[string]ScoringProfile, error) {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid scoring profiles: %w", err)
+	}
+
+	profiles := make(map[string]ScoringProfile, len(raw))
+	for name, data := range raw {
+		if strings.TrimSpace(name) == "" {
+			return nil, errors.New("scoring profile without a name")
+		}
+
+		profile := DefaultScoringProfile()
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&profile); err != nil {
+			return nil, fmt.Errorf("profile %q: %w", name, err)
+		}
+		if err := profile.validate(); err != nil {
+			return nil, fmt.Errorf("profile %q: %w", name, err)
+		}
+		profiles[name] = profile
+	}
+	return profiles, nil
+}
+
+// SetDefaultProfile sets the profile used when a request doesn't pick one
+func (p *ScoringPolicy) SetDefaultProfile(name string) error {
+	if _, ok := p.profiles[name]; !ok {
+		return fmt.Errorf("unknown scoring profile %q, available: %s", name, strings.Join(p.Names(), ", "))
+	}
+	p.defaultProfile = name
+	return nil
+}
+
+// Profile returns the profile with the given name, or the default profile for an empty name
+func (p *ScoringPolicy) Profile(name string) (ScoringProfile, bool) {
+	if name == "" {
+		name = p.defaultProfile
+	}
+	profile, ok := p.profiles[name]
+	return profile, ok
+}
+
+// DefaultProfile returns the name of the profile used when a request doesn't pick one
+func (p *ScoringPolicy) DefaultProfile() string {
+	return p.defaultProfile
+}
+
+// Names returns the names of the profiles in alphabetical order
+func (p *ScoringPolicy) Names() []string {
+	names := make([]string, 0, len(p.profiles))
+	for name := range p.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,474 @@
+package validator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"emailvalidator/pkg/monitoring"
+)
+
+var (
+	// ErrSMTPVerificationDisabled is returned when mailbox probing has not been enabled
+	ErrSMTPVerificationDisabled = errors.New("smtp verification is disabled")
+	// ErrNoMailExchanger is returned when the domain has no usable MX host to probe
+	ErrNoMailExchanger = errors.New("no usable mail exchanger")
+	// ErrSMTPUTF8Unsupported is returned when the mailbox needs SMTPUTF8 and the mail exchanger doesn't announce it
+	ErrSMTPUTF8Unsupported = errors.New("mail exchanger does not support SMTPUTF8")
+)
+
+// SMTPConfig holds the settings used when probing mailboxes over SMTP
+type SMTPConfig struct {
+	// HeloName is the host name announced in EHLO/HELO
+	HeloName string
+	// MailFrom is the envelope sender used in MAIL FROM. Empty means the null sender (<>)
+	MailFrom string
+	// Port is the TCP port the mail exchanger is contacted on
+	Port string
+	// ConnectTimeout bounds establishing the TCP connection
+	ConnectTimeout time.Duration
+	// CommandTimeout bounds each SMTP command and its reply, including the greeting
+	CommandTimeout time.Duration
+	// MaxRetries is how many times a temporarily rejected probe is retried in the background
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry; each further retry doubles it
+	RetryBackoff time.Duration
+	// MaxTrackedRetries bounds the emails whose retries are scheduled or await pickup. Past it,
+	// temporary failures aren't retried and stay inconclusive
+	MaxTrackedRetries int
+}
+
+// DefaultSMTPConfig returns the default SMTP probing settings
+func DefaultSMTPConfig() SMTPConfig {
+	return SMTPConfig{
+		HeloName:          "localhost",
+		Port:              "25",
+		ConnectTimeout:    5 * time.Second,
+		CommandTimeout:    5 * time.Second,
+		MaxRetries:        3,
+		RetryBackoff:      time.Minute,
+		MaxTrackedRetries: 10000,
+	}
+}
+
+// retryResultRetention is how long a finished background retry result is kept for pickup
+const retryResultRetention = time.Hour
+
+// SMTPResult represents the outcome of an SMTP mailbox probe
+type SMTPResult struct {
+	// Exists reports whether the mail exchanger accepted the recipient
+	Exists bool
+	// Code is the reply code returned for RCPT TO, or the 4xx code that stopped the probe early.
+	// It is 0 if the probe did not get a reply
+	Code int
+	// Message is the reply text that goes with Code
+	Message string
+	// CatchAll reports whether the mail exchanger also accepts recipients that cannot exist,
+	// in which case Exists says nothing about the mailbox
+	CatchAll bool
+	// MXHost is the mail exchanger that was contacted
+	MXHost string
+	// Pending reports that a retry is scheduled after a temporary failure
+	Pending bool
+	// Err is set when the probe could not be completed
+	Err error
+}
+
+// Temporary reports whether the mail exchanger deferred the answer with a 4xx reply,
+// such as 421, 450, 451 or 452 from greylisting or rate limiting
+func (r SMTPResult) Temporary() bool {
+	return r.Code/100 == 4
+}
+
+// Permanent reports whether the mail exchanger definitively rejected the recipient with a 5xx reply,
+// such as 550, 551 or 553
+func (r SMTPResult) Permanent() bool {
+	return r.Err == nil && r.Code/100 == 5
+}
+
+// Inconclusive reports whether the probe failed to produce a definite answer
+func (r SMTPResult) Inconclusive() bool {
+	return r.Err != nil || r.Temporary()
+}
+
+// smtpRetry tracks the background retries of a temporarily rejected probe
+type smtpRetry struct {
+	attempts int
+	result   SMTPResult
+	done     bool
+}
+
+// SMTPVerifier checks mailbox existence by talking to the domain's mail exchanger.
+// It stops after RCPT TO and never sends DATA, so no message is delivered.
+type SMTPVerifier struct {
+	resolver     DNSResolver
+	cacheManager *DomainCacheManager
+	config       SMTPConfig
+
+	retryMutex sync.Mutex
+	retries    map[string]*smtpRetry
+}
+
+// NewSMTPVerifier creates a new instance of SMTPVerifier.
+// Catch-all results are cached per domain in cacheManager when it is not nil.
+func NewSMTPVerifier(resolver DNSResolver, cacheManager *DomainCacheManager, config SMTPConfig) *SMTPVerifier {
+	defaults := DefaultSMTPConfig()
+	if config.HeloName == "" {
+		config.HeloName = defaults.HeloName
+	}
+	if config.Port == "" {
+		config.Port = defaults.Port
+	}
+	if config.ConnectTimeout <= 0 {
+		config.ConnectTimeout = defaults.ConnectTimeout
+	}
+	if config.CommandTimeout <= 0 {
+		config.CommandTimeout = defaults.CommandTimeout
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaults.RetryBackoff
+	}
+	if config.MaxTrackedRetries <= 0 {
+		config.MaxTrackedRetries = defaults.MaxTrackedRetries
+	}
+
+	return &SMTPVerifier{
+		resolver:     resolver,
+		cacheManager: cacheManager,
+		config:       config,
+		retries:      make(map[string]*smtpRetry),
+	}
+}
+
+// Verify probes the highest-priority mail exchanger of the email's domain, stopping when ctx is done.
+// A temporary rejection schedules background retries with exponential backoff and the result is
+// marked Pending; calling Verify again returns the retry outcome once it is known.
+// A probe cut short by ctx fails with the error of ctx and isn't retried
+func (v *SMTPVerifier) Verify(ctx context.Context, email string) SMTPResult {
+	if result, found := v.retryResult(email); found {
+		return result
+	}
+
+	result := v.verifyOnce(ctx, email)
+	if err := ctx.Err(); err != nil {
+		return SMTPResult{MXHost: result.MXHost, Err: err}
+	}
+	if result.Temporary() && v.config.MaxRetries > 0 {
+		result.Pending = v.scheduleRetry(email, result)
+	}
+
+	return result
+}
+
+// retryResult returns the state of a scheduled retry for the email, if there is one.
+// A finished retry is handed out once and then forgotten.
+func (v *SMTPVerifier) retryResult(email string) (SMTPResult, bool) {
+	v.retryMutex.Lock()
+	defer v.retryMutex.Unlock()
+
+	retry, found := v.retries[email]
+	if !found {
+		return SMTPResult{}, false
+	}
+
+	if retry.done {
+		delete(v.retries, email)
+		return retry.result, true
+	}
+
+	result := retry.result
+	result.Pending = true
+	return result, true
+}
+
+// scheduleRetry registers a retry for a temporarily rejected probe unless one is already scheduled.
+// It reports whether a retry is pending, which it isn't when MaxTrackedRetries are already tracked
+func (v *SMTPVerifier) scheduleRetry(email string, result SMTPResult) bool {
+	v.retryMutex.Lock()
+	defer v.retryMutex.Unlock()
+
+	if _, found := v.retries[email]; found {
+		return true
+	}
+	if len(v.retries) >= v.config.MaxTrackedRetries {
+		return false
+	}
+
+	v.retries[email] = &smtpRetry{result: result}
+	time.AfterFunc(v.config.RetryBackoff, func() {
+		v.retry(email)
+	})
+	return true
+}
+
+// retry re-probes the email and either schedules the next attempt or records the final result
+func (v *SMTPVerifier) retry(email string) {
+	// Retries run in the background, after the request that scheduled them is over
+	result := v.verifyOnce(context.Background(), email)
+
+	v.retryMutex.Lock()
+	defer v.retryMutex.Unlock()
+
+	retry, found := v.retries[email]
+	if !found {
+		return
+	}
+
+	retry.attempts++
+	retry.result = result
+	monitoring.RecordSMTPRetry(smtpProbeOutcome(result))
+
+	if result.Temporary() && retry.attempts < v.config.MaxRetries {
+		time.AfterFunc(v.config.RetryBackoff<<retry.attempts, func() {
+			v.retry(email)
+		})
+		return
+	}
+
+	retry.done = true
+	time.AfterFunc(retryResultRetention, func() {
+		v.retryMutex.Lock()
+		if v.retries[email] == retry {
+			delete(v.retries, email)
+		}
+		v.retryMutex.Unlock()
+	})
+}
+
+// verifyOnce performs a single probe against the highest-priority mail exchanger
+func (v *SMTPVerifier) verifyOnce(ctx context.Context, email string) SMTPResult {
+	parts := strings.Split(email, "@")
+	if len(parts) != 2 {
+		return SMTPResult{Err: errors.New("invalid email address")}
+	}
+
+	// Mail exchangers are given the domain in its ASCII form
+	domain := lookupDomain(parts[1])
+	host, err := v.lookupMailExchanger(ctx, domain)
+	if err != nil {
+		return SMTPResult{Err: err}
+	}
+
+	start := time.Now()
+	result := v.probe(ctx, host, domain, parts[0]+"@"+domain)
+	monitoring.RecordSMTPProbe(smtpProbeOutcome(result), time.Since(start))
+
+	return result
+}
+
+// lookupMailExchanger returns the MX host with the lowest preference value.
+// The MX answer cached by domain validation is reused, and a fresh one is cached for it
+func (v *SMTPVerifier) lookupMailExchanger(ctx context.Context, domain string) (string, error) {
+	if v.cacheManager != nil {
+		if answer, found := v.cacheManager.GetMXAnswer(domain); found {
+			monitoring.RecordCacheOperation("mx_lookup", "hit")
+			return preferredMailExchanger(answer)
+		}
+		monitoring.RecordCacheOperation("mx_lookup", "miss")
+	}
+
+	start := time.Now()
+	mxRecords, ttl, err := v.resolver.LookupMX(ctx, domain)
+	monitoring.RecordDNSLookup("mx", time.Since(start))
+	answer := newMXAnswer(mxRecords, err)
+	if v.cacheManager != nil && ctx.Err() == nil {
+		v.cacheManager.SetMXAnswer(domain, answer, ttl)
+	}
+	if err != nil {
+		return "", err
+	}
+	return preferredMailExchanger(answer)
+}
+
+// preferredMailExchanger returns the first usable host of the MX answer
+func preferredMailExchanger(answer MXAnswer) (string, error) {
+	for _, mx := range answer.Records {
+		host := strings.TrimSuffix(mx.Host, ".")
+		if host != "" {
+			return host, nil
+		}
+	}
+
+	return "", ErrNoMailExchanger
+}
+
+// probe runs the EHLO/MAIL FROM/RCPT TO exchange against a single mail exchanger.
+// The connection is closed as soon as ctx is done, failing the command in progress
+func (v *SMTPVerifier) probe(ctx context.Context, host, domain, email string) SMTPResult {
+	result := SMTPResult{MXHost: host}
+
+	dialer := net.Dialer{Timeout: v.config.ConnectTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, v.config.Port))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	// Bound the greeting, which smtp.NewClient reads immediately
+	if err := v.extendDeadline(conn); err != nil {
+		_ = conn.Close()
+		result.Err = err
+		return result
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		setProbeError(&result, err)
+		return result
+	}
+	defer func() {
+		_ = v.extendDeadline(conn)
+		_ = client.Quit()
+		_ = client.Close()
+	}()
+
+	if err := v.extendDeadline(conn); err != nil {
+		result.Err = err
+		return result
+	}
+	if err := client.Hello(v.config.HeloName); err != nil {
+		setProbeError(&result, err)
+		return result
+	}
+
+	// A UTF-8 local part can only be given to servers announcing SMTPUTF8, which
+	// client.Mail then requests
+	if RequiresSMTPUTF8(email) {
+		if ok, _ := client.Extension("SMTPUTF8"); !ok {
+			result.Err = ErrSMTPUTF8Unsupported
+			return result
+		}
+	}
+
+	if err := v.extendDeadline(conn); err != nil {
+		result.Err = err
+		return result
+	}
+	if err := client.Mail(v.config.MailFrom); err != nil {
+		setProbeError(&result, err)
+		return result
+	}
+
+	if err := v.extendDeadline(conn); err != nil {
+		result.Err = err
+		return result
+	}
+	result.Code, result.Message, result.Err = rcpt(client, email)
+	result.Exists = result.Err == nil && result.Code/100 == 2
+
+	switch {
+	case result.Exists:
+		result.CatchAll = v.detectCatchAll(client, conn, domain)
+	case !result.Inconclusive():
+		// A domain that rejects a recipient is not a catch-all
+		v.cacheCatchAll(domain, false)
+	}
+
+	return result
+}
+
+// detectCatchAll checks whether the mail exchanger also accepts a recipient that cannot exist.
+// The probe reuses the open connection and the result is cached per domain.
+func (v *SMTPVerifier) detectCatchAll(client *smtp.Client, conn net.Conn, domain string) bool {
+	if v.cacheManager != nil {
+		if catchAll, found := v.cacheManager.GetCatchAll(domain); found {
+			monitoring.RecordCacheOperation("catch_all", "hit")
+			return catchAll
+		}
+		monitoring.RecordCacheOperation("catch_all", "miss")
+	}
+
+	localPart, err := randomLocalPart()
+	if err != nil {
+		return false
+	}
+
+	if err := v.extendDeadline(conn); err != nil {
+		return false
+	}
+	code, _, err := rcpt(client, localPart+"@"+domain)
+	if err != nil || code/100 == 4 {
+		// Leave the domain uncached so the next probe tries again
+		return false
+	}
+
+	catchAll := code/100 == 2
+	v.cacheCatchAll(domain, catchAll)
+	return catchAll
+}
+
+// cacheCatchAll stores a conclusive catch-all result for the domain
+func (v *SMTPVerifier) cacheCatchAll(domain string, catchAll bool) {
+	if v.cacheManager != nil {
+		v.cacheManager.SetCatchAll(domain, catchAll)
+	}
+}
+
+// randomLocalPart returns a local part that is vanishingly unlikely to be a real mailbox
+func randomLocalPart() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "nx-" + hex.EncodeToString(buf), nil
+}
+
+// setProbeError records an error that stopped the probe before RCPT TO.
+// A 4xx reply is kept as a temporary failure so it can be retried; anything else is a probe error.
+func setProbeError(result *SMTPResult, err error) {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code/100 == 4 {
+		result.Code, result.Message = protoErr.Code, protoErr.Msg
+		return
+	}
+	result.Err = err
+}
+
+// extendDeadline gives the next SMTP command a fresh command timeout
+func (v *SMTPVerifier) extendDeadline(conn net.Conn) error {
+	return conn.SetDeadline(time.Now().Add(v.config.CommandTimeout))
+}
+
+// rcpt issues RCPT TO and returns the reply code and text.
+// Protocol-level rejections are reported through the code rather than as an error.
+func rcpt(client *smtp.Client, email string) (int, string, error) {
+	err := client.Rcpt(email)
+	if err == nil {
+		return 250, "", nil
+	}
+
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code, protoErr.Msg, nil
+	}
+
+	return 0, "", err
+}
+
+// smtpProbeOutcome maps a probe result to a metrics label
+func smtpProbeOutcome(result SMTPResult) string {
+	switch {
+	case result.Err != nil:
+		return "error"
+	case result.CatchAll:
+		return "catch_all"
+	case result.Exists:
+		return "accepted"
+	case result.Temporary():
+		return "temporary_failure"
+	default:
+		return "rejected"
+	}
+}
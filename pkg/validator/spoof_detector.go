@@ -0,0 +1,189 @@
+package validator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SpoofDetector detects domains that imitate known domains with lookalike characters from other
+// scripts, such as gmаil.com with a Cyrillic а, using the skeletons of Unicode Technical Standard #39
+type SpoofDetector struct {
+	confusables map[rune]string
+	// targets maps the skeleton of each protected domain to the domain, most popular first on collisions
+	targets map[string]string
+}
+
+// NewSpoofDetector creates a new instance of SpoofDetector protecting the given domains,
+// using the confusables data in the config directory
+func NewSpoofDetector(domains []string) (*SpoofDetector, error) {
+	path, err := configFilePath("confusables.txt")
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	confusables, err := ParseConfusables(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewSpoofDetectorWithConfusables(domains, confusables), nil
+}
+
+// NewSpoofDetectorWithConfusables creates a new instance of SpoofDetector protecting the given domains,
+// most popular first, with custom confusables data mapping characters to their prototypes
+func NewSpoofDetectorWithConfusables(domains []string, confusables map[rune]string) *SpoofDetector {
+	d := &SpoofDetector{
+		confusables: confusables,
+		targets:     make(map[string]string, len(domains)),
+	}
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+		skeleton := d.Skeleton(domain)
+		if _, ok := d.targets[skeleton]; !ok {
+			d.targets[skeleton] = domain
+		}
+	}
+	return d
+}
+
+// ParseConfusables reads confusables data in the format of the Unicode confusables.txt file,
+// "source ; prototype ; type # comment" with code points in hex, and returns the prototype of each source
+func ParseConfusables(r io.Reader) (map[rune]string, error) {
+	confusables := make(map[rune]string)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimPrefix(scanner.Text(), "\ufeff")
+		if hash := strings.Index(text, "#"); hash >= 0 {
+			text = text[:hash]
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		fields := strings.Split(text, ";")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected source and prototype", line)
+		}
+		source, err := parseCodePoints(fields[0])
+		if err != nil || len([]rune(source)) != 1 {
+			return nil, fmt.Errorf("line %d: invalid source %q", line, strings.TrimSpace(fields[0]))
+		}
+		prototype, err := parseCodePoints(fields[1])
+		if err != nil || prototype == "" {
+			return nil, fmt.Errorf("line %d: invalid prototype %q", line, strings.TrimSpace(fields[1]))
+		}
+		confusables[[]rune(source)[0]] = prototype
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return confusables, nil
+}
+
+// parseCodePoints decodes space-separated hex code points into a string
+func parseCodePoints(field string) (string, error) {
+	var b strings.Builder
+	for _, hex := range strings.Fields(field) {
+		r, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return "", err
+		}
+		b.WriteRune(rune(r))
+	}
+	return b.String(), nil
+}
+
+// Skeleton returns the UTS #39 skeleton of s: the NFD form with every character replaced by its
+// prototype, normalized again. Two strings that look alike have the same skeleton
+func (d *SpoofDetector) Skeleton(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if prototype, ok := d.confusables[r]; ok {
+			b.WriteString(prototype)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFD.String(b.String())
+}
+
+// Check reports whether the domain is suspected of spoofing. target is the protected domain it
+// imitates, or empty when the domain is only suspect for mixing scripts within a label
+func (d *SpoofDetector) Check(domain string) (target string, suspect bool) {
+	if unicodeDomain, err := ToUnicodeDomain(domain); err == nil {
+		domain = unicodeDomain
+	}
+	domain = strings.ToLower(domain)
+
+	if target, ok := d.targets[d.Skeleton(domain)]; ok && target != domain {
+		return target, true
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if mixedScript(label) {
+			return "", true
+		}
+	}
+	return "", false
+}
+
+// allowedScriptSets are the combinations of scripts that UTS #39 allows in a single label at its
+// highly restrictive level, as they are commonly written together. Any single script is allowed too
+var allowedScriptSets = [][]string{
+	{"Latin", "Han", "Hiragana", "Katakana"},
+	{"Latin", "Han", "Bopomofo"},
+	{"Latin", "Han", "Hangul"},
+}
+
+// mixedScript reports whether the label mixes scripts in a way that isn't commonly written,
+// such as Latin with Cyrillic. Characters shared by all scripts, such as digits and hyphens, are ignored
+func mixedScript(label string) bool {
+	scripts := make(map[string]struct{})
+	for _, r := range label {
+		if script := runeScript(r); script != "" {
+			scripts[script] = struct{}{}
+		}
+	}
+	if len(scripts) <= 1 {
+		return false
+	}
+
+	for _, allowed := range allowedScriptSets {
+		covered := 0
+		for _, script := range allowed {
+			if _, ok := scripts[script]; ok {
+				covered++
+			}
+		}
+		if covered == len(scripts) {
+			return false
+		}
+	}
+	return true
+}
+
+// runeScript returns the script of r, or "" for characters of the Common and Inherited scripts
+func runeScript(r rune) string {
+	if r < 0x80 {
+		if unicode.IsLetter(r) {
+			return "Latin"
+		}
+		return ""
+	}
+	for name, table := range unicode.Scripts {
+		if name != "Common" && name != "Inherited" && unicode.Is(table, r) {
+			return name
+		}
+	}
+	return ""
+}
@@ -1,18 +1,21 @@
 package validator
 
 import (
-	"net/mail"
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // SyntaxValidator handles email syntax validation
 type SyntaxValidator struct {
 	// Regex to detect quoted strings
 	quotedStringCheck *regexp.Regexp
+	// tlds rejects domains under TLDs missing from the root zone; nil accepts any TLD
+	tlds *TLDValidator
 }
 
-// NewSyntaxValidator creates a new instance of SyntaxValidator
+// NewSyntaxValidator creates a new instance of SyntaxValidator that accepts any TLD
 func NewSyntaxValidator() *SyntaxValidator {
 	return &SyntaxValidator{
 		// Regex to detect quoted strings in local part
@@ -20,45 +23,97 @@ func NewSyntaxValidator() *SyntaxValidator {
 	}
 }
 
+// NewSyntaxValidatorWithTLDs creates a new instance of SyntaxValidator that rejects TLDs unknown to tlds
+func NewSyntaxValidatorWithTLDs(tlds *TLDValidator) *SyntaxValidator {
+	v := NewSyntaxValidator()
+	v.tlds = tlds
+	return v
+}
+
 // Validate checks if the email address format is valid
 func (v *SyntaxValidator) Validate(email string) bool {
+	return v.Diagnose(email) == ""
+}
+
+// Diagnose checks the email address format and returns the reason code of the first problem found,
+// or an empty string if the format is valid
+func (v *SyntaxValidator) Diagnose(email string) string {
 	if email == "" {
-		return false
+		return ReasonEmptyEmail
 	}
 
 	// Check maximum length (RFC 5321)
 	if len(email) > 254 {
-		return false
+		return ReasonEmailTooLong
 	}
 
 	// Check for quoted strings
 	if v.quotedStringCheck.MatchString(email) {
-		return false
+		return ReasonQuotedString
 	}
 
-	// Parse with net/mail
-	addr, err := mail.ParseAddress(email)
-	if err != nil {
-		return false
+	switch strings.Count(email, "@") {
+	case 0:
+		return ReasonMissingAtSign
+	case 1:
+	default:
+		return ReasonMultipleAtSigns
 	}
 
-	// Check for consecutive dots
-	if strings.Contains(addr.Address, "..") {
-		return false
+	// Check the shape of the local part and domain first, so the reason is as specific as possible
+	localPart, domain, _ := strings.Cut(email, "@")
+	switch {
+	case localPart == "":
+		return ReasonEmptyLocalPart
+	case domain == "":
+		return ReasonEmptyDomain
+	case len(localPart) > 64:
+		return ReasonLocalPartTooLong
+	case len(domain) > 255:
+		return ReasonDomainTooLong
+	case strings.Contains(email, ".."):
+		return ReasonConsecutiveDots
+	case strings.HasPrefix(localPart, ".") || strings.HasSuffix(localPart, "."):
+		return ReasonLocalPartDot
 	}
 
-	// Split email into local part and domain
-	parts := strings.Split(addr.Address, "@")
-	if len(parts) != 2 {
-		return false
+	// Reject invalid UTF-8 and characters outside the dot-atom of RFC 5322, extended with
+	// the UTF-8 characters RFC 6531 allows in internationalized local parts
+	if !utf8.ValidString(email) || !validLocalPart(localPart) {
+		return ReasonInvalidSyntax
 	}
 
-	localPart, domain := parts[0], parts[1]
+	// Check the domain labels and that they convert to ASCII under IDNA2008
+	if !validDomainLabels(domain) {
+		return ReasonBadDomainLabel
+	}
+	asciiDomain, err := ToASCIIDomain(domain)
+	if err != nil {
+		return ReasonBadDomainLabel
+	}
 
-	// Check local part and domain lengths
-	if len(localPart) > 64 || len(domain) > 255 {
-		return false
+	// Reject TLDs that don't exist without asking DNS. Special-use TLDs such as .test are
+	// well-formed and left to the reserved domain check
+	if tld := asciiDomain[strings.LastIndex(asciiDomain, ".")+1:]; v.tlds != nil && !v.tlds.Validate(tld) && !isSpecialUseTLD(tld) {
+		return ReasonUnknownTLD
 	}
 
+	return ""
+}
+
+// validDomainLabels reports whether every dot-separated label of the domain is 1 to 63 bytes
+// of letters, digits, marks and inner hyphens. Letters and marks of any script are allowed
+// for internationalized domains.
+func validDomainLabels(domain string) bool {
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r) {
+				return false
+			}
+		}
+	}
 	return true
 }
@@ -0,0 +1,123 @@
+package validator
+
+import (
+	"sort"
+	"strings"
+)
+
+// tldTypos maps commonly mistyped TLDs to the TLD that was meant.
+// Some of them, such as co and om, exist but are far less common than their correction
+var tldTypos = map[string]string{
+	"con":  "com",
+	"cmo":  "com",
+	"ocm":  "com",
+	"cpm":  "com",
+	"xom":  "com",
+	"vom":  "com",
+	"cim":  "com",
+	"comm": "com",
+	"coom": "com",
+	"om":   "com",
+	"co":   "com",
+	"cm":   "com",
+	"nte":  "net",
+	"ner":  "net",
+	"met":  "net",
+	"ogr":  "org",
+	"rog":  "org",
+	"prg":  "org",
+}
+
+// tldSwaps lists suffixes that are often typed for one another
+var tldSwaps = [][2]string{
+	{"co.uk", "com"},
+	{"com", "co.uk"},
+}
+
+// tldPopular are the TLDs an unknown TLD is compared against
+var tldPopular = []string{"com", "net", "org", "edu", "gov", "info", "biz", "io", "uk", "de", "fr"}
+
+// TLDCorrector suggests corrections of mistyped top-level domains
+type TLDCorrector struct {
+	tlds *TLDValidator
+}
+
+// NewTLDCorrector creates a new instance of TLDCorrector
+func NewTLDCorrector(tlds *TLDValidator) *TLDCorrector {
+	return &TLDCorrector{
+		tlds: tlds,
+	}
+}
+
+// Suggest returns corrections of the email's TLD, best first. A correction is only suggested when
+// the domain as typed has no MX records and the corrected domain has, according to hasMX
+func (c *TLDCorrector) Suggest(email string, hasMX func(domain string) bool) []TypoSuggestion {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return nil
+	}
+	localPart, domain := email[:at], strings.ToLower(email[at+1:])
+
+	candidates := c.candidates(domain)
+	if len(candidates) == 0 || hasMX(domain) {
+		return nil
+	}
+
+	var suggestions []TypoSuggestion
+	source := []rune(domain)
+	for _, candidate := range candidates {
+		if !hasMX(candidate) {
+			continue
+		}
+		target := []rune(candidate)
+		distance := editDistance(source, target, true)
+		suggestions = append(suggestions, TypoSuggestion{
+			Email:      localPart + "@" + candidate,
+			Domain:     candidate,
+			Confidence: typoConfidence(distance, len(target)),
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Confidence > suggestions[j].Confidence
+	})
+	if len(suggestions) > typoMaxSuggestions {
+		suggestions = suggestions[:typoMaxSuggestions]
+	}
+	return suggestions
+}
+
+// candidates returns the domains the TLD of domain may have been meant as, likeliest first
+func (c *TLDCorrector) candidates(domain string) []string {
+	dot := strings.LastIndex(domain, ".")
+	if dot <= 0 || dot == len(domain)-1 {
+		return nil
+	}
+	name, tld := domain[:dot], domain[dot+1:]
+
+	var candidates []string
+	seen := map[string]struct{}{domain: {}}
+	add := func(candidate string) {
+		if _, ok := seen[candidate]; !ok {
+			seen[candidate] = struct{}{}
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	if correction, ok := tldTypos[tld]; ok {
+		add(name + "." + correction)
+	}
+	for _, swap := range tldSwaps {
+		if base, ok := strings.CutSuffix(domain, "."+swap[0]); ok && base != "" {
+			add(base + "." + swap[1])
+		}
+	}
+	if !c.tlds.Validate(tld) {
+		for _, popular := range tldPopular {
+			if editDistance([]rune(tld), []rune(popular), true) <= 1 {
+				add(name + "." + popular)
+			}
+		}
+	}
+	return candidates
+}
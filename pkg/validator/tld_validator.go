@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TLDListURL is where IANA publishes the current list of root zone TLDs
+const TLDListURL = "https://data.iana.org/TLD/tlds-alpha-by-domain.txt"
+
+// TLDValidator checks top-level domains against the DNS root zone
+type TLDValidator struct {
+	tlds map[string]struct{}
+}
+
+// NewTLDValidator creates a new instance of TLDValidator using the IANA list in the config directory
+func NewTLDValidator() (*TLDValidator, error) {
+	path, err := configFilePath("tlds.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	reader := NewFileDomainReader(path)
+	return NewTLDValidatorWithReader(reader)
+}
+
+// NewTLDValidatorWithDomains creates a new instance of TLDValidator with a custom list of TLDs.
+// Internationalized TLDs are given in their ASCII xn-- form, as in the IANA list
+func NewTLDValidatorWithDomains(tlds []string) *TLDValidator {
+	known := make(map[string]struct{}, len(tlds))
+	for _, tld := range tlds {
+		known[strings.ToLower(strings.TrimPrefix(tld, "."))] = struct{}{}
+	}
+	return &TLDValidator{
+		tlds: known,
+	}
+}
+
+// NewTLDValidatorWithReader creates a new instance of TLDValidator using a DomainReader
+func NewTLDValidatorWithReader(reader DomainReader) (*TLDValidator, error) {
+	tlds, err := reader.ReadDomains()
+	if err != nil {
+		return nil, err
+	}
+	return NewTLDValidatorWithDomains(tlds), nil
+}
+
+// Validate checks if the top-level domain exists in the root zone
+func (v *TLDValidator) Validate(tld string) bool {
+	_, exists := v.tlds[strings.ToLower(strings.TrimPrefix(tld, "."))]
+	return exists
+}
+
+// TLDListPath returns the path of the bundled TLD list in the config directory
+func TLDListPath() (string, error) {
+	return configFilePath("tlds.txt")
+}
+
+// ParseTLDList reads a TLD list in the IANA format, one TLD per line with # comments,
+// and checks that every entry is a valid ASCII label so a truncated or HTML answer is rejected
+func ParseTLDList(r io.Reader) ([]string, error) {
+	var tlds []string
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		tld := strings.TrimSpace(scanner.Text())
+		if tld == "" || strings.HasPrefix(tld, "#") {
+			continue
+		}
+		if !validTLDLabel(tld) {
+			return nil, fmt.Errorf("line %d: invalid TLD %q", line, tld)
+		}
+		tlds = append(tlds, tld)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(tlds) == 0 {
+		return nil, errors.New("no TLDs found")
+	}
+	return tlds, nil
+}
+
+// validTLDLabel reports whether s is a label of 1 to 63 ASCII letters, digits and inner hyphens
+func validTLDLabel(s string) bool {
+	if len(s) > 63 || strings.HasPrefix(s, "-") || strings.HasSuffix(s, "-") {
+		return false
+	}
+	for _, r := range s {
+		if r != '-' && (r < '0' || r > '9') && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
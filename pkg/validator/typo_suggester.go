@@ -0,0 +1,325 @@
+package validator
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// typoMaxSuggestions is the number of ranked suggestions returned for a domain
+	typoMaxSuggestions = 3
+	// typoLongDomain is the length from which a domain may be two edits away from its correction
+	// rather than one, as short domains two edits apart are usually unrelated
+	typoLongDomain = 10
+	// typoShortLabel is the length below which a domain's first label is short. Most near misses of a short
+	// label are real domains of their own, such as sol.com next to aol.com
+	typoShortLabel = 5
+	// typoShortLabelConfidence is the confidence a correction towards a domain with a short label needs
+	typoShortLabelConfidence = 0.95
+	// typoNearMiss is the edit distance within which a listed provider is taken for a typo of a popular
+	// domain rather than a provider of its own, as the provider list also records common typos
+	typoNearMiss = 2
+	// typoAdjacentKeyCost is the cost of substituting a key for its neighbour on the keyboard,
+	// the most common slip of the finger
+	typoAdjacentKeyCost = 0.5
+	// typoCacheSize bounds the number of domains whose suggestions are remembered
+	typoCacheSize = 10000
+)
+
+// qwertyRows is the QWERTY layout used to find neighbouring keys
+var qwertyRows = []string{
+	"1234567890-",
+	"qwertyuiop",
+	"asdfghjkl",
+	"zxcvbnm",
+}
+
+// qwertyKeys maps each key to its row and column
+var qwertyKeys = func() map[rune][2]int {
+	keys := make(map[rune][2]int)
+	for row, keysInRow := range qwertyRows {
+		for col, key := range keysInRow {
+			keys[key] = [2]int{row, col}
+		}
+	}
+	return keys
+}()
+
+// TypoSuggestion is a possible correction of a mistyped email address
+type TypoSuggestion struct {
+	Email  string
+	Domain string
+	// Confidence ranges from 0 to 1 and is higher the fewer and likelier the edits needed
+	Confidence float64
+}
+
+// TypoSuggester suggests corrections of mistyped domains using the Damerau-Levenshtein distance
+// to popular email provider domains
+type TypoSuggester struct {
+	domains          []string
+	known            map[string]struct{}
+	providers        map[string]struct{}
+	keyboardWeighted bool
+
+	cacheMutex sync.Mutex
+	cache      map[string][]TypoSuggestion
+}
+
+// NewTypoSuggester creates a new instance of TypoSuggester using the popular providers that
+// the provider list in the config directory knows as non-disposable. The other providers listed
+// there are real domains too and aren't corrected, unless they are near misses of a popular one
+func NewTypoSuggester() (*TypoSuggester, error) {
+	popularPath, err := configFilePath("popular_email_providers.txt")
+	if err != nil {
+		return nil, err
+	}
+	popular, err := NewFileDomainReader(popularPath).ReadDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	providersPath, err := configFilePath("email_providers.csv")
+	if err != nil {
+		return nil, err
+	}
+	providers, err := NewProviderCSVReader(providersPath).ReadDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make(map[string]struct{}, len(providers))
+	for _, domain := range providers {
+		listed[domain] = struct{}{}
+	}
+	domains := make([]string, 0, len(popular))
+	for _, domain := range popular {
+		if _, ok := listed[strings.ToLower(domain)]; ok {
+			domains = append(domains, domain)
+		}
+	}
+	return NewTypoSuggesterWithProviders(domains, providers), nil
+}
+
+// NewTypoSuggesterWithDomains creates a new instance of TypoSuggester with a custom list of domains,
+// most popular first. Ties between equally likely corrections go to the more popular domain
+func NewTypoSuggesterWithDomains(domains []string) *TypoSuggester {
+	return NewTypoSuggesterWithProviders(domains, nil)
+}
+
+// NewTypoSuggesterWithProviders creates a new instance of TypoSuggester with a custom list of domains,
+// most popular first, that leaves the domains of the given providers uncorrected. Providers within
+// typoNearMiss edits of one of the domains are typos recorded as providers and still corrected
+func NewTypoSuggesterWithProviders(domains, providers []string) *TypoSuggester {
+	s := &TypoSuggester{
+		domains:          make([]string, 0, len(domains)),
+		known:            make(map[string]struct{}, len(domains)),
+		providers:        make(map[string]struct{}, len(providers)),
+		keyboardWeighted: true,
+		cache:            make(map[string][]TypoSuggestion),
+	}
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+		if _, ok := s.known[domain]; ok {
+			continue
+		}
+		s.known[domain] = struct{}{}
+		s.domains = append(s.domains, domain)
+	}
+	for _, domain := range providers {
+		domain = strings.ToLower(domain)
+		if !s.nearMiss(domain) {
+			s.providers[domain] = struct{}{}
+		}
+	}
+	return s
+}
+
+// nearMiss reports whether the domain is within typoNearMiss edits of one of the popular domains
+func (s *TypoSuggester) nearMiss(domain string) bool {
+	source := []rune(domain)
+	for _, candidate := range s.domains {
+		target := []rune(candidate)
+		if math.Abs(float64(len(target)-len(source))) > typoNearMiss {
+			continue
+		}
+		if distance := editDistance(source, target, false); distance > 0 && distance <= typoNearMiss {
+			return true
+		}
+	}
+	return false
+}
+
+// SetKeyboardWeighted sets whether substituting neighbouring keys counts as half an edit
+func (s *TypoSuggester) SetKeyboardWeighted(weighted bool) {
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+	s.keyboardWeighted = weighted
+	s.cache = make(map[string][]TypoSuggestion)
+}
+
+// Domains returns the popular domains suggestions correct towards, most popular first
+func (s *TypoSuggester) Domains() []string {
+	return append([]string(nil), s.domains...)
+}
+
+// IsPopular reports whether the domain is one of the popular domains suggestions correct towards
+func (s *TypoSuggester) IsPopular(domain string) bool {
+	_, ok := s.known[strings.ToLower(domain)]
+	return ok
+}
+
+// IsProvider reports whether the domain is a popular domain or one of the other known providers,
+// which are spelt right by definition. Near misses of popular domains aren't providers
+func (s *TypoSuggester) IsProvider(domain string) bool {
+	domain = strings.ToLower(domain)
+	if _, ok := s.known[domain]; ok {
+		return true
+	}
+	_, ok := s.providers[domain]
+	return ok
+}
+
+// Suggest returns the likely corrections of the email's domain, best first
+func (s *TypoSuggester) Suggest(email string) []TypoSuggestion {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return nil
+	}
+	localPart, domain := email[:at], strings.ToLower(email[at+1:])
+
+	domainSuggestions := s.suggestDomain(domain)
+	if len(domainSuggestions) == 0 {
+		return nil
+	}
+	suggestions := make([]TypoSuggestion, len(domainSuggestions))
+	for i, suggestion := range domainSuggestions {
+		suggestion.Email = localPart + "@" + suggestion.Domain
+		suggestions[i] = suggestion
+	}
+	return suggestions
+}
+
+// suggestDomain returns the likely corrections of a domain, remembering recent answers
+// as batches tend to repeat domains
+func (s *TypoSuggester) suggestDomain(domain string) []TypoSuggestion {
+	if s.IsProvider(domain) {
+		return nil
+	}
+
+	s.cacheMutex.Lock()
+	suggestions, found := s.cache[domain]
+	weighted := s.keyboardWeighted
+	s.cacheMutex.Unlock()
+	if found {
+		return suggestions
+	}
+
+	source := []rune(domain)
+	for _, candidate := range s.domains {
+		target := []rune(candidate)
+		maxDistance := 1.0
+		if len(target) >= typoLongDomain {
+			maxDistance = 2
+		}
+		if math.Abs(float64(len(target)-len(source))) > maxDistance {
+			continue
+		}
+
+		distance := editDistance(source, target, weighted)
+		if distance == 0 || distance > maxDistance {
+			continue
+		}
+		confidence := typoConfidence(distance, len(target))
+		if shortLabel(candidate) && confidence < typoShortLabelConfidence {
+			continue
+		}
+		suggestions = append(suggestions, TypoSuggestion{
+			Domain:     candidate,
+			Confidence: confidence,
+		})
+	}
+
+	// Candidates are in order of popularity, which the stable sort keeps for equal confidence
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Confidence > suggestions[j].Confidence
+	})
+	if len(suggestions) > typoMaxSuggestions {
+		suggestions = suggestions[:typoMaxSuggestions]
+	}
+
+	s.cacheMutex.Lock()
+	if len(s.cache) >= typoCacheSize {
+		s.cache = make(map[string][]TypoSuggestion)
+	}
+	s.cache[domain] = suggestions
+	s.cacheMutex.Unlock()
+
+	return suggestions
+}
+
+// typoConfidence turns the edit distance to a correction of the given length into a confidence
+// from 0 to 1, rounded to two decimals
+func typoConfidence(distance float64, length int) float64 {
+	return math.Round(max(0, 1-distance/float64(length))*100) / 100
+}
+
+// shortLabel reports whether the first label of the domain is shorter than typoShortLabel
+func shortLabel(domain string) bool {
+	label, _, _ := strings.Cut(domain, ".")
+	return len([]rune(label)) < typoShortLabel
+}
+
+// editDistance returns the Damerau-Levenshtein distance between a and b in its optimal string
+// alignment form, where insertions, deletions, substitutions and transpositions of adjacent
+// characters cost one edit. With keyboardWeighted, substituting a neighbouring key costs less
+func editDistance(a, b []rune, keyboardWeighted bool) float64 {
+	// Three rows are enough as transpositions look back two characters
+	prevPrev := make([]float64, len(b)+1)
+	prev := make([]float64, len(b)+1)
+	curr := make([]float64, len(b)+1)
+	for j := range prev {
+		prev[j] = float64(j)
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = float64(i)
+		for j := 1; j <= len(b); j++ {
+			substitution := 0.0
+			if a[i-1] != b[j-1] {
+				substitution = 1
+				if keyboardWeighted && adjacentKeys(a[i-1], b[j-1]) {
+					substitution = typoAdjacentKeyCost
+				}
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+substitution)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prevPrev[j-2]+1)
+			}
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+	return prev[len(b)]
+}
+
+// adjacentKeys reports whether two keys are neighbours on a QWERTY keyboard,
+// where each row is shifted half a key to the right of the one above
+func adjacentKeys(a, b rune) bool {
+	keyA, okA := qwertyKeys[a]
+	keyB, okB := qwertyKeys[b]
+	if !okA || !okB {
+		return false
+	}
+
+	switch rowDiff, colDiff := keyB[0]-keyA[0], keyB[1]-keyA[1]; rowDiff {
+	case 0:
+		return colDiff == -1 || colDiff == 1
+	case 1:
+		return colDiff == -1 || colDiff == 0
+	case -1:
+		return colDiff == 0 || colDiff == 1
+	default:
+		return false
+	}
+}
@@ -0,0 +1,554 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var (
+	// ErrNoUpstreamServers is returned when an upstream resolver is configured without servers
+	ErrNoUpstreamServers = errors.New("no upstream DNS servers configured")
+	// ErrUnknownDNSProtocol is returned when an upstream resolver is configured with an unsupported protocol
+	ErrUnknownDNSProtocol = errors.New("unknown DNS protocol")
+)
+
+// Protocols upstream DNS servers can be queried over
+const (
+	// DNSProtocolUDP queries over UDP, retrying over TCP when an answer is truncated
+	DNSProtocolUDP = "udp"
+	// DNSProtocolTLS queries over DNS-over-TLS (RFC 7858)
+	DNSProtocolTLS = "tls"
+	// DNSProtocolHTTPS queries over DNS-over-HTTPS (RFC 8484)
+	DNSProtocolHTTPS = "https"
+)
+
+// dohContentType is the media type of DNS messages sent over HTTPS
+const dohContentType = "application/dns-message"
+
+// maxUDPSize is the UDP payload size advertised through EDNS(0), small enough to avoid IP fragmentation
+const maxUDPSize = 1232
+
+// ResolverConfig holds the settings of the upstream DNS servers queried instead of the system resolver
+type ResolverConfig struct {
+	// Servers are the upstream servers, tried in order until one answers. For UDP and TLS they are
+	// host or host:port, with port 53 or 853 when none is given. For HTTPS they are URLs such as
+	// https://dns.example/dns-query; a bare host gets the /dns-query path
+	Servers []string
+	// Protocol selects how the servers are queried: DNSProtocolUDP, DNSProtocolTLS or DNSProtocolHTTPS.
+	// Empty uses UDP
+	Protocol string
+	// TLSConfig verifies TLS and HTTPS servers; nil verifies them against the system roots
+	TLSConfig *tls.Config
+	// Timeout bounds each query sent to a server
+	Timeout time.Duration
+	// Retries is how many more times a server that timed out is queried before moving on to the next one
+	Retries int
+}
+
+// DefaultResolverConfig returns the default upstream resolver settings, without servers
+func DefaultResolverConfig() ResolverConfig {
+	return ResolverConfig{
+		Protocol: DNSProtocolUDP,
+		Timeout:  2 * time.Second,
+		Retries:  1,
+	}
+}
+
+// dnsTransport sends a packed DNS query to a server and returns the packed response
+type dnsTransport interface {
+	// queryID returns the message ID of the next query
+	queryID() (uint16, error)
+	exchange(ctx context.Context, server string, query []byte) ([]byte, error)
+}
+
+// UpstreamResolver implements DNSResolver by querying the configured
+// servers directly, so lookups don't depend on the resolv.conf of the host
+type UpstreamResolver struct {
+	servers   []string
+	timeout   time.Duration
+	retries   int
+	transport dnsTransport
+}
+
+// NewUpstreamResolver creates a resolver querying the configured servers over the configured protocol.
+// It fails with ErrNoUpstreamServers or ErrUnknownDNSProtocol if the configuration can't be used
+func NewUpstreamResolver(config ResolverConfig) (*UpstreamResolver, error) {
+	if len(config.Servers) == 0 {
+		return nil, ErrNoUpstreamServers
+	}
+
+	var transport dnsTransport
+	address := func(server string) string { return withDefaultPort(server, "53") }
+	switch strings.ToLower(config.Protocol) {
+	case "", DNSProtocolUDP:
+		transport = udpTransport{}
+	case DNSProtocolTLS:
+		transport = tlsTransport{config: config.TLSConfig}
+		address = func(server string) string { return withDefaultPort(server, "853") }
+	case DNSProtocolHTTPS:
+		transport = newHTTPSTransport(config.TLSConfig)
+		address = dohURL
+	default:
+		return nil, fmt.Errorf("%w %q, available: %s, %s, %s", ErrUnknownDNSProtocol, config.Protocol,
+			DNSProtocolUDP, DNSProtocolTLS, DNSProtocolHTTPS)
+	}
+
+	servers := make([]string, len(config.Servers))
+	for i, server := range config.Servers {
+		servers[i] = address(server)
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultResolverConfig().Timeout
+	}
+
+	return &UpstreamResolver{
+		servers:   servers,
+		timeout:   timeout,
+		retries:   max(config.Retries, 0),
+		transport: transport,
+	}, nil
+}
+
+// dnsAnswer holds the records answering a query and how long the answer may be cached
+type dnsAnswer struct {
+	records []dnsmessage.Resource
+	ttl     time.Duration
+}
+
+// LookupHost resolves the IPv4 and IPv6 addresses of the domain, with queries that stop when ctx is done.
+// Addresses are kept for the lowest TTL among them; a domain without addresses for the lowest
+// negative caching TTL of the two queries
+func (r *UpstreamResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	var addresses []string
+	var lookupErr error
+	addressTTL, negativeTTL := UnknownTTL, UnknownTTL
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		answer, err := r.query(ctx, domain, qtype)
+		if err != nil {
+			var dnsErr *net.DNSError
+			// A missing domain has no records of the other type either
+			if (errors.As(err, &dnsErr) && dnsErr.IsNotFound) || ctx.Err() != nil {
+				return nil, answer.ttl, err
+			}
+			lookupErr = err
+			continue
+		}
+		found := false
+		for _, record := range answer.records {
+			switch body := record.Body.(type) {
+			case *dnsmessage.AResource:
+				addresses = append(addresses, net.IP(body.A[:]).String())
+				found = true
+			case *dnsmessage.AAAAResource:
+				addresses = append(addresses, net.IP(body.AAAA[:]).String())
+				found = true
+			}
+		}
+		if found {
+			addressTTL = lowerTTL(addressTTL, answer.ttl)
+		} else {
+			negativeTTL = lowerTTL(negativeTTL, answer.ttl)
+		}
+	}
+
+	if len(addresses) > 0 {
+		return addresses, addressTTL, nil
+	}
+	if lookupErr != nil {
+		return nil, UnknownTTL, lookupErr
+	}
+	return nil, negativeTTL, &net.DNSError{Err: "no such host", Name: domain, IsNotFound: true}
+}
+
+// LookupMX resolves the MX records of the domain, sorted by preference, with queries that stop when ctx is done.
+// A domain without MX records gives an empty list rather than an error
+func (r *UpstreamResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
+	answer, err := r.query(ctx, domain, dnsmessage.TypeMX)
+	if err != nil {
+		return nil, answer.ttl, err
+	}
+
+	var records []*net.MX
+	for _, record := range answer.records {
+		if body, ok := record.Body.(*dnsmessage.MXResource); ok {
+			records = append(records, &net.MX{Host: body.MX.String(), Pref: body.Pref})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Pref < records[j].Pref
+	})
+	return records, answer.ttl, nil
+}
+
+// query asks the servers in order for the records of the domain. A server that times out is retried
+// before moving on; a server that fails to answer is skipped. A missing domain ends the lookup,
+// with its negative caching TTL in the answer
+func (r *UpstreamResolver) query(ctx context.Context, domain string, qtype dnsmessage.Type) (dnsAnswer, error) {
+	unanswered := dnsAnswer{ttl: UnknownTTL}
+	name, err := dnsmessage.NewName(lookupDomain(strings.TrimSuffix(domain, ".")) + ".")
+	if err != nil {
+		return unanswered, &net.DNSError{Err: "invalid domain name", Name: domain}
+	}
+	question := dnsmessage.Question{Name: name, Type: qtype, Class: dnsmessage.ClassINET}
+
+	var lastErr error
+	for _, server := range r.servers {
+		for attempt := 0; attempt <= r.retries; attempt++ {
+			answer, err := r.ask(ctx, server, question)
+			if err == nil {
+				return answer, nil
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return unanswered, ctxErr
+			}
+
+			dnsErr := asDNSError(err, domain, server)
+			if dnsErr.IsNotFound {
+				return answer, dnsErr
+			}
+			lastErr = dnsErr
+			if !dnsErr.IsTimeout {
+				break
+			}
+		}
+	}
+	return unanswered, lastErr
+}
+
+// ask sends the question to a single server within the query timeout and returns the answer records
+func (r *UpstreamResolver) ask(ctx context.Context, server string, question dnsmessage.Question) (dnsAnswer, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	unanswered := dnsAnswer{ttl: UnknownTTL}
+	id, err := r.transport.queryID()
+	if err != nil {
+		return unanswered, err
+	}
+	query, err := packQuery(id, question)
+	if err != nil {
+		return unanswered, err
+	}
+	packed, err := r.transport.exchange(ctx, server, query)
+	if err != nil {
+		// A connection closed by the timeout fails with a network error
+		if ctx.Err() != nil {
+			return unanswered, ctx.Err()
+		}
+		return unanswered, err
+	}
+
+	var response dnsmessage.Message
+	if err := response.Unpack(packed); err != nil {
+		return unanswered, fmt.Errorf("invalid DNS response: %w", err)
+	}
+	if !matchesQuery(response, id, question) {
+		return unanswered, errors.New("DNS response does not match the query")
+	}
+
+	switch response.RCode {
+	case dnsmessage.RCodeSuccess:
+		return dnsAnswer{records: response.Answers, ttl: answerTTL(response, question.Type)}, nil
+	case dnsmessage.RCodeNameError:
+		return dnsAnswer{ttl: negativeTTL(response)}, &net.DNSError{Err: "no such host", IsNotFound: true}
+	default:
+		// SERVFAIL, REFUSED and the like may be answered differently by the next server
+		return unanswered, &net.DNSError{Err: "server misbehaving: " + response.RCode.String(), IsTemporary: true}
+	}
+}
+
+// randomQueryID returns a message ID from a cryptographic source, so spoofed replies can't predict it
+func randomQueryID() (uint16, error) {
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(id[:]), nil
+}
+
+// answerTTL returns how long a successful response may be cached: the lowest TTL of its answer records,
+// CNAMEs included, or the negative caching TTL when it holds no record of the asked type
+func answerTTL(response dnsmessage.Message, qtype dnsmessage.Type) time.Duration {
+	ttl, found := UnknownTTL, false
+	for _, record := range response.Answers {
+		ttl = lowerTTL(ttl, time.Duration(record.Header.TTL)*time.Second)
+		found = found || record.Header.Type == qtype
+	}
+	if !found {
+		return negativeTTL(response)
+	}
+	return ttl
+}
+
+// negativeTTL returns how long a missing domain or record may be cached: the lower of the TTL
+// of the SOA record in the authority section and its minimum field (RFC 2308 section 5).
+// It is UnknownTTL when the response carries no SOA record
+func negativeTTL(response dnsmessage.Message) time.Duration {
+	for _, record := range response.Authorities {
+		if soa, ok := record.Body.(*dnsmessage.SOAResource); ok {
+			return time.Duration(min(record.Header.TTL, soa.MinTTL)) * time.Second
+		}
+	}
+	return UnknownTTL
+}
+
+// lowerTTL returns the lower of two TTLs, either of which may be UnknownTTL
+func lowerTTL(a, b time.Duration) time.Duration {
+	if a == UnknownTTL {
+		return b
+	}
+	if b == UnknownTTL {
+		return a
+	}
+	return min(a, b)
+}
+
+// packQuery builds a recursive query for the question, advertising EDNS(0) support
+func packQuery(id uint16, question dnsmessage.Question) ([]byte, error) {
+	builder := dnsmessage.NewBuilder(make([]byte, 0, 512), dnsmessage.Header{ID: id, RecursionDesired: true})
+	builder.EnableCompression()
+	if err := builder.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := builder.Question(question); err != nil {
+		return nil, err
+	}
+	if err := builder.StartAdditionals(); err != nil {
+		return nil, err
+	}
+	var opt dnsmessage.ResourceHeader
+	if err := opt.SetEDNS0(maxUDPSize, dnsmessage.RCodeSuccess, false); err != nil {
+		return nil, err
+	}
+	if err := builder.OPTResource(opt, dnsmessage.OPTResource{}); err != nil {
+		return nil, err
+	}
+	return builder.Finish()
+}
+
+// matchesQuery reports whether the message answers the query with the given ID and question
+func matchesQuery(response dnsmessage.Message, id uint16, question dnsmessage.Question) bool {
+	if !response.Header.Response || response.Header.ID != id || len(response.Questions) != 1 {
+		return false
+	}
+	got := response.Questions[0]
+	return got.Type == question.Type && got.Class == question.Class &&
+		strings.EqualFold(got.Name.String(), question.Name.String())
+}
+
+// asDNSError converts a failed query into the net.DNSError the validation reasons are derived from
+func asDNSError(err error, domain, server string) *net.DNSError {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		copied := *dnsErr
+		copied.Name, copied.Server = domain, server
+		return &copied
+	}
+
+	var netErr net.Error
+	timeout := errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) ||
+		errors.As(err, &netErr) && netErr.Timeout()
+	return &net.DNSError{Err: err.Error(), Name: domain, Server: server, IsTimeout: timeout, IsTemporary: timeout}
+}
+
+// withDefaultPort adds the port to a server address that has none
+func withDefaultPort(server, port string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(strings.Trim(server, "[]"), port)
+}
+
+// udpTransport sends queries over UDP and repeats them over TCP when the answer is truncated
+type udpTransport struct{}
+
+func (udpTransport) queryID() (uint16, error) {
+	return randomQueryID()
+}
+
+func (udpTransport) exchange(ctx context.Context, server string, query []byte) ([]byte, error) {
+	response, err := exchangeUDP(ctx, server, query)
+	if err != nil {
+		return nil, err
+	}
+	if truncated(response) {
+		return exchangeStream(ctx, dialTCP, server, query)
+	}
+	return response, nil
+}
+
+// truncated reports whether the TC bit of a packed response is set
+func truncated(response []byte) bool {
+	return len(response) > 2 && response[2]&0x02 != 0
+}
+
+// exchangeUDP sends the query in a single datagram and waits for the datagram answering it
+func exchangeUDP(ctx context.Context, server string, query []byte) ([]byte, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer closeOnDone(ctx, conn)()
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buffer := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buffer)
+		if err != nil {
+			return nil, err
+		}
+		// Datagrams from earlier queries or spoofing attempts carry another ID and are ignored
+		if n >= 2 && binary.BigEndian.Uint16(buffer) == binary.BigEndian.Uint16(query) {
+			return buffer[:n], nil
+		}
+	}
+}
+
+// dialTCP opens a TCP connection to a DNS server
+func dialTCP(ctx context.Context, server string) (net.Conn, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", server)
+}
+
+// exchangeStream sends the query over a connection opened by dial, with the two-byte length prefix
+// of DNS over TCP (RFC 1035 section 4.2.2), and reads the answer
+func exchangeStream(
+	ctx context.Context,
+	dial func(ctx context.Context, server string) (net.Conn, error),
+	server string,
+	query []byte,
+) ([]byte, error) {
+	conn, err := dial(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	defer closeOnDone(ctx, conn)()
+
+	message := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(message, uint16(len(query)))
+	copy(message[2:], query)
+	if _, err := conn.Write(message); err != nil {
+		return nil, err
+	}
+
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	response := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// closeOnDone closes the connection when ctx is done, failing the exchange in progress, so the error
+// of ctx is always set by then. The returned function closes the connection once the exchange is over
+func closeOnDone(ctx context.Context, conn net.Conn) func() {
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	return func() {
+		stop()
+		_ = conn.Close()
+	}
+}
+
+// tlsTransport sends queries over DNS-over-TLS, opening a connection for each query
+type tlsTransport struct {
+	config *tls.Config
+}
+
+func (tlsTransport) queryID() (uint16, error) {
+	return randomQueryID()
+}
+
+func (t tlsTransport) exchange(ctx context.Context, server string, query []byte) ([]byte, error) {
+	return exchangeStream(ctx, t.dial, server, query)
+}
+
+// dial opens a TLS connection to the server, verifying its certificate for the host of the address
+func (t tlsTransport) dial(ctx context.Context, server string) (net.Conn, error) {
+	config := &tls.Config{}
+	if t.config != nil {
+		config = t.config.Clone()
+	}
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(server)
+		if err != nil {
+			return nil, err
+		}
+		config.ServerName = host
+	}
+	dialer := tls.Dialer{Config: config}
+	return dialer.DialContext(ctx, "tcp", server)
+}
+
+// httpsTransport sends queries over DNS-over-HTTPS as POST requests, keeping connections open between queries
+type httpsTransport struct {
+	client *http.Client
+}
+
+func newHTTPSTransport(config *tls.Config) httpsTransport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config != nil {
+		transport.TLSClientConfig = config.Clone()
+	}
+	return httpsTransport{client: &http.Client{Transport: transport}}
+}
+
+// queryID is always 0, as RFC 8484 asks, so identical queries make identical requests that HTTP caches can answer
+func (httpsTransport) queryID() (uint16, error) {
+	return 0, nil
+}
+
+func (t httpsTransport) exchange(ctx context.Context, server string, query []byte) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, server, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", dohContentType)
+	request.Header.Set("Accept", dohContentType)
+
+	response, err := t.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DNS-over-HTTPS server answered %s", response.Status)
+	}
+	if contentType := response.Header.Get("Content-Type"); !strings.HasPrefix(contentType, dohContentType) {
+		return nil, fmt.Errorf("DNS-over-HTTPS server answered with content type %q", contentType)
+	}
+	return io.ReadAll(io.LimitReader(response.Body, 65535))
+}
+
+// dohURL turns a DNS-over-HTTPS server into the URL queries are posted to
+func dohURL(server string) string {
+	if strings.Contains(server, "://") {
+		return server
+	}
+	return "https://" + strings.TrimSuffix(server, "/") + "/dns-query"
+}
@@ -65,7 +65,7 @@ func TestBatchNullMXRecordAcceptance(t *testing.T) {
 	emails := []string{
 		"test@gmail.com",   // Valid MX
 		"test@gmail.dk",    // Null MX
-		"test@example.com", // Valid MX
+		"test@example.com", // Reserved domain
 	}
 
 	// Execute batch test
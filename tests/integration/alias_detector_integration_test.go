@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"testing"
 
 	"emailvalidator/internal/service"
@@ -54,7 +55,7 @@ func TestEmailServiceAliasDetection(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Validate the email
-			result := svc.ValidateEmail(tt.email)
+			result := svc.ValidateEmail(context.Background(), tt.email)
 
 			// Check if AliasOf field is set correctly
 			if tt.shouldHaveAlias {
@@ -89,7 +90,7 @@ func TestBatchValidationAliasDetection(t *testing.T) {
 	}
 
 	// Validate the emails
-	result := svc.ValidateEmails(emails)
+	result := svc.ValidateEmails(context.Background(), emails)
 
 	// Verify results
 	if len(result.Results) != len(emails) {
@@ -3,9 +3,15 @@ package integration
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -41,8 +47,11 @@ func getTestServer(t *testing.T) *httptest.Server {
 		apiMux := http.NewServeMux()
 		apiMux.HandleFunc("/validate", handler.HandleValidate)
 		apiMux.HandleFunc("/validate/batch", handler.HandleBatchValidate)
+		apiMux.HandleFunc("/validate/upload", handler.HandleUpload)
 		apiMux.HandleFunc("/typo-suggestions", handler.HandleTypoSuggestions)
 		apiMux.HandleFunc("/status", handler.HandleStatus)
+		apiMux.HandleFunc("/jobs", handler.HandleJobs)
+		apiMux.HandleFunc("/jobs/", handler.HandleJob)
 
 		// Wrap API routes with monitoring
 		monitoredHandler := monitoring.MetricsMiddleware(apiMux)
@@ -66,13 +75,16 @@ func TestHandleValidate(t *testing.T) {
 	tests := []struct {
 		name       string
 		email      string
+		profile    string
+		explain    bool
+		mode       string
 		method     string
 		wantStatus int
 		wantScore  int
 	}{
 		{
 			name:       "Valid email POST",
-			email:      "user@example.com",
+			email:      "user@nonexistent123.com",
 			method:     http.MethodPost,
 			wantStatus: http.StatusOK,
 			wantScore:  40,
@@ -86,11 +98,56 @@ func TestHandleValidate(t *testing.T) {
 		},
 		{
 			name:       "Valid email GET",
-			email:      "user@example.com",
+			email:      "user@nonexistent123.com",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantScore:  40,
+		},
+		{
+			name:       "Explained email GET",
+			email:      "user@nonexistent123.com",
+			explain:    true,
 			method:     http.MethodGet,
 			wantStatus: http.StatusOK,
 			wantScore:  40,
 		},
+		{
+			name:       "Explained email POST",
+			email:      "user@nonexistent123.com",
+			explain:    true,
+			method:     http.MethodPost,
+			wantStatus: http.StatusOK,
+			wantScore:  40,
+		},
+		{
+			name:       "Reserved domain POST",
+			email:      "user@example.com",
+			method:     http.MethodPost,
+			wantStatus: http.StatusOK,
+			wantScore:  0,
+		},
+		{
+			name:       "Fast mode GET",
+			email:      "user@nonexistent123.com",
+			mode:       "fast",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantScore:  100,
+		},
+		{
+			name:       "Unknown mode POST",
+			email:      "user@nonexistent123.com",
+			mode:       "turbo",
+			method:     http.MethodPost,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Unknown scoring profile POST",
+			email:      "user@nonexistent123.com",
+			profile:    "no-such-profile",
+			method:     http.MethodPost,
+			wantStatus: http.StatusBadRequest,
+		},
 		{
 			name:       "Missing email parameter GET",
 			email:      "",
@@ -123,7 +180,7 @@ func TestHandleValidate(t *testing.T) {
 
 			switch tt.method {
 			case http.MethodPost:
-				reqBody := model.EmailValidationRequest{Email: tt.email}
+				reqBody := model.EmailValidationRequest{Email: tt.email, Profile: tt.profile, Explain: tt.explain, Mode: tt.mode}
 				jsonBody, _ := json.Marshal(reqBody)
 				req, err := http.NewRequest(http.MethodPost, server.URL+"/api/validate", bytes.NewBuffer(jsonBody))
 				if err != nil {
@@ -142,6 +199,12 @@ func TestHandleValidate(t *testing.T) {
 				if tt.email != "" {
 					q := req.URL.Query()
 					q.Add("email", tt.email)
+					if tt.explain {
+						q.Add("explain", "true")
+					}
+					if tt.mode != "" {
+						q.Add("mode", tt.mode)
+					}
 					req.URL.RawQuery = q.Encode()
 				}
 				resp, err = client.Do(req)
@@ -181,6 +244,12 @@ func TestHandleValidate(t *testing.T) {
 				if result.Score != tt.wantScore {
 					t.Errorf("got score %d, want %d", result.Score, tt.wantScore)
 				}
+				if (result.Explanation != nil) != tt.explain {
+					t.Errorf("got explanation %+v, want one: %v", result.Explanation, tt.explain)
+				}
+				if skipped := !result.Validations.Ran("domain"); skipped != (tt.mode == "fast") {
+					t.Errorf("got domain check skipped %v, want %v", skipped, tt.mode == "fast")
+				}
 			}
 		})
 	}
@@ -261,6 +330,373 @@ func TestHandleBatchValidate(t *testing.T) {
 	}
 }
 
+func TestHandleBatchValidateNDJSON(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	t.Parallel()
+	server := getTestServer(t)
+
+	emails := []string{"not-an-email", "also.not.an.email", ""}
+	jsonBody, _ := json.Marshal(model.BatchValidationRequest{Emails: emails})
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/api/validate/batch", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	client := &http.Client{
+		Timeout: 2 * time.Second,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Errorf("Failed to close response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("got Content-Type %q, want application/x-ndjson", got)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	seen := make(map[string]bool)
+	for range emails {
+		var result model.EmailValidationResponse
+		if err := decoder.Decode(&result); err != nil {
+			t.Fatalf("Failed to decode result line: %v", err)
+		}
+		seen[result.Email] = true
+	}
+	for _, email := range emails {
+		if !seen[email] {
+			t.Errorf("missing result for %q", email)
+		}
+	}
+
+	var summary model.BatchSummaryLine
+	if err := decoder.Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode summary line: %v", err)
+	}
+	if summary.Summary.Total != len(emails) {
+		t.Errorf("got summary total %d, want %d", summary.Summary.Total, len(emails))
+	}
+	if summary.Summary.StatusCounts[model.ValidationStatusInvalidFormat] != 2 || summary.Summary.StatusCounts[model.ValidationStatusMissingEmail] != 1 {
+		t.Errorf("got status counts %v", summary.Summary.StatusCounts)
+	}
+	if decoder.More() {
+		t.Error("expected the summary to be the last line")
+	}
+}
+
+func TestHandleUpload(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	t.Parallel()
+	server := getTestServer(t)
+
+	tests := []struct {
+		name        string
+		filename    string
+		content     string
+		column      string
+		wantStatus  int
+		wantLines   []string
+		wantFileOut string
+	}{
+		{
+			name:        "CSV with detected column",
+			filename:    "contacts.csv",
+			content:     "name,email\nAda,not-an-email\n",
+			wantStatus:  http.StatusOK,
+			wantLines:   []string{"name,email,status,score,reason", "Ada,not-an-email,INVALID_FORMAT,0,email address syntax is invalid"},
+			wantFileOut: "contacts-validated.csv",
+		},
+		{
+			name:        "Plain text list",
+			filename:    "list.txt",
+			content:     "not-an-email\n",
+			wantStatus:  http.StatusOK,
+			wantLines:   []string{"email,status,score,reason", "not-an-email,INVALID_FORMAT,0,email address syntax is invalid"},
+			wantFileOut: "list-validated.csv",
+		},
+		{
+			name:       "Unknown column",
+			filename:   "contacts.csv",
+			content:    "name,email\nAda,not-an-email\n",
+			column:     "work_email",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			part, err := form.CreateFormFile("file", tt.filename)
+			if err != nil {
+				t.Fatalf("Failed to create form file: %v", err)
+			}
+			if _, err := part.Write([]byte(tt.content)); err != nil {
+				t.Fatalf("Failed to write form file: %v", err)
+			}
+			if tt.column != "" {
+				if err := form.WriteField("column", tt.column); err != nil {
+					t.Fatalf("Failed to write column field: %v", err)
+				}
+			}
+			if err := form.Close(); err != nil {
+				t.Fatalf("Failed to close form: %v", err)
+			}
+
+			client := &http.Client{
+				Timeout: 2 * time.Second,
+			}
+			resp, err := client.Post(server.URL+"/api/validate/upload", form.FormDataContentType(), &body)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer func() {
+				if err := resp.Body.Close(); err != nil {
+					t.Errorf("Failed to close response body: %v", err)
+				}
+			}()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("got status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if got := resp.Header.Get("Content-Disposition"); !strings.Contains(got, tt.wantFileOut) {
+				t.Errorf("got Content-Disposition %q, want filename %s", got, tt.wantFileOut)
+			}
+			out, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Failed to read response: %v", err)
+			}
+			if got := strings.Split(strings.TrimSpace(string(out)), "\n"); !reflect.DeepEqual(got, tt.wantLines) {
+				t.Errorf("got lines %q, want %q", got, tt.wantLines)
+			}
+		})
+	}
+}
+
+// slowResolver answers every lookup after a delay, like a DNS server far away
+type slowResolver struct {
+	delay time.Duration
+}
+
+func (r slowResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	time.Sleep(r.delay)
+	return []string{"192.0.2.1"}, validator.UnknownTTL, nil
+}
+
+func (r slowResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
+	time.Sleep(r.delay)
+	return []*net.MX{{Host: "mail." + domain, Pref: 10}}, validator.UnknownTTL, nil
+}
+
+func TestHandleUploadSlowerThanWriteTimeout(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	t.Parallel()
+
+	emailValidator, err := validator.NewEmailValidatorWithResolver(slowResolver{delay: 400 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	handler := api.NewHandler(service.NewEmailServiceWithDeps(emailValidator))
+	server := httptest.NewUnstartedServer(http.HandlerFunc(handler.HandleUpload))
+	// Validating the file takes longer than the server allows for writing a response
+	server.Config.WriteTimeout = 300 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "list.txt")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	if _, err := part.Write([]byte("ada@lovelace.org\ngrace@hopper.org\n")); err != nil {
+		t.Fatalf("Failed to write form file: %v", err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatalf("Failed to close form: %v", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(server.URL, form.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response after the write timeout: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(out)), "\n"); len(lines) != 3 {
+		t.Errorf("got lines %q, want the header and 2 validated rows", lines)
+	}
+}
+
+func TestHandleUploadSlowerThanReadTimeout(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	t.Parallel()
+
+	emailValidator, err := validator.NewEmailValidatorWithResolver(slowResolver{})
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	handler := api.NewHandler(service.NewEmailServiceWithDeps(emailValidator))
+	server := httptest.NewUnstartedServer(http.HandlerFunc(handler.HandleUpload))
+	// Sending the file takes longer than the server allows for reading a request
+	server.Config.ReadTimeout = 200 * time.Millisecond
+	server.Config.WriteTimeout = 200 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "list.txt")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	if _, err := part.Write([]byte("not-an-email\nalso-not-an-email\n")); err != nil {
+		t.Fatalf("Failed to write form file: %v", err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatalf("Failed to close form: %v", err)
+	}
+
+	// Send the first half, then stall past the read timeout like a slow uplink
+	upload, writer := io.Pipe()
+	go func() {
+		data := body.Bytes()
+		_, _ = writer.Write(data[:len(data)/2])
+		time.Sleep(500 * time.Millisecond)
+		_, _ = writer.Write(data[len(data)/2:])
+		_ = writer.Close()
+	}()
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(server.URL, form.FormDataContentType(), upload)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response after the read timeout: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(out)), "\n"); len(lines) != 3 {
+		t.Errorf("got lines %q, want the header and 2 validated rows", lines)
+	}
+}
+
+func TestHandleJobs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	t.Parallel()
+	server := getTestServer(t)
+	client := &http.Client{
+		Timeout: 2 * time.Second,
+	}
+
+	getJSON := func(t *testing.T, path string, wantStatus int, dest interface{}) {
+		t.Helper()
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				t.Errorf("Failed to close response body: %v", err)
+			}
+		}()
+		if resp.StatusCode != wantStatus {
+			t.Fatalf("GET %s: got status %d, want %d", path, resp.StatusCode, wantStatus)
+		}
+		if dest != nil {
+			if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+	}
+
+	emails := []string{"not-an-email", "also.not.an.email", "missing-at.example.com"}
+	jsonBody, _ := json.Marshal(model.BatchValidationRequest{Emails: emails})
+	resp, err := client.Post(server.URL+"/api/jobs", "application/json", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	var job model.Job
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Errorf("Failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+	if got := resp.Header.Get("Location"); got != "/api/jobs/"+job.ID {
+		t.Errorf("got Location %q, want %q", got, "/api/jobs/"+job.ID)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != model.JobStatusCompleted {
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not complete in time, last status %s", job.Status)
+		}
+		time.Sleep(20 * time.Millisecond)
+		getJSON(t, "/api/jobs/"+job.ID, http.StatusOK, &job)
+	}
+	if job.Processed != len(emails) || job.StatusCounts[model.ValidationStatusInvalidFormat] != len(emails) {
+		t.Errorf("got processed %d and counts %v, want %d INVALID_FORMAT", job.Processed, job.StatusCounts, len(emails))
+	}
+
+	var page model.JobResultsResponse
+	getJSON(t, "/api/jobs/"+job.ID+"/results?offset=1&limit=1", http.StatusOK, &page)
+	if len(page.Results) != 1 || page.Results[0].Email != emails[1] {
+		t.Errorf("got results %v, want only %s", page.Results, emails[1])
+	}
+	if page.NextOffset == nil || *page.NextOffset != 2 {
+		t.Errorf("got next offset %v, want 2", page.NextOffset)
+	}
+
+	getJSON(t, "/api/jobs/"+job.ID+"/results?limit=abc", http.StatusBadRequest, nil)
+	getJSON(t, "/api/jobs/unknown", http.StatusNotFound, nil)
+	getJSON(t, "/api/jobs/unknown/results", http.StatusNotFound, nil)
+}
+
 func TestHandleTypoSuggestions(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -387,6 +823,7 @@ func TestInvalidJSON(t *testing.T) {
 		"/validate",
 		"/validate/batch",
 		"/typo-suggestions",
+		"/jobs",
 	}
 
 	for _, endpoint := range endpoints {
@@ -0,0 +1,219 @@
+// Package clitest contains unit tests for the emailvalidator command-line tool
+package clitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"emailvalidator/internal/cli"
+	"emailvalidator/internal/config"
+	"emailvalidator/internal/model"
+	"emailvalidator/internal/service"
+	"emailvalidator/pkg/validator"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cliTestResolver answers for acme.io only, so results don't depend on the network
+type cliTestResolver struct{}
+
+func (cliTestResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	if domain == "acme.io" {
+		return []string{"192.0.2.1"}, validator.UnknownTTL, nil
+	}
+	return nil, validator.UnknownTTL, errors.New("no such host")
+}
+
+func (cliTestResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
+	if domain == "acme.io" {
+		return []*net.MX{{Host: "mx.acme.io.", Pref: 10}}, validator.UnknownTTL, nil
+	}
+	return nil, validator.UnknownTTL, errors.New("no such host")
+}
+
+// runCLI runs the CLI with the given standard input and returns the exit code, stdout and stderr
+func runCLI(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	c := &cli.CLI{
+		NewService: func(config.Config) (*service.EmailService, error) {
+			emailValidator, err := validator.NewEmailValidatorWithResolver(cliTestResolver{})
+			if err != nil {
+				return nil, err
+			}
+			return service.NewEmailServiceWithDeps(emailValidator), nil
+		},
+		Stdin:  strings.NewReader(stdin),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	code := c.Run(args)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestValidateCommand(t *testing.T) {
+	code, stdout, _ := runCLI(t, "", "validate", "ada@acme.io", "--output", "json")
+	assert.Equal(t, cli.ExitOK, code)
+
+	var result model.EmailValidationResponse
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, "ada@acme.io", result.Email)
+	assert.Equal(t, model.ValidationStatusValid, result.Status)
+
+	code, stdout, _ = runCLI(t, "", "validate", "not-an-email")
+	assert.Equal(t, cli.ExitInvalid, code)
+	assert.Contains(t, stdout, "INVALID_FORMAT")
+	assert.Contains(t, stdout, "MISSING_AT_SIGN")
+
+	code, stdout, _ = runCLI(t, "", "validate", "--explain", "ada@acme.io")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "Score breakdown (profile default)")
+	assert.Contains(t, stdout, "valid_threshold")
+
+	code, stdout, _ = runCLI(t, "", "validate", "--mode", "fast", "ada@acme.io")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "skipped")
+
+	code, _, stderr := runCLI(t, "", "validate", "--checks", "syntax,bogus", "ada@acme.io")
+	assert.Equal(t, cli.ExitUsage, code)
+	assert.Contains(t, stderr, "unknown check")
+}
+
+func TestDomainCommand(t *testing.T) {
+	code, stdout, _ := runCLI(t, "", "domain", "--output=json", "ACME.io")
+	assert.Equal(t, cli.ExitOK, code)
+	var result model.DomainValidationResponse
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, model.DomainValidationResponse{Domain: "acme.io", DomainExists: true, MXRecords: true}, result)
+
+	code, stdout, _ = runCLI(t, "", "domain", "missing.example")
+	assert.Equal(t, cli.ExitInvalid, code)
+	assert.Contains(t, stdout, "Domain exists:  false")
+}
+
+func TestTypoCommand(t *testing.T) {
+	code, stdout, _ := runCLI(t, "", "typo", "--output", "json", "ada@gmial.com")
+	assert.Equal(t, cli.ExitInvalid, code)
+	var result model.TypoSuggestionResponse
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, "ada@gmail.com", result.TypoSuggestion)
+
+	code, _, _ = runCLI(t, "", "typo", "ada@acme.io")
+	assert.Equal(t, cli.ExitOK, code)
+}
+
+func TestBatchCommand(t *testing.T) {
+	dir := t.TempDir()
+	csvIn := filepath.Join(dir, "contacts.csv")
+	require.NoError(t, os.WriteFile(csvIn, []byte("name,email\nAda,ada@acme.io\nBob,not-an-email\n"), 0o600))
+
+	t.Run("CSV to CSV keeps original columns", func(t *testing.T) {
+		out := filepath.Join(dir, "validated.csv")
+		code, _, stderr := runCLI(t, "", "batch", "--in", csvIn, "--out", out)
+		assert.Equal(t, cli.ExitInvalid, code)
+		assert.Contains(t, stderr, "Validated 2 emails")
+
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Equal(t, "name,email,status,score,reason\n"+
+			"Ada,ada@acme.io,VALID,100,email address is valid\n"+
+			"Bob,not-an-email,INVALID_FORMAT,0,email address syntax is invalid\n", string(data))
+	})
+
+	t.Run("NDJSON to JSON lines", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "\"ada@acme.io\"\n{\"email\":\"bob@acme.io\"}\n", "batch", "--format", "ndjson", "--output", "json")
+		assert.Equal(t, cli.ExitOK, code)
+
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		require.Len(t, lines, 2)
+		var result model.EmailValidationResponse
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &result))
+		assert.Equal(t, "bob@acme.io", result.Email)
+	})
+
+	t.Run("Text to table", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "ada@acme.io\n\nbob@missing.io\n", "batch")
+		assert.Equal(t, cli.ExitInvalid, code)
+		assert.Contains(t, stdout, "EMAIL")
+		assert.Contains(t, stdout, "bob@missing.io")
+		assert.Contains(t, stdout, "INVALID_DOMAIN")
+	})
+
+	t.Run("Missing input file", func(t *testing.T) {
+		code, _, stderr := runCLI(t, "", "batch", "--in", filepath.Join(dir, "missing.csv"))
+		assert.Equal(t, cli.ExitUsage, code)
+		assert.Contains(t, stderr, "missing.csv")
+	})
+}
+
+func TestUpdateTLDsCommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tlds.txt":
+			fmt.Fprint(w, "# Version 2024010100\nCOM\nIO\nXN--P1AI\n")
+		case "/html":
+			fmt.Fprint(w, "<html><body>Not the list</body></html>\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	out := filepath.Join(t.TempDir(), "tlds.txt")
+	require.NoError(t, os.WriteFile(out, []byte("COM\n"), 0o644))
+
+	code, stdout, _ := runCLI(t, "", "update-tlds", "--url", server.URL+"/tlds.txt", "--out", out)
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "Wrote 3 TLDs")
+
+	tlds, err := validator.NewTLDValidatorWithReader(validator.NewFileDomainReader(out))
+	require.NoError(t, err)
+	assert.True(t, tlds.Validate("io"))
+	assert.True(t, tlds.Validate("xn--p1ai"))
+
+	// A failed or invalid download leaves the current list in place
+	for _, path := range []string{"/missing", "/html"} {
+		code, _, stderr := runCLI(t, "", "update-tlds", "--url", server.URL+path, "--out", out)
+		assert.Equal(t, cli.ExitUsage, code, path)
+		assert.NotEmpty(t, stderr, path)
+	}
+	content, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "XN--P1AI")
+}
+
+func TestUsageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"No command", nil},
+		{"Unknown command", []string{"frobnicate"}},
+		{"Missing email", []string{"validate"}},
+		{"Too many emails", []string{"validate", "a@acme.io", "b@acme.io"}},
+		{"Unknown output", []string{"validate", "--output", "xml", "a@acme.io"}},
+		{"Unknown flag", []string{"domain", "--verbose", "acme.io"}},
+		{"Unknown input format", []string{"batch", "--format", "xlsx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runCLI(t, "", tt.args...)
+			assert.Equal(t, cli.ExitUsage, code)
+			assert.Empty(t, stdout)
+			assert.NotEmpty(t, stderr)
+		})
+	}
+}
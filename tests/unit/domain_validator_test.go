@@ -1,9 +1,11 @@
 package unit
 
 import (
+	"context"
 	"emailvalidator/pkg/validator"
 	"net"
 	"testing"
+	"time"
 )
 
 // MockResolver implements the validator.DNSResolver interface for testing
@@ -14,18 +16,18 @@ type MockResolver struct {
 	MXErrors    map[string]error
 }
 
-func (r *MockResolver) LookupHost(domain string) ([]string, error) {
+func (r *MockResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
 	if err, ok := r.HostErrors[domain]; ok {
-		return nil, err
+		return nil, validator.UnknownTTL, err
 	}
-	return r.HostResults[domain], nil
+	return r.HostResults[domain], validator.UnknownTTL, nil
 }
 
-func (r *MockResolver) LookupMX(domain string) ([]*net.MX, error) {
+func (r *MockResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
 	if err, ok := r.MXErrors[domain]; ok {
-		return nil, err
+		return nil, validator.UnknownTTL, err
 	}
-	return r.MXResults[domain], nil
+	return r.MXResults[domain], validator.UnknownTTL, nil
 }
 
 func TestNullMXRecord(t *testing.T) {
@@ -91,7 +93,7 @@ func TestNullMXRecord(t *testing.T) {
 	// Run the tests
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := domainValidator.ValidateMX(tc.domain)
+			result := domainValidator.ValidateMX(context.Background(), tc.domain)
 			if result != tc.expectedResult {
 				t.Errorf("ValidateMX for %s returned %v, expected %v",
 					tc.domain, result, tc.expectedResult)
@@ -99,3 +101,86 @@ func TestNullMXRecord(t *testing.T) {
 		})
 	}
 }
+
+func TestDomainValidatorReasons(t *testing.T) {
+	mockResolver := &MockResolver{
+		HostResults: map[string][]string{
+			"example.com": {"192.0.2.1"},
+			"gmail.dk":    {"192.0.2.2"},
+		},
+		MXResults: map[string][]*net.MX{
+			"example.com": {{Host: "mail.example.com", Pref: 10}},
+			"gmail.dk":    {{Host: ".", Pref: 0}},
+		},
+		HostErrors: map[string]error{
+			"missing.example": &net.DNSError{Err: "no such host", Name: "missing.example", IsNotFound: true},
+			"slow.example":    &net.DNSError{Err: "i/o timeout", Name: "slow.example", IsTimeout: true},
+			"broken.example":  &net.DNSError{Err: "server misbehaving", Name: "broken.example", IsTemporary: true},
+		},
+		MXErrors: map[string]error{
+			"missing.example": &net.DNSError{Err: "no such host", Name: "missing.example", IsNotFound: true},
+		},
+	}
+	domainValidator := validator.NewDomainValidator(mockResolver, validator.NewDomainCacheManager(time.Minute))
+
+	hostTests := []struct {
+		domain     string
+		wantExists bool
+		wantReason string
+	}{
+		{"example.com", true, ""},
+		{"missing.example", false, validator.ReasonNXDomain},
+		{"slow.example", false, validator.ReasonDNSTimeout},
+		{"broken.example", false, validator.ReasonServFail},
+	}
+	for _, tt := range hostTests {
+		// The second round is answered from the cache, which must keep the reason
+		for round := 0; round < 2; round++ {
+			exists, reason := domainValidator.Check(context.Background(), tt.domain)
+			if exists != tt.wantExists || reason != tt.wantReason {
+				t.Errorf("Check(%q) round %d = %v, %q, want %v, %q", tt.domain, round, exists, reason, tt.wantExists, tt.wantReason)
+			}
+		}
+	}
+
+	mxTests := []struct {
+		domain     string
+		wantMX     bool
+		wantReason string
+	}{
+		{"example.com", true, ""},
+		{"gmail.dk", false, validator.ReasonNullMX},
+		{"missing.example", false, validator.ReasonNXDomain},
+		{"empty.example", false, validator.ReasonNoMXRecords},
+	}
+	for _, tt := range mxTests {
+		for round := 0; round < 2; round++ {
+			hasMX, reason := domainValidator.CheckMX(context.Background(), tt.domain)
+			if hasMX != tt.wantMX || reason != tt.wantReason {
+				t.Errorf("CheckMX(%q) round %d = %v, %q, want %v, %q", tt.domain, round, hasMX, reason, tt.wantMX, tt.wantReason)
+			}
+		}
+	}
+}
+
+func TestDomainValidatorLooksUpPunycode(t *testing.T) {
+	// The resolver only knows the ASCII form, as DNS does
+	mockResolver := &MockResolver{
+		HostResults: map[string][]string{"xn--bcher-kva.de": {"192.0.2.1"}},
+		MXResults: map[string][]*net.MX{
+			"xn--bcher-kva.de": {{Host: "mail.xn--bcher-kva.de", Pref: 10}},
+		},
+		HostErrors: map[string]error{},
+		MXErrors:   map[string]error{},
+	}
+	domainValidator := validator.NewDomainValidator(mockResolver, validator.NewDomainCacheManager(time.Hour))
+
+	for _, domain := range []string{"bücher.de", "BÜCHER.DE", "xn--bcher-kva.de"} {
+		if !domainValidator.Validate(context.Background(), domain) {
+			t.Errorf("Validate(%q) = false, want true", domain)
+		}
+		if !domainValidator.ValidateMX(context.Background(), domain) {
+			t.Errorf("ValidateMX(%q) = false, want true", domain)
+		}
+	}
+}
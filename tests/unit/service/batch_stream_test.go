@@ -0,0 +1,106 @@
+// Package servicetest contains unit tests for streamed batch validation
+package servicetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"emailvalidator/internal/model"
+	"emailvalidator/internal/service"
+	"emailvalidator/tests/unit/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newStreamTestBatchService returns a batch service that rates every email VALID.
+// Checks of slow.example are held until release is closed.
+func newStreamTestBatchService(release <-chan struct{}) *service.BatchValidationService {
+	rv := new(mocks.MockEmailRuleValidator)
+	dv := new(mocks.MockDomainValidationService)
+	mc := new(mocks.MockMetricsCollector)
+
+	rv.On("ValidateSyntax", mock.Anything).Return(true)
+	rv.On("IsRoleBased", mock.Anything).Return(false)
+	rv.On("DetectAlias", mock.Anything).Return("")
+	rv.On("GetTypoSuggestions", mock.Anything, mock.Anything).Return([]string{})
+	dv.On("ValidateDomainConcurrently", mock.Anything, "slow.example").
+		Run(func(mock.Arguments) { <-release }).
+		Return(true, true, false)
+	dv.On("ValidateDomainConcurrently", mock.Anything, mock.Anything).Return(true, true, false)
+	mc.On("RecordValidationScore", "overall", mock.Anything)
+
+	return service.NewBatchValidationService(rv, dv, mc)
+}
+
+func TestBatchValidationService_ValidateEmailsStream(t *testing.T) {
+	release := make(chan struct{})
+	svc := newStreamTestBatchService(release)
+	emails := []string{"a@slow.example", "b@fast.example", "invalid", ""}
+
+	var emitted []model.EmailValidationResponse
+	done := make(chan struct{})
+	var summary model.BatchSummary
+	var err error
+	go func() {
+		defer close(done)
+		summary, err = svc.ValidateEmailsStream(context.Background(), emails, func(result model.EmailValidationResponse) error {
+			emitted = append(emitted, result)
+			if len(emitted) == 3 {
+				// Everything but the slow domain is out before its check finishes
+				close(release)
+			}
+			return nil
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not finish; results were held back by the slow domain")
+	}
+
+	require.NoError(t, err)
+	require.Len(t, emitted, 4)
+	assert.Equal(t, "a@slow.example", emitted[3].Email)
+	assert.Equal(t, 4, summary.Total)
+	assert.Equal(t, map[model.ValidationStatus]int{
+		model.ValidationStatusValid:         2,
+		model.ValidationStatusInvalidFormat: 1,
+		model.ValidationStatusMissingEmail:  1,
+	}, summary.StatusCounts)
+}
+
+func TestBatchValidationService_ValidateEmailsStream_EmitError(t *testing.T) {
+	release := make(chan struct{})
+	close(release)
+	svc := newStreamTestBatchService(release)
+	emails := jobTestEmails(50)
+	errClosed := errors.New("client went away")
+
+	calls := 0
+	summary, err := svc.ValidateEmailsStream(context.Background(), emails, func(model.EmailValidationResponse) error {
+		calls++
+		return errClosed
+	})
+
+	assert.ErrorIs(t, err, errClosed)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 0, summary.Total)
+}
+
+func TestBatchValidationService_ValidateEmailsStream_Empty(t *testing.T) {
+	svc := newStreamTestBatchService(nil)
+
+	summary, err := svc.ValidateEmailsStream(context.Background(), nil, func(model.EmailValidationResponse) error {
+		t.Fatal("nothing should be emitted")
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, summary.Total)
+	assert.NotNil(t, summary.StatusCounts)
+}
@@ -2,10 +2,12 @@
 package servicetest
 
 import (
+	"context"
 	"testing"
 
 	"emailvalidator/internal/model"
 	"emailvalidator/internal/service"
+	"emailvalidator/pkg/validator"
 	"emailvalidator/tests/unit/service/mocks"
 
 	"github.com/stretchr/testify/assert"
@@ -35,10 +37,9 @@ func TestBatchValidationService_ValidateEmails(t *testing.T) {
 				rv.On("ValidateSyntax", "test@example.com").Return(true)
 				rv.On("IsRoleBased", "test@example.com").Return(false)
 				rv.On("DetectAlias", "test@example.com").Return("")
-				rv.On("GetTypoSuggestions", "test@example.com").Return([]string{})
+				rv.On("GetTypoSuggestions", mock.Anything, "test@example.com").Return([]string{})
 				dv.On("ValidateDomainConcurrently", mock.Anything, "example.com").Return(true, true, false)
-				rv.On("CalculateScore", mock.Anything).Return(95)
-				mc.On("RecordValidationScore", "overall", float64(95))
+				mc.On("RecordValidationScore", "overall", float64(100))
 			},
 			expected: model.BatchValidationResponse{
 				Results: []model.EmailValidationResponse{
@@ -52,7 +53,7 @@ func TestBatchValidationService_ValidateEmails(t *testing.T) {
 							IsRoleBased:   false,
 							MailboxExists: true,
 						},
-						Score:  95,
+						Score:  100,
 						Status: model.ValidationStatusValid,
 					},
 				},
@@ -65,10 +66,9 @@ func TestBatchValidationService_ValidateEmails(t *testing.T) {
 				rv.On("ValidateSyntax", "test@gmial.com").Return(true)
 				rv.On("IsRoleBased", "test@gmial.com").Return(false)
 				rv.On("DetectAlias", "test@gmial.com").Return("")
-				rv.On("GetTypoSuggestions", "test@gmial.com").Return([]string{"test@gmail.com"})
+				rv.On("GetTypoSuggestions", mock.Anything, "test@gmial.com").Return([]string{"test@gmail.com"})
 				dv.On("ValidateDomainConcurrently", mock.Anything, "gmial.com").Return(true, true, false)
-				rv.On("CalculateScore", mock.Anything).Return(95)
-				mc.On("RecordValidationScore", "overall", float64(75)) // 95 - 20 (typo penalty)
+				mc.On("RecordValidationScore", "overall", float64(80)) // 100 - 20 (typo penalty)
 			},
 			expected: model.BatchValidationResponse{
 				Results: []model.EmailValidationResponse{
@@ -82,7 +82,7 @@ func TestBatchValidationService_ValidateEmails(t *testing.T) {
 							IsRoleBased:   false,
 							MailboxExists: true,
 						},
-						Score:          75, // 95 - 20 (typo penalty)
+						Score:          80, // 100 - 20 (typo penalty)
 						Status:         model.ValidationStatusProbablyValid,
 						TypoSuggestion: "test@gmail.com",
 					},
@@ -97,20 +97,18 @@ func TestBatchValidationService_ValidateEmails(t *testing.T) {
 				rv.On("ValidateSyntax", "test1@example.com").Return(true)
 				rv.On("IsRoleBased", "test1@example.com").Return(false)
 				rv.On("DetectAlias", "test1@example.com").Return("")
-				rv.On("GetTypoSuggestions", "test1@example.com").Return([]string{})
+				rv.On("GetTypoSuggestions", mock.Anything, "test1@example.com").Return([]string{})
 
 				// Second email
 				rv.On("ValidateSyntax", "test2@example.com").Return(true)
 				rv.On("IsRoleBased", "test2@example.com").Return(false)
 				rv.On("DetectAlias", "test2@example.com").Return("")
-				rv.On("GetTypoSuggestions", "test2@example.com").Return([]string{})
+				rv.On("GetTypoSuggestions", mock.Anything, "test2@example.com").Return([]string{})
 
 				// Domain validation (called once for the domain)
 				dv.On("ValidateDomainConcurrently", mock.Anything, "example.com").Return(true, true, false)
 
-				// Score calculations
-				rv.On("CalculateScore", mock.Anything).Return(95).Times(2)
-				mc.On("RecordValidationScore", "overall", float64(95)).Times(2)
+				mc.On("RecordValidationScore", "overall", float64(100)).Times(2)
 			},
 			expected: model.BatchValidationResponse{
 				Results: []model.EmailValidationResponse{
@@ -124,7 +122,7 @@ func TestBatchValidationService_ValidateEmails(t *testing.T) {
 							IsRoleBased:   false,
 							MailboxExists: true,
 						},
-						Score:  95,
+						Score:  100,
 						Status: model.ValidationStatusValid,
 					},
 					{
@@ -137,7 +135,7 @@ func TestBatchValidationService_ValidateEmails(t *testing.T) {
 							IsRoleBased:   false,
 							MailboxExists: true,
 						},
-						Score:  95,
+						Score:  100,
 						Status: model.ValidationStatusValid,
 					},
 				},
@@ -162,7 +160,7 @@ func TestBatchValidationService_ValidateEmails(t *testing.T) {
 			)
 
 			// Execute
-			result := svc.ValidateEmails(tt.emails)
+			result := svc.ValidateEmails(context.Background(), tt.emails)
 
 			// Assert
 			assert.Equal(t, len(tt.expected.Results), len(result.Results))
@@ -180,3 +178,38 @@ func TestBatchValidationService_ValidateEmails(t *testing.T) {
 		})
 	}
 }
+
+func TestBatchValidationService_PendingMailboxChecks(t *testing.T) {
+	emails := []string{"greylisted@example.com", "known@example.com"}
+
+	mockRuleValidator := new(mocks.MockEmailRuleValidator)
+	mockDomainValidationSvc := new(mocks.MockDomainValidationService)
+	mockMetricsCollector := new(mocks.MockMetricsCollector)
+	mockMailboxVerifier := new(mocks.MockMailboxVerifier)
+
+	for _, email := range emails {
+		mockRuleValidator.On("ValidateSyntax", email).Return(true)
+		mockRuleValidator.On("IsRoleBased", email).Return(false)
+		mockRuleValidator.On("DetectAlias", email).Return("")
+		mockRuleValidator.On("GetTypoSuggestions", mock.Anything, email).Return([]string{})
+	}
+	mockDomainValidationSvc.On("ValidateDomainConcurrently", mock.Anything, "example.com").Return(true, true, false)
+	mockMetricsCollector.On("RecordValidationScore", "overall", float64(100))
+	mockMailboxVerifier.On("VerifyMailbox", mock.Anything, "greylisted@example.com").
+		Return(validator.SMTPResult{Code: 451, Message: "Greylisted, try again later", Pending: true})
+	mockMailboxVerifier.On("VerifyMailbox", mock.Anything, "known@example.com").
+		Return(validator.SMTPResult{Exists: true, Code: 250})
+
+	svc := service.NewBatchValidationService(mockRuleValidator, mockDomainValidationSvc, mockMetricsCollector)
+	svc.SetMailboxVerifier(mockMailboxVerifier)
+
+	result := svc.ValidateEmails(context.Background(), emails)
+
+	assert.Len(t, result.Results, 2)
+	assert.Equal(t, model.ValidationStatusUnknown, result.Results[0].Status)
+	assert.True(t, result.Results[0].Pending)
+	assert.True(t, result.Results[0].Validations.MailboxExists, "a temporary failure must not mark the mailbox as missing")
+	assert.Equal(t, model.ValidationStatusValid, result.Results[1].Status)
+	assert.False(t, result.Results[1].Pending)
+	mockMailboxVerifier.AssertExpectations(t)
+}
@@ -10,6 +10,7 @@ import (
 	"emailvalidator/tests/unit/service/mocks"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestConcurrentDomainValidationService_ValidateDomainConcurrently(t *testing.T) {
@@ -27,8 +28,8 @@ func TestConcurrentDomainValidationService_ValidateDomainConcurrently(t *testing
 			domain:  "example.com",
 			timeout: 5 * time.Second,
 			setup: func(mv *mocks.MockDomainValidator) {
-				mv.On("ValidateDomain", "example.com").Return(true)
-				mv.On("ValidateMXRecords", "example.com").Return(true)
+				mv.On("ValidateDomain", mock.Anything, "example.com").Return(true)
+				mv.On("ValidateMXRecords", mock.Anything, "example.com").Return(true)
 				mv.On("IsDisposable", "example.com").Return(false)
 			},
 			expectedExists:  true,
@@ -40,8 +41,8 @@ func TestConcurrentDomainValidationService_ValidateDomainConcurrently(t *testing
 			domain:  "nonexistent.com",
 			timeout: 5 * time.Second,
 			setup: func(mv *mocks.MockDomainValidator) {
-				mv.On("ValidateDomain", "nonexistent.com").Return(false)
-				mv.On("ValidateMXRecords", "nonexistent.com").Return(false)
+				mv.On("ValidateDomain", mock.Anything, "nonexistent.com").Return(false)
+				mv.On("ValidateMXRecords", mock.Anything, "nonexistent.com").Return(false)
 				mv.On("IsDisposable", "nonexistent.com").Return(false)
 			},
 			expectedExists:  false,
@@ -53,8 +54,8 @@ func TestConcurrentDomainValidationService_ValidateDomainConcurrently(t *testing
 			domain:  "temp.com",
 			timeout: 5 * time.Second,
 			setup: func(mv *mocks.MockDomainValidator) {
-				mv.On("ValidateDomain", "temp.com").Return(true)
-				mv.On("ValidateMXRecords", "temp.com").Return(true)
+				mv.On("ValidateDomain", mock.Anything, "temp.com").Return(true)
+				mv.On("ValidateMXRecords", mock.Anything, "temp.com").Return(true)
 				mv.On("IsDisposable", "temp.com").Return(true)
 			},
 			expectedExists:  true,
@@ -66,8 +67,8 @@ func TestConcurrentDomainValidationService_ValidateDomainConcurrently(t *testing
 			domain:  "slow.com",
 			timeout: 1 * time.Millisecond,
 			setup: func(mv *mocks.MockDomainValidator) {
-				mv.On("ValidateDomain", "slow.com").After(10 * time.Millisecond).Return(true)
-				mv.On("ValidateMXRecords", "slow.com").After(10 * time.Millisecond).Return(true)
+				mv.On("ValidateDomain", mock.Anything, "slow.com").After(10 * time.Millisecond).Return(true)
+				mv.On("ValidateMXRecords", mock.Anything, "slow.com").After(10 * time.Millisecond).Return(true)
 				mv.On("IsDisposable", "slow.com").After(10 * time.Millisecond).Return(false)
 			},
 			expectedExists:  false,
@@ -102,3 +103,17 @@ func TestConcurrentDomainValidationService_ValidateDomainConcurrently(t *testing
 		})
 	}
 }
+
+func TestConcurrentDomainValidationService_CheckSelectedDomainConcurrently(t *testing.T) {
+	mockValidator := new(mocks.MockDomainValidator)
+	mockValidator.On("IsDisposable", "temp.com").Return(true)
+	svc := service.NewConcurrentDomainValidationService(mockValidator)
+
+	check := svc.CheckSelectedDomainConcurrently(context.Background(), "temp.com", service.DomainChecks{Disposable: true})
+
+	assert.Equal(t, service.DomainCheck{IsDisposable: true}, check)
+	// The existence and MX lookups weren't requested, so they must not run
+	mockValidator.AssertExpectations(t)
+	mockValidator.AssertNotCalled(t, "ValidateDomain", mock.Anything, "temp.com")
+	mockValidator.AssertNotCalled(t, "ValidateMXRecords", mock.Anything, "temp.com")
+}
@@ -2,10 +2,13 @@
 package servicetest
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"emailvalidator/internal/model"
 	"emailvalidator/internal/service"
+	"emailvalidator/pkg/validator"
 	"emailvalidator/tests/unit/service/mocks"
 
 	"github.com/stretchr/testify/assert"
@@ -35,8 +38,8 @@ func (m *MockEmailValidator) CalculateScore(validations map[string]bool) int {
 }
 
 // GetTypoSuggestions implements the validator.EmailValidator interface
-func (m *MockEmailValidator) GetTypoSuggestions(email string) []string {
-	return m.MockEmailRuleValidator.GetTypoSuggestions(email)
+func (m *MockEmailValidator) GetTypoSuggestions(ctx context.Context, email string) []string {
+	return m.MockEmailRuleValidator.GetTypoSuggestions(ctx, email)
 }
 
 // DetectAlias implements the validator.EmailValidator interface
@@ -45,13 +48,13 @@ func (m *MockEmailValidator) DetectAlias(email string) string {
 }
 
 // ValidateDomain implements the validator.EmailValidator interface
-func (m *MockEmailValidator) ValidateDomain(domain string) bool {
-	return m.MockDomainValidator.ValidateDomain(domain)
+func (m *MockEmailValidator) ValidateDomain(ctx context.Context, domain string) bool {
+	return m.MockDomainValidator.ValidateDomain(ctx, domain)
 }
 
 // ValidateMXRecords implements the validator.EmailValidator interface
-func (m *MockEmailValidator) ValidateMXRecords(domain string) bool {
-	return m.MockDomainValidator.ValidateMXRecords(domain)
+func (m *MockEmailValidator) ValidateMXRecords(ctx context.Context, domain string) bool {
+	return m.MockDomainValidator.ValidateMXRecords(ctx, domain)
 }
 
 // IsDisposable implements the validator.EmailValidator interface
@@ -95,10 +98,9 @@ func TestEmailService_ValidateEmail(t *testing.T) {
 				rv.On("ValidateSyntax", "test@example.com").Return(true)
 				rv.On("IsRoleBased", "test@example.com").Return(false)
 				rv.On("DetectAlias", "test@example.com").Return("")
-				rv.On("GetTypoSuggestions", "test@example.com").Return([]string{})
+				rv.On("GetTypoSuggestions", mock.Anything, "test@example.com").Return([]string{})
 				dv.On("ValidateDomainConcurrently", mock.Anything, "example.com").Return(true, true, false)
-				rv.On("CalculateScore", mock.Anything).Return(95)
-				mc.On("RecordValidationScore", "overall", float64(95))
+				mc.On("RecordValidationScore", "overall", float64(100))
 			},
 			expected: model.EmailValidationResponse{
 				Email: "test@example.com",
@@ -110,7 +112,7 @@ func TestEmailService_ValidateEmail(t *testing.T) {
 					IsRoleBased:   false,
 					MailboxExists: true,
 				},
-				Score:  95,
+				Score:  100,
 				Status: model.ValidationStatusValid,
 			},
 		},
@@ -140,7 +142,7 @@ func TestEmailService_ValidateEmail(t *testing.T) {
 			svc.SetMetricsCollector(mockMetricsCollector)
 
 			// Execute
-			result := svc.ValidateEmail(tt.email)
+			result := svc.ValidateEmail(context.Background(), tt.email)
 
 			// Assert
 			assert.Equal(t, tt.expected.Email, result.Email)
@@ -155,3 +157,95 @@ func TestEmailService_ValidateEmail(t *testing.T) {
 		})
 	}
 }
+
+func TestEmailService_ValidateEmail_MailboxProbe(t *testing.T) {
+	tests := []struct {
+		name         string
+		result       validator.SMTPResult
+		wantMailbox  bool
+		wantCatchAll bool
+		wantScore    int
+		wantStatus   model.ValidationStatus
+		wantPending  bool
+	}{
+		{
+			name:        "Mailbox accepted",
+			result:      validator.SMTPResult{Exists: true, Code: 250},
+			wantMailbox: true,
+			wantScore:   100,
+			wantStatus:  model.ValidationStatusValid,
+		},
+		{
+			name:        "Mailbox rejected",
+			result:      validator.SMTPResult{Exists: false, Code: 550},
+			wantMailbox: false,
+			wantScore:   80,
+			wantStatus:  model.ValidationStatusInvalid,
+		},
+		{
+			name:         "Catch-all domain",
+			result:       validator.SMTPResult{Exists: true, CatchAll: true, Code: 250},
+			wantMailbox:  true,
+			wantCatchAll: true,
+			wantScore:    85, // 100 - 15 (catch-all penalty)
+			wantStatus:   model.ValidationStatusCatchAll,
+		},
+		{
+			name:        "Greylisted with retry scheduled",
+			result:      validator.SMTPResult{Code: 450, Message: "Greylisted", Pending: true},
+			wantMailbox: true,
+			wantScore:   100,
+			wantStatus:  model.ValidationStatusUnknown,
+			wantPending: true,
+		},
+		{
+			name:        "Temporary failures after all retries",
+			result:      validator.SMTPResult{Code: 421, Message: "Service not available"},
+			wantMailbox: true,
+			wantScore:   100,
+			wantStatus:  model.ValidationStatusUnknown,
+		},
+		{
+			name:        "Probe inconclusive falls back to MX result",
+			result:      validator.SMTPResult{Err: errors.New("connection refused")},
+			wantMailbox: true,
+			wantScore:   100,
+			wantStatus:  model.ValidationStatusValid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := "user@example.com"
+			mockRuleValidator := new(mocks.MockEmailRuleValidator)
+			mockDomainValidationSvc := new(mocks.MockDomainValidationService)
+			mockMetricsCollector := new(mocks.MockMetricsCollector)
+			mockMailboxVerifier := new(mocks.MockMailboxVerifier)
+
+			mockRuleValidator.On("ValidateSyntax", email).Return(true)
+			mockRuleValidator.On("IsRoleBased", email).Return(false)
+			mockRuleValidator.On("DetectAlias", email).Return("")
+			mockRuleValidator.On("GetTypoSuggestions", mock.Anything, email).Return([]string{})
+			mockDomainValidationSvc.On("ValidateDomainConcurrently", mock.Anything, "example.com").Return(true, true, false)
+			mockMetricsCollector.On("RecordValidationScore", "overall", float64(tt.wantScore))
+			mockMailboxVerifier.On("VerifyMailbox", mock.Anything, email).Return(tt.result)
+
+			svc := service.NewEmailServiceWithDeps(&MockEmailValidator{
+				MockEmailRuleValidator: mockRuleValidator,
+				MockDomainValidator:    new(mocks.MockDomainValidator),
+			})
+			svc.SetDomainValidationService(mockDomainValidationSvc)
+			svc.SetMetricsCollector(mockMetricsCollector)
+			svc.SetMailboxVerifier(mockMailboxVerifier)
+
+			result := svc.ValidateEmail(context.Background(), email)
+
+			assert.Equal(t, tt.wantMailbox, result.Validations.MailboxExists)
+			assert.Equal(t, tt.wantCatchAll, result.Validations.IsCatchAll)
+			assert.Equal(t, tt.wantPending, result.Pending)
+			assert.Equal(t, tt.wantScore, result.Score)
+			assert.Equal(t, tt.wantStatus, result.Status)
+			mockMailboxVerifier.AssertExpectations(t)
+		})
+	}
+}
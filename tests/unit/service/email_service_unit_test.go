@@ -2,12 +2,16 @@
 package servicetest
 
 import (
+	"context"
 	"emailvalidator/internal/model"
 	"emailvalidator/internal/service"
 	"emailvalidator/pkg/validator"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -17,16 +21,16 @@ type mockDNSResolver struct {
 	delay time.Duration
 }
 
-func (m *mockDNSResolver) LookupMX(domain string) ([]*net.MX, error) {
+func (m *mockDNSResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
 	// Simulate network latency
 	time.Sleep(m.delay)
-	return []*net.MX{{Host: "mail." + domain, Pref: 10}}, nil
+	return []*net.MX{{Host: "mail." + domain, Pref: 10}}, validator.UnknownTTL, nil
 }
 
-func (m *mockDNSResolver) LookupHost(domain string) ([]string, error) {
+func (m *mockDNSResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
 	// Simulate network latency
 	time.Sleep(m.delay)
-	return []string{"192.0.2.1"}, nil
+	return []string{"192.0.2.1"}, validator.UnknownTTL, nil
 }
 
 func TestServiceValidateEmail(t *testing.T) {
@@ -40,7 +44,7 @@ func TestServiceValidateEmail(t *testing.T) {
 	}{
 		{
 			name:       "Valid email",
-			email:      "user@example.com",
+			email:      "user@acme.io",
 			wantScore:  100,
 			wantSyntax: true,
 			wantStatus: model.ValidationStatusValid,
@@ -54,7 +58,7 @@ func TestServiceValidateEmail(t *testing.T) {
 		},
 		{
 			name:          "Role-based email",
-			email:         "admin@example.com",
+			email:         "admin@acme.io",
 			wantScore:     90,
 			wantSyntax:    true,
 			wantRoleBased: true,
@@ -67,6 +71,13 @@ func TestServiceValidateEmail(t *testing.T) {
 			wantSyntax: false,
 			wantStatus: model.ValidationStatusMissingEmail,
 		},
+		{
+			name:       "Reserved domain",
+			email:      "user@example.com",
+			wantScore:  0,
+			wantSyntax: true,
+			wantStatus: model.ValidationStatusReserved,
+		},
 		{
 			name:       "Email with typo",
 			email:      "user@outlok.com",
@@ -87,7 +98,7 @@ func TestServiceValidateEmail(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := emailService.ValidateEmail(tt.email)
+			result := emailService.ValidateEmail(context.Background(), tt.email)
 
 			if result.Score != tt.wantScore {
 				t.Errorf("Score = %v, want %v", result.Score, tt.wantScore)
@@ -136,7 +147,7 @@ func TestServiceValidateEmails(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := emailService.ValidateEmails(tt.emails)
+			result := emailService.ValidateEmails(context.Background(), tt.emails)
 
 			if len(result.Results) != tt.wantCount {
 				t.Errorf("got %d results, want %d", len(result.Results), tt.wantCount)
@@ -167,6 +178,13 @@ func TestServiceGetTypoSuggestions(t *testing.T) {
 			wantHasSuggestion: false,
 			wantSuggestion:    "",
 		},
+		{
+			name:              "Typosquat that receives mail",
+			email:             "user@gmial.com",
+			wantEmail:         "user@gmial.com",
+			wantHasSuggestion: true,
+			wantSuggestion:    "user@gmail.com",
+		},
 	}
 
 	mockResolver := &mockDNSResolver{
@@ -180,7 +198,7 @@ func TestServiceGetTypoSuggestions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := emailService.GetTypoSuggestions(tt.email)
+			result := emailService.GetTypoSuggestions(context.Background(), tt.email)
 
 			if result.Email != tt.wantEmail {
 				t.Errorf("Email = %v, want %v", result.Email, tt.wantEmail)
@@ -194,6 +212,14 @@ func TestServiceGetTypoSuggestions(t *testing.T) {
 			if tt.wantHasSuggestion && result.TypoSuggestion != tt.wantSuggestion {
 				t.Errorf("TypoSuggestion = %v, want %v", result.TypoSuggestion, tt.wantSuggestion)
 			}
+
+			if tt.wantHasSuggestion && (result.Confidence <= 0 || result.Confidence > 1) {
+				t.Errorf("Confidence = %v, want a value in (0, 1]", result.Confidence)
+			}
+
+			if tt.wantHasSuggestion && (len(result.Suggestions) == 0 || result.Suggestions[0].Email != tt.wantSuggestion) {
+				t.Errorf("Suggestions = %v, want %v ranked first", result.Suggestions, tt.wantSuggestion)
+			}
 		})
 	}
 }
@@ -218,8 +244,8 @@ func TestServiceGetAPIStatus(t *testing.T) {
 	}
 
 	// Make some requests and check counter
-	emailService.ValidateEmail("test@example.com")
-	emailService.ValidateEmail("another@example.com")
+	emailService.ValidateEmail(context.Background(), "test@example.com")
+	emailService.ValidateEmail(context.Background(), "another@example.com")
 
 	status = emailService.GetAPIStatus()
 	if status.RequestsHandled != 2 {
@@ -240,6 +266,8 @@ func TestServiceParallelBatchValidation(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create validator: %v", err)
 	}
+	// Disable domain caching so both runs pay the DNS latency and only concurrency differs
+	emailValidator.SetCacheDuration(0)
 	emailService := service.NewEmailServiceWithDeps(emailValidator)
 
 	// Create a larger batch of emails with mixed domains
@@ -258,7 +286,7 @@ func TestServiceParallelBatchValidation(t *testing.T) {
 
 	for run := 0; run < runs; run++ {
 		start := time.Now()
-		result := emailService.ValidateEmails(emails)
+		result := emailService.ValidateEmails(context.Background(), emails)
 		parallelDuration := time.Since(start)
 		totalParallel += parallelDuration
 
@@ -278,7 +306,7 @@ func TestServiceParallelBatchValidation(t *testing.T) {
 		// Time sequential execution
 		start = time.Now()
 		for _, email := range emails {
-			emailService.ValidateEmail(email)
+			emailService.ValidateEmail(context.Background(), email)
 		}
 		totalSequential += time.Since(start)
 	}
@@ -344,7 +372,7 @@ func TestServiceBatchValidationEdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := emailService.ValidateEmails(tt.emails)
+			result := emailService.ValidateEmails(context.Background(), tt.emails)
 
 			if len(result.Results) != tt.wantCount {
 				t.Errorf("got %d results, want %d", len(result.Results), tt.wantCount)
@@ -404,7 +432,7 @@ func TestServiceEmailValidationErrorScenarios(t *testing.T) {
 				tt.setupFunc()
 			}
 
-			result := emailService.ValidateEmail(tt.email)
+			result := emailService.ValidateEmail(context.Background(), tt.email)
 
 			if result.Status != tt.wantStatus {
 				t.Errorf("Status = %v, want %v", result.Status, tt.wantStatus)
@@ -419,3 +447,514 @@ func TestServiceEmailValidationErrorScenarios(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceFreeProviderClassification(t *testing.T) {
+	mockResolver := &mockDNSResolver{}
+	emailValidator, err := validator.NewEmailValidatorWithResolver(mockResolver)
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	emailService := service.NewEmailServiceWithDeps(emailValidator)
+
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"someone@gmail.com", true},
+		{"someone@yahoo.com", true},
+		{"someone@acme-corp.io", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			single := emailService.ValidateEmail(context.Background(), tt.email)
+			if single.Validations.IsFreeProvider != tt.want {
+				t.Errorf("ValidateEmail IsFreeProvider = %v, want %v", single.Validations.IsFreeProvider, tt.want)
+			}
+
+			batch := emailService.ValidateEmails(context.Background(), []string{tt.email})
+			if batch.Results[0].Validations.IsFreeProvider != tt.want {
+				t.Errorf("ValidateEmails IsFreeProvider = %v, want %v", batch.Results[0].Validations.IsFreeProvider, tt.want)
+			}
+		})
+	}
+}
+
+// reasonsDNSResolver fails lookups in the ways the validation reasons distinguish
+type reasonsDNSResolver struct{}
+
+func (reasonsDNSResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	switch domain {
+	case "missing.net":
+		return nil, validator.UnknownTTL, &net.DNSError{Err: "no such host", Name: domain, IsNotFound: true}
+	case "slow.net":
+		return nil, validator.UnknownTTL, &net.DNSError{Err: "i/o timeout", Name: domain, IsTimeout: true}
+	}
+	return []string{"192.0.2.1"}, validator.UnknownTTL, nil
+}
+
+func (reasonsDNSResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
+	switch domain {
+	case "missing.net", "nomx.net":
+		return nil, validator.UnknownTTL, &net.DNSError{Err: "no such host", Name: domain, IsNotFound: true}
+	case "slow.net":
+		return nil, validator.UnknownTTL, &net.DNSError{Err: "i/o timeout", Name: domain, IsTimeout: true}
+	case "nullmx.net":
+		return []*net.MX{{Host: ".", Pref: 0}}, validator.UnknownTTL, nil
+	}
+	return []*net.MX{{Host: "mail." + domain, Pref: 10}}, validator.UnknownTTL, nil
+}
+
+func TestServiceValidationReasons(t *testing.T) {
+	emailValidator, err := validator.NewEmailValidatorWithResolver(reasonsDNSResolver{})
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	emailService := service.NewEmailServiceWithDeps(emailValidator)
+
+	tests := []struct {
+		email string
+		want  []string
+	}{
+		{"user@acme.io", nil},
+		{"", []string{"syntax:" + validator.ReasonEmptyEmail}},
+		{"user.example.com", []string{"syntax:" + validator.ReasonMissingAtSign}},
+		{"john..doe@example.com", []string{"syntax:" + validator.ReasonConsecutiveDots}},
+		{"user@foo.notatld", []string{"syntax:" + validator.ReasonUnknownTLD}},
+		{"user@missing.net", []string{"domain:" + validator.ReasonNXDomain}},
+		{"user@slow.net", []string{"domain:" + validator.ReasonDNSTimeout}},
+		{"user@nomx.net", []string{"mx:" + validator.ReasonNoMXRecords}},
+		{"user@nullmx.net", []string{"mx:" + validator.ReasonNullMX}},
+		{"admin@acme.io", []string{"role_based:" + validator.ReasonRoleBased}},
+		{"user@example.com", []string{"domain:" + validator.ReasonReservedDomain}},
+		{"user@mail.test", []string{"domain:" + validator.ReasonReservedDomain}},
+		{"user@gmаil.com", []string{"spoof:" + validator.ReasonSpoofSuspect, "typo:" + validator.ReasonPossibleTypo}},
+	}
+
+	codes := func(reasons []model.Reason) []string {
+		var got []string
+		for _, reason := range reasons {
+			if reason.Message == "" {
+				t.Errorf("reason %s has no message", reason.Code)
+			}
+			got = append(got, reason.Check+":"+reason.Code)
+		}
+		return got
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			single := emailService.ValidateEmail(context.Background(), tt.email)
+			if got := codes(single.Reasons); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ValidateEmail reasons = %v, want %v", got, tt.want)
+			}
+
+			batch := emailService.ValidateEmails(context.Background(), []string{tt.email})
+			if got := codes(batch.Results[0].Reasons); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ValidateEmails reasons = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// countingDNSResolver counts lookups and resolves every domain
+type countingDNSResolver struct {
+	lookups atomic.Int32
+}
+
+func (r *countingDNSResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	r.lookups.Add(1)
+	return []string{"192.0.2.1"}, validator.UnknownTTL, nil
+}
+
+func (r *countingDNSResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
+	r.lookups.Add(1)
+	return []*net.MX{{Host: "mail." + domain, Pref: 10}}, validator.UnknownTTL, nil
+}
+
+func TestServiceUnknownTLDSkipsDNS(t *testing.T) {
+	resolver := &countingDNSResolver{}
+	emailValidator, err := validator.NewEmailValidatorWithResolver(resolver)
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	emailService := service.NewEmailServiceWithDeps(emailValidator)
+
+	single := emailService.ValidateEmail(context.Background(), "user@foo.notatld")
+	batch := emailService.ValidateEmails(context.Background(), []string{"user@bar.notatld"})
+
+	if single.Status != model.ValidationStatusInvalidFormat || batch.Results[0].Status != model.ValidationStatusInvalidFormat {
+		t.Errorf("Status = %v and %v, want %v", single.Status, batch.Results[0].Status, model.ValidationStatusInvalidFormat)
+	}
+	if lookups := resolver.lookups.Load(); lookups != 0 {
+		t.Errorf("DNS lookups = %d, want 0", lookups)
+	}
+}
+
+func TestServiceReservedDomainSkipsDNS(t *testing.T) {
+	resolver := &countingDNSResolver{}
+	emailValidator, err := validator.NewEmailValidatorWithResolver(resolver)
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	emailService := service.NewEmailServiceWithDeps(emailValidator)
+
+	emails := []string{"user@example.com", "user@host.localhost", "user@printer.local", "user@abc.onion", "user@x.invalid"}
+	for _, email := range emails {
+		if result := emailService.ValidateEmail(context.Background(), email); result.Status != model.ValidationStatusReserved {
+			t.Errorf("ValidateEmail(%q).Status = %v, want %v", email, result.Status, model.ValidationStatusReserved)
+		}
+	}
+	for _, result := range emailService.ValidateEmails(context.Background(), emails).Results {
+		if result.Status != model.ValidationStatusReserved {
+			t.Errorf("ValidateEmails status of %q = %v, want %v", result.Email, result.Status, model.ValidationStatusReserved)
+		}
+	}
+	if domain := emailService.ValidateDomain(context.Background(), "example.com"); !domain.IsReserved {
+		t.Errorf("ValidateDomain(example.com).IsReserved = false, want true")
+	}
+	if lookups := resolver.lookups.Load(); lookups != 0 {
+		t.Errorf("DNS lookups = %d, want 0", lookups)
+	}
+
+	// Allowed reserved domains are looked up like any other
+	emailValidator.AllowReservedDomains("test")
+	if result := emailService.ValidateEmail(context.Background(), "user@mail.test"); result.Status != model.ValidationStatusValid {
+		t.Errorf("ValidateEmail(user@mail.test).Status = %v, want %v", result.Status, model.ValidationStatusValid)
+	}
+	if resolver.lookups.Load() == 0 {
+		t.Errorf("DNS lookups = 0, want lookups for an allowed reserved domain")
+	}
+}
+
+// asciiOnlyDNSResolver resolves domains in ASCII form only, as DNS does
+type asciiOnlyDNSResolver struct{}
+
+func (asciiOnlyDNSResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	for _, r := range domain {
+		if r >= 0x80 {
+			return nil, validator.UnknownTTL, &net.DNSError{Err: "no such host", Name: domain, IsNotFound: true}
+		}
+	}
+	return []string{"192.0.2.1"}, validator.UnknownTTL, nil
+}
+
+func (r asciiOnlyDNSResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
+	if _, _, err := r.LookupHost(ctx, domain); err != nil {
+		return nil, validator.UnknownTTL, err
+	}
+	return []*net.MX{{Host: "mail." + domain, Pref: 10}}, validator.UnknownTTL, nil
+}
+
+func TestServiceInternationalizedEmail(t *testing.T) {
+	emailValidator, err := validator.NewEmailValidatorWithResolver(asciiOnlyDNSResolver{})
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	emailService := service.NewEmailServiceWithDeps(emailValidator)
+
+	tests := []struct {
+		email       string
+		wantUnicode string
+		wantASCII   string
+		wantUTF8    bool
+	}{
+		{"user@bücher.de", "user@bücher.de", "user@xn--bcher-kva.de", false},
+		{"user@xn--bcher-kva.de", "user@bücher.de", "user@xn--bcher-kva.de", false},
+		{"José@bücher.de", "José@bücher.de", "José@xn--bcher-kva.de", true},
+	}
+
+	batch := emailService.ValidateEmails(context.Background(), []string{tests[0].email, tests[1].email, tests[2].email})
+	for i, tt := range tests {
+		for _, result := range []model.EmailValidationResponse{emailService.ValidateEmail(context.Background(), tt.email), batch.Results[i]} {
+			if result.Status != model.ValidationStatusValid {
+				t.Errorf("Status of %q = %v, want %v", tt.email, result.Status, model.ValidationStatusValid)
+			}
+			if result.EmailUnicode != tt.wantUnicode || result.EmailASCII != tt.wantASCII {
+				t.Errorf("Forms of %q = %q, %q, want %q, %q", tt.email, result.EmailUnicode, result.EmailASCII, tt.wantUnicode, tt.wantASCII)
+			}
+			if result.RequiresSMTPUTF8 != tt.wantUTF8 {
+				t.Errorf("RequiresSMTPUTF8 of %q = %v, want %v", tt.email, result.RequiresSMTPUTF8, tt.wantUTF8)
+			}
+		}
+	}
+}
+
+func TestServiceScoringProfiles(t *testing.T) {
+	emailValidator, err := validator.NewEmailValidatorWithResolver(&mockDNSResolver{})
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	policy, err := validator.NewScoringPolicy()
+	if err != nil {
+		t.Fatalf("Failed to load scoring profiles: %v", err)
+	}
+	emailService := service.NewEmailServiceWithDeps(emailValidator)
+	emailService.SetScoringPolicy(policy)
+
+	tests := []struct {
+		profile    string
+		wantScore  int
+		wantStatus model.ValidationStatus
+	}{
+		{"", 80, model.ValidationStatusProbablyValid},           // 100 - 20 (typo penalty)
+		{"strict-signup", 70, model.ValidationStatusInvalid},    // 100 - 30, below 80
+		{"lenient-newsletter", 90, model.ValidationStatusValid}, // 100 - 10, above 80
+	}
+
+	email := "user@outlok.com"
+	for _, tt := range tests {
+		t.Run("profile "+tt.profile, func(t *testing.T) {
+			opts := service.ValidationOptions{Profile: tt.profile}
+
+			single, err := emailService.ValidateEmailWithOptions(context.Background(), email, opts)
+			if err != nil {
+				t.Fatalf("ValidateEmailWithOptions() error = %v", err)
+			}
+			batch, err := emailService.ValidateEmailsWithOptions(context.Background(), []string{email}, opts)
+			if err != nil {
+				t.Fatalf("ValidateEmailsWithOptions() error = %v", err)
+			}
+
+			for _, result := range []model.EmailValidationResponse{single, batch.Results[0]} {
+				if result.Score != tt.wantScore || result.Status != tt.wantStatus {
+					t.Errorf("got %d %s, want %d %s", result.Score, result.Status, tt.wantScore, tt.wantStatus)
+				}
+			}
+		})
+	}
+
+	if _, err := emailService.ValidateEmailWithOptions(context.Background(), email, service.ValidationOptions{Profile: "missing"}); !errors.Is(err, service.ErrUnknownProfile) {
+		t.Errorf("ValidateEmailWithOptions() with an unknown profile error = %v, want ErrUnknownProfile", err)
+	}
+	if _, err := emailService.ValidateEmailsWithOptions(context.Background(), []string{email}, service.ValidationOptions{Profile: "missing"}); !errors.Is(err, service.ErrUnknownProfile) {
+		t.Errorf("ValidateEmailsWithOptions() with an unknown profile error = %v, want ErrUnknownProfile", err)
+	}
+}
+
+func TestServiceScoreExplanation(t *testing.T) {
+	emailValidator, err := validator.NewEmailValidatorWithResolver(reasonsDNSResolver{})
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	emailService := service.NewEmailServiceWithDeps(emailValidator)
+
+	tests := []struct {
+		email    string
+		wantRule string
+	}{
+		{"user@acme.io", service.StatusRuleValidThreshold},
+		{"user@example.com", service.StatusRuleReservedDomain},
+		{"user@outlok.com", service.StatusRuleProbablyValidThreshold},
+		{"user@missing.net", service.StatusRuleDomainNotFound},
+		{"user@nomx.net", service.StatusRuleNoMXRecords},
+		{"not-an-email", service.StatusRuleInvalidSyntax},
+		{"", service.StatusRuleMissingEmail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			result, err := emailService.ValidateEmailWithOptions(context.Background(), tt.email, service.ValidationOptions{Explain: true})
+			if err != nil {
+				t.Fatalf("ValidateEmailWithOptions() error = %v", err)
+			}
+			if result.Explanation == nil {
+				t.Fatal("Explanation = nil, want an explanation")
+			}
+			if result.Explanation.StatusRule.Rule != tt.wantRule {
+				t.Errorf("StatusRule = %q, want %q", result.Explanation.StatusRule.Rule, tt.wantRule)
+			}
+
+			total := 0
+			for _, contribution := range result.Explanation.Contributions {
+				total += contribution.Points
+			}
+			if total != result.Score {
+				t.Errorf("contributions add up to %d, want the score %d", total, result.Score)
+			}
+		})
+	}
+
+	result := emailService.ValidateEmail(context.Background(), "user@acme.io")
+	if result.Explanation != nil {
+		t.Errorf("Explanation = %+v, want nil when not requested", result.Explanation)
+	}
+}
+
+func TestServiceCheckSelection(t *testing.T) {
+	resolver := &countingDNSResolver{}
+	emailValidator, err := validator.NewEmailValidatorWithResolver(resolver)
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	emailService := service.NewEmailServiceWithDeps(emailValidator)
+
+	// Fast mode runs no DNS lookups and scales the points of the checks that ran
+	fast := service.ValidationOptions{Mode: service.ModeFast}
+	single, err := emailService.ValidateEmailWithOptions(context.Background(), "user@outlok.com", fast)
+	if err != nil {
+		t.Fatalf("ValidateEmailWithOptions() error = %v", err)
+	}
+	batch, err := emailService.ValidateEmailsWithOptions(context.Background(), []string{"user@outlok.com"}, fast)
+	if err != nil {
+		t.Fatalf("ValidateEmailsWithOptions() error = %v", err)
+	}
+	for _, result := range []model.EmailValidationResponse{single, batch.Results[0]} {
+		if result.Score != 80 || result.Status != model.ValidationStatusProbablyValid {
+			t.Errorf("got %d %s, want 80 %s", result.Score, result.Status, model.ValidationStatusProbablyValid)
+		}
+		if result.TypoSuggestion != "user@outlook.com" {
+			t.Errorf("TypoSuggestion = %q, want user@outlook.com", result.TypoSuggestion)
+		}
+	}
+	if lookups := resolver.lookups.Load(); lookups != 0 {
+		t.Errorf("DNS lookups = %d, want 0", lookups)
+	}
+
+	// Skipped checks are null in JSON and survive a round trip
+	data, err := json.Marshal(single.Validations)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var validations map[string]interface{}
+	if err := json.Unmarshal(data, &validations); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	for _, field := range []string{"domain_exists", "mx_records", "mailbox_exists", "is_catch_all"} {
+		if value, ok := validations[field]; !ok || value != nil {
+			t.Errorf("%s = %v, want null", field, value)
+		}
+	}
+	if validations["is_disposable"] != false {
+		t.Errorf("is_disposable = %v, want false", validations["is_disposable"])
+	}
+	var decoded model.ValidationResults
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if decoded.Ran(model.CheckDomain) || !decoded.Ran(model.CheckDisposable) {
+		t.Errorf("decoded Skipped = %v, want domain skipped and disposable run", decoded.Skipped)
+	}
+
+	// Checks pick single checks; a standard check selection looks the domain up
+	result, err := emailService.ValidateEmailWithOptions(context.Background(), "admin@acme.io", service.ValidationOptions{Checks: []string{"syntax", "typo"}})
+	if err != nil {
+		t.Fatalf("ValidateEmailWithOptions() error = %v", err)
+	}
+	if result.Validations.IsRoleBased || result.Validations.Ran(model.CheckRoleBased) {
+		t.Errorf("role_based ran, want it skipped")
+	}
+	standard, err := emailService.ValidateEmailWithOptions(context.Background(), "user@acme.io", service.ValidationOptions{Mode: service.ModeStandard})
+	if err != nil {
+		t.Fatalf("ValidateEmailWithOptions() error = %v", err)
+	}
+	if standard.Status != model.ValidationStatusValid || standard.Validations.Ran(model.CheckMailbox) {
+		t.Errorf("got %s with mailbox run %v, want %s with the mailbox skipped",
+			standard.Status, standard.Validations.Ran(model.CheckMailbox), model.ValidationStatusValid)
+	}
+	if resolver.lookups.Load() == 0 {
+		t.Errorf("DNS lookups = 0, want lookups in standard mode")
+	}
+
+	invalid := []service.ValidationOptions{
+		{Mode: "turbo"},
+		{Checks: []string{"syntax", "bogus"}},
+		{Mode: service.ModeFast, Checks: []string{"syntax"}},
+		{Mode: service.ModeDeep}, // SMTP verification is disabled
+	}
+	for _, opts := range invalid {
+		if _, err := emailService.ValidateEmailWithOptions(context.Background(), "user@acme.io", opts); !errors.Is(err, service.ErrInvalidChecks) {
+			t.Errorf("ValidateEmailWithOptions(%+v) error = %v, want ErrInvalidChecks", opts, err)
+		}
+		if _, err := emailService.ValidateEmailsWithOptions(context.Background(), []string{"user@acme.io"}, opts); !errors.Is(err, service.ErrInvalidChecks) {
+			t.Errorf("ValidateEmailsWithOptions(%+v) error = %v, want ErrInvalidChecks", opts, err)
+		}
+	}
+}
+
+// hangingDNSResolver resolves every domain, except while hang is set, when lookups wait for their context
+type hangingDNSResolver struct {
+	hang atomic.Bool
+}
+
+func (r *hangingDNSResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	if r.hang.Load() {
+		<-ctx.Done()
+		return nil, validator.UnknownTTL, ctx.Err()
+	}
+	return []string{"192.0.2.1"}, validator.UnknownTTL, nil
+}
+
+func (r *hangingDNSResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
+	if r.hang.Load() {
+		<-ctx.Done()
+		return nil, validator.UnknownTTL, ctx.Err()
+	}
+	return []*net.MX{{Host: "mail." + domain, Pref: 10}}, validator.UnknownTTL, nil
+}
+
+func TestServiceValidationTimeout(t *testing.T) {
+	resolver := &hangingDNSResolver{}
+	resolver.hang.Store(true)
+	emailValidator, err := validator.NewEmailValidatorWithResolver(resolver)
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	emailService := service.NewEmailServiceWithDeps(emailValidator)
+	emailService.SetValidationTimeout(50 * time.Millisecond)
+
+	check := func(name string, result model.EmailValidationResponse) {
+		t.Helper()
+		if !result.TimedOut || result.Status != model.ValidationStatusUnknown {
+			t.Errorf("%s TimedOut = %v, Status = %v, want true, %v", name, result.TimedOut, result.Status, model.ValidationStatusUnknown)
+		}
+		if len(result.Reasons) == 0 || result.Reasons[0].Code != validator.ReasonDNSTimeout {
+			t.Errorf("%s reasons = %+v, want %s first", name, result.Reasons, validator.ReasonDNSTimeout)
+		}
+		if result.Explanation == nil || result.Explanation.StatusRule.Rule != service.StatusRuleTimedOut {
+			t.Errorf("%s explanation = %+v, want rule %s", name, result.Explanation, service.StatusRuleTimedOut)
+		}
+	}
+
+	opts := service.ValidationOptions{Explain: true}
+	start := time.Now()
+	single, err := emailService.ValidateEmailWithOptions(context.Background(), "user@acme.io", opts)
+	if err != nil {
+		t.Fatalf("ValidateEmailWithOptions() error = %v", err)
+	}
+	check("ValidateEmailWithOptions", single)
+	batch, err := emailService.ValidateEmailsWithOptions(context.Background(), []string{"user@acme.io", "user@other.io"}, opts)
+	if err != nil {
+		t.Fatalf("ValidateEmailsWithOptions() error = %v", err)
+	}
+	for _, result := range batch.Results {
+		check("ValidateEmailsWithOptions", result)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("validations took %v, want them to stop at the deadline", elapsed)
+	}
+
+	// A cancelled request stops the lookups as well
+	resolver.hang.Store(false)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelled, err := emailService.ValidateEmailWithOptions(ctx, "user@fresh.io", opts)
+	if err != nil {
+		t.Fatalf("ValidateEmailWithOptions() error = %v", err)
+	}
+	check("cancelled ValidateEmailWithOptions", cancelled)
+
+	// Timed out lookups aren't cached, so the domain validates once DNS answers
+	if result := emailService.ValidateEmail(context.Background(), "user@acme.io"); result.TimedOut || result.Status != model.ValidationStatusValid {
+		t.Errorf("ValidateEmail after the timeout TimedOut = %v, Status = %v, want false, %v", result.TimedOut, result.Status, model.ValidationStatusValid)
+	}
+
+	// Checks that need no lookups finish in time
+	fast, err := emailService.ValidateEmailWithOptions(ctx, "user@acme.io", service.ValidationOptions{Mode: service.ModeFast})
+	if err != nil {
+		t.Fatalf("ValidateEmailWithOptions() error = %v", err)
+	}
+	if fast.TimedOut || fast.Status != model.ValidationStatusValid {
+		t.Errorf("fast ValidateEmailWithOptions TimedOut = %v, Status = %v, want false, %v", fast.TimedOut, fast.Status, model.ValidationStatusValid)
+	}
+}
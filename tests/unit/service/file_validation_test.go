@@ -0,0 +1,153 @@
+// Package servicetest contains unit tests for CSV and plain-text file validation
+package servicetest
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"emailvalidator/internal/model"
+	"emailvalidator/internal/service"
+	"emailvalidator/tests/unit/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newFileTestBatchService returns a batch service that rates addresses at example.com VALID
+// and every other domain as non-existent
+func newFileTestBatchService() *service.BatchValidationService {
+	rv := new(mocks.MockEmailRuleValidator)
+	dv := new(mocks.MockDomainValidationService)
+	mc := new(mocks.MockMetricsCollector)
+
+	rv.On("ValidateSyntax", mock.Anything).Return(true)
+	rv.On("IsRoleBased", mock.Anything).Return(false)
+	rv.On("DetectAlias", mock.Anything).Return("")
+	rv.On("GetTypoSuggestions", mock.Anything, mock.Anything).Return([]string{})
+	dv.On("ValidateDomainConcurrently", mock.Anything, "example.com").Return(true, true, false)
+	dv.On("ValidateDomainConcurrently", mock.Anything, mock.Anything).Return(false, false, false)
+	mc.On("RecordValidationScore", "overall", mock.Anything)
+
+	return service.NewBatchValidationService(rv, dv, mc)
+}
+
+func TestBatchValidationService_ValidateCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		column  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "Detects email header",
+			input: "name,Email,company\nAda,ada@example.com,Acme\nBob,bob@missing.test,\n",
+			want: "name,Email,company,status,score,reason\n" +
+				"Ada,ada@example.com,Acme,VALID,100,email address is valid\n" +
+				"Bob,bob@missing.test,,INVALID_DOMAIN,40,domain does not exist\n",
+		},
+		{
+			name:  "Detects column without header",
+			input: "Ada,ada@example.com\nBob,\n",
+			want: "Ada,ada@example.com,VALID,100,email address is valid\n" +
+				"Bob,,MISSING_EMAIL,0,email address is missing\n",
+		},
+		{
+			name:   "Column selected by name",
+			input:  "work,personal\nada@missing.test,ada@example.com\n",
+			column: "personal",
+			want: "work,personal,status,score,reason\n" +
+				"ada@missing.test,ada@example.com,VALID,100,email address is valid\n",
+		},
+		{
+			name:   "Column selected by position",
+			input:  "\ufeffcontact\n ada@example.com \n",
+			column: "1",
+			want: "contact,status,score,reason\n" +
+				"\" ada@example.com \",VALID,100,email address is valid\n",
+		},
+		{
+			name:    "Unknown column",
+			input:   "name,email\nAda,ada@example.com\n",
+			column:  "mail",
+			wantErr: service.ErrEmailColumnNotFound,
+		},
+		{
+			name:    "No email column",
+			input:   "name,company\nAda,Acme\n",
+			wantErr: service.ErrEmailColumnNotFound,
+		},
+		{
+			name:    "Empty file",
+			input:   "",
+			wantErr: service.ErrEmptyFile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newFileTestBatchService()
+			var out bytes.Buffer
+
+			_, err := svc.ValidateCSV(context.Background(), strings.NewReader(tt.input), &out, tt.column)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, out.String())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestBatchValidationService_ValidateText(t *testing.T) {
+	svc := newFileTestBatchService()
+	var out bytes.Buffer
+
+	summary, err := svc.ValidateText(context.Background(), strings.NewReader("ada@example.com\r\n\nbob@missing.test\n"), &out)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Total)
+	assert.Equal(t, map[model.ValidationStatus]int{
+		model.ValidationStatusValid:         1,
+		model.ValidationStatusInvalidDomain: 1,
+	}, summary.StatusCounts)
+	assert.Equal(t, "email,status,score,reason\n"+
+		"ada@example.com,VALID,100,email address is valid\n"+
+		"bob@missing.test,INVALID_DOMAIN,40,domain does not exist\n", out.String())
+
+	out.Reset()
+	_, err = svc.ValidateText(context.Background(), strings.NewReader("\n  \n"), &out)
+	assert.ErrorIs(t, err, service.ErrEmptyFile)
+}
+
+func TestBatchValidationService_ValidateTextTimedOutReason(t *testing.T) {
+	rv := new(mocks.MockEmailRuleValidator)
+	dv := new(mocks.MockDomainValidationService)
+	mc := new(mocks.MockMetricsCollector)
+
+	rv.On("ValidateSyntax", mock.Anything).Return(true)
+	rv.On("IsRoleBased", mock.Anything).Return(false)
+	rv.On("DetectAlias", mock.Anything).Return("")
+	rv.On("GetTypoSuggestions", mock.Anything, mock.Anything).Return([]string{})
+	// The lookups hang until the deadline of the request
+	dv.On("ValidateDomainConcurrently", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { <-args.Get(0).(context.Context).Done() }).
+		Return(false, false, false)
+	mc.On("RecordValidationScore", "overall", mock.Anything)
+	svc := service.NewBatchValidationService(rv, dv, mc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var out bytes.Buffer
+	summary, err := svc.ValidateText(ctx, strings.NewReader("ada@example.com\n"), &out)
+	require.NoError(t, err)
+	assert.Equal(t, map[model.ValidationStatus]int{model.ValidationStatusUnknown: 1}, summary.StatusCounts)
+	// UNKNOWN because of the deadline, not because the mail server deferred the check
+	assert.Equal(t, "email,status,score,reason\n"+
+		"ada@example.com,UNKNOWN,40,validation did not finish before the deadline\n", out.String())
+}
@@ -0,0 +1,189 @@
+// Package servicetest contains unit tests for the asynchronous job service
+package servicetest
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"emailvalidator/internal/config"
+	"emailvalidator/internal/model"
+	"emailvalidator/internal/service"
+	"emailvalidator/pkg/cache"
+	"emailvalidator/tests/unit/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newJobTestBatchService returns a batch service whose dependencies rate every email VALID.
+// block, when set, is called before each domain validation.
+func newJobTestBatchService(block func()) *service.BatchValidationService {
+	rv := new(mocks.MockEmailRuleValidator)
+	dv := new(mocks.MockDomainValidationService)
+	mc := new(mocks.MockMetricsCollector)
+
+	rv.On("ValidateSyntax", mock.Anything).Return(true)
+	rv.On("IsRoleBased", mock.Anything).Return(false)
+	rv.On("DetectAlias", mock.Anything).Return("")
+	rv.On("GetTypoSuggestions", mock.Anything, mock.Anything).Return([]string{})
+	call := dv.On("ValidateDomainConcurrently", mock.Anything, mock.Anything).Return(true, true, false)
+	if block != nil {
+		call.Run(func(mock.Arguments) { block() })
+	}
+	mc.On("RecordValidationScore", "overall", float64(100))
+
+	return service.NewBatchValidationService(rv, dv, mc)
+}
+
+func jobTestEmails(n int) []string {
+	emails := make([]string, n)
+	for i := range emails {
+		emails[i] = fmt.Sprintf("user%d@example.com", i)
+	}
+	return emails
+}
+
+// waitForJob polls the job until it leaves the queued and running states
+func waitForJob(t *testing.T, svc *service.JobService, id string) model.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := svc.Get(context.Background(), id)
+		require.NoError(t, err)
+		if job.Status == model.JobStatusCompleted || job.Status == model.JobStatusFailed {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish in time", id)
+	return model.Job{}
+}
+
+func TestJobService_ProcessesJob(t *testing.T) {
+	jobConfig := config.Default().Jobs
+	stores := map[string]service.JobStore{
+		"memory": service.NewMemoryJobStore(jobConfig.TTL),
+		"cache":  service.NewCacheJobStore(cache.NewMockCache(), jobConfig.TTL),
+	}
+
+	for name, store := range stores {
+		store := store
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewJobService(newJobTestBatchService(nil), store, jobConfig)
+			emails := jobTestEmails(250)
+			ctx := context.Background()
+
+			job, err := svc.Submit(ctx, emails)
+			require.NoError(t, err)
+			assert.NotEmpty(t, job.ID)
+			assert.Equal(t, model.JobStatusQueued, job.Status)
+			assert.Equal(t, 250, job.Total)
+
+			job = waitForJob(t, svc, job.ID)
+			assert.Equal(t, model.JobStatusCompleted, job.Status)
+			assert.Equal(t, 250, job.Processed)
+			assert.Equal(t, map[model.ValidationStatus]int{model.ValidationStatusValid: 250}, job.StatusCounts)
+			assert.NotNil(t, job.CompletedAt)
+
+			// A page spanning two stored chunks
+			page, err := svc.Results(ctx, job.ID, 90, 20)
+			require.NoError(t, err)
+			require.Len(t, page.Results, 20)
+			assert.Equal(t, emails[90], page.Results[0].Email)
+			assert.Equal(t, emails[109], page.Results[19].Email)
+			require.NotNil(t, page.NextOffset)
+			assert.Equal(t, 110, *page.NextOffset)
+
+			// The last page has no next offset
+			page, err = svc.Results(ctx, job.ID, 240, 0)
+			require.NoError(t, err)
+			assert.Equal(t, 100, page.Limit)
+			require.Len(t, page.Results, 10)
+			assert.Equal(t, emails[249], page.Results[9].Email)
+			assert.Nil(t, page.NextOffset)
+		})
+	}
+}
+
+func TestJobService_RejectsInvalidRequests(t *testing.T) {
+	jobConfig := config.Default().Jobs
+	jobConfig.MaxEmails = 10
+	svc := service.NewJobService(newJobTestBatchService(nil), service.NewMemoryJobStore(jobConfig.TTL), jobConfig)
+	ctx := context.Background()
+
+	_, err := svc.Submit(ctx, nil)
+	assert.ErrorIs(t, err, service.ErrNoEmails)
+
+	_, err = svc.Submit(ctx, jobTestEmails(11))
+	assert.ErrorIs(t, err, service.ErrTooManyEmails)
+
+	_, err = svc.Get(ctx, "missing")
+	assert.ErrorIs(t, err, service.ErrJobNotFound)
+
+	_, err = svc.Results(ctx, "missing", 0, 10)
+	assert.ErrorIs(t, err, service.ErrJobNotFound)
+}
+
+// recordingJobStore is a MemoryJobStore that records the IDs of the jobs saved in it
+type recordingJobStore struct {
+	*service.MemoryJobStore
+	mu    sync.Mutex
+	saved map[string]bool
+}
+
+func (s *recordingJobStore) SaveJob(ctx context.Context, job model.Job) error {
+	s.mu.Lock()
+	s.saved[job.ID] = true
+	s.mu.Unlock()
+	return s.MemoryJobStore.SaveJob(ctx, job)
+}
+
+func (s *recordingJobStore) savedIDs() map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.saved)
+}
+
+func TestJobService_QueueFull(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	block := func() {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+	}
+
+	jobConfig := config.JobConfig{Workers: 1, QueueSize: 1, TTL: time.Hour}
+	store := &recordingJobStore{MemoryJobStore: service.NewMemoryJobStore(jobConfig.TTL), saved: make(map[string]bool)}
+	svc := service.NewJobService(newJobTestBatchService(block), store, jobConfig)
+	ctx := context.Background()
+
+	// The first job occupies the only worker
+	running, err := svc.Submit(ctx, jobTestEmails(1))
+	require.NoError(t, err)
+	<-started
+
+	// The second job waits in the queue, the third has nowhere to go
+	queued, err := svc.Submit(ctx, jobTestEmails(1))
+	require.NoError(t, err)
+	rejected, err := svc.Submit(ctx, jobTestEmails(1))
+	assert.ErrorIs(t, err, service.ErrJobQueueFull)
+	assert.Empty(t, rejected.ID)
+	// The rejected job left nothing in the store
+	assert.Equal(t, map[string]bool{running.ID: true, queued.ID: true}, store.savedIDs())
+
+	job, err := svc.Get(ctx, running.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusRunning, job.Status)
+
+	close(release)
+	assert.Equal(t, model.JobStatusCompleted, waitForJob(t, svc, running.ID).Status)
+	assert.Equal(t, model.JobStatusCompleted, waitForJob(t, svc, queued.ID).Status)
+}
@@ -4,6 +4,8 @@ package mocks
 import (
 	"context"
 
+	"emailvalidator/pkg/validator"
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -27,8 +29,8 @@ func (m *MockEmailRuleValidator) CalculateScore(validations map[string]bool) int
 	return args.Int(0)
 }
 
-func (m *MockEmailRuleValidator) GetTypoSuggestions(email string) []string {
-	args := m.Called(email)
+func (m *MockEmailRuleValidator) GetTypoSuggestions(ctx context.Context, email string) []string {
+	args := m.Called(ctx, email)
 	return args.Get(0).([]string)
 }
 
@@ -42,13 +44,13 @@ type MockDomainValidator struct {
 	mock.Mock
 }
 
-func (m *MockDomainValidator) ValidateDomain(domain string) bool {
-	args := m.Called(domain)
+func (m *MockDomainValidator) ValidateDomain(ctx context.Context, domain string) bool {
+	args := m.Called(ctx, domain)
 	return args.Bool(0)
 }
 
-func (m *MockDomainValidator) ValidateMXRecords(domain string) bool {
-	args := m.Called(domain)
+func (m *MockDomainValidator) ValidateMXRecords(ctx context.Context, domain string) bool {
+	args := m.Called(ctx, domain)
 	return args.Bool(0)
 }
 
@@ -79,3 +81,13 @@ func (m *MockMetricsCollector) RecordValidationScore(name string, score float64)
 func (m *MockMetricsCollector) UpdateMemoryUsage(heapInUse, stackInUse float64) {
 	m.Called(heapInUse, stackInUse)
 }
+
+// MockMailboxVerifier mocks the MailboxVerifier interface
+type MockMailboxVerifier struct {
+	mock.Mock
+}
+
+func (m *MockMailboxVerifier) VerifyMailbox(ctx context.Context, email string) validator.SMTPResult {
+	args := m.Called(ctx, email)
+	return args.Get(0).(validator.SMTPResult)
+}
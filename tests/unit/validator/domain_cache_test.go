@@ -0,0 +1,336 @@
+package validatortest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"emailvalidator/pkg/cache"
+	"emailvalidator/pkg/validator"
+)
+
+// failingCache implements cache.Cache and fails every operation, like an unreachable Redis
+type failingCache struct {
+	calls int
+}
+
+func (c *failingCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.calls++
+	return errors.New("connection refused")
+}
+
+func (c *failingCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	c.calls++
+	return errors.New("connection refused")
+}
+
+func (c *failingCache) Delete(ctx context.Context, key string) error {
+	c.calls++
+	return errors.New("connection refused")
+}
+
+func (c *failingCache) Close() error {
+	return nil
+}
+
+func TestDomainCacheManagerSharedTier(t *testing.T) {
+	t.Parallel()
+
+	shared := cache.NewMockCache()
+
+	// Two managers sharing one remote cache behave like two replicas
+	first := validator.NewDomainCacheManager(time.Hour)
+	first.SetRemoteCache(shared)
+	second := validator.NewDomainCacheManager(time.Hour)
+	second.SetRemoteCache(shared)
+
+	first.Set("example.com", true)
+	first.SetMX("example.com", false)
+	first.SetCatchAll("example.com", true)
+
+	if exists, found := second.Get("example.com"); !found || !exists {
+		t.Errorf("Get() = (%v, %v), want (true, true) from the shared cache", exists, found)
+	}
+	if hasMX, found := second.GetMX("example.com"); !found || hasMX {
+		t.Errorf("GetMX() = (%v, %v), want (false, true) from the shared cache", hasMX, found)
+	}
+	if catchAll, found := second.GetCatchAll("example.com"); !found || !catchAll {
+		t.Errorf("GetCatchAll() = (%v, %v), want (true, true) from the shared cache", catchAll, found)
+	}
+	if _, found := second.Get("unknown.com"); found {
+		t.Error("Get() found a domain that was never cached")
+	}
+}
+
+func TestDomainCacheManagerSharedTierUnavailable(t *testing.T) {
+	t.Parallel()
+
+	remote := &failingCache{}
+	manager := validator.NewDomainCacheManager(time.Hour)
+	manager.SetRemoteCache(remote)
+
+	manager.Set("example.com", true)
+	if exists, found := manager.Get("example.com"); !found || !exists {
+		t.Errorf("Get() = (%v, %v), want (true, true) from the in-process cache", exists, found)
+	}
+	if _, found := manager.GetMX("example.com"); found {
+		t.Error("GetMX() found an entry that was never cached")
+	}
+
+	// After the first failure the shared cache is bypassed instead of being hit on every lookup
+	if remote.calls != 1 {
+		t.Errorf("Remote cache calls = %d, want 1", remote.calls)
+	}
+}
+
+func TestDomainValidatorCachesMXResults(t *testing.T) {
+	t.Parallel()
+
+	resolver := NewMockResolver()
+	cacheManager := validator.NewDomainCacheManager(time.Hour)
+	domainValidator := validator.NewDomainValidator(resolver, cacheManager)
+
+	if !domainValidator.ValidateMX(context.Background(), "example.com") {
+		t.Fatal("Expected example.com to have MX records")
+	}
+	if hasMX, found := cacheManager.GetMX("example.com"); !found || !hasMX {
+		t.Errorf("GetMX() = (%v, %v), want (true, true) after validation", hasMX, found)
+	}
+}
+
+func TestDomainCacheManagerTTLs(t *testing.T) {
+	t.Parallel()
+
+	manager := validator.NewDomainCacheManager(time.Hour)
+	manager.SetTTLConfig(validator.CacheTTLConfig{
+		MinTTL:         time.Hour,
+		MaxTTL:         2 * time.Hour,
+		NegativeMaxTTL: 20 * time.Millisecond,
+	})
+
+	// A zero TTL is raised to the positive minimum
+	manager.SetWithTTL("found.com", true, "", 0)
+	// NXDOMAIN with a long SOA minimum is cut to the negative maximum
+	manager.SetWithTTL("missing.com", false, validator.ReasonNXDomain, time.Hour)
+	// Without a TTL the cache duration is used, within the negative bounds too
+	manager.SetMXWithReason("nomx.com", false, validator.ReasonNoMXRecords)
+	manager.SetMXAnswer("mail.com", validator.MXAnswer{Records: []validator.MXRecord{{Host: "mx.mail.com.", Pref: 10}}}, validator.UnknownTTL)
+
+	time.Sleep(50 * time.Millisecond)
+
+	if exists, found := manager.Get("found.com"); !found || !exists {
+		t.Errorf("Get(found.com) = (%v, %v), want (true, true) within the positive minimum", exists, found)
+	}
+	if hasMX, found := manager.GetMX("mail.com"); !found || !hasMX {
+		t.Errorf("GetMX(mail.com) = (%v, %v), want (true, true) for the cache duration", hasMX, found)
+	}
+	if _, found := manager.Get("missing.com"); found {
+		t.Error("Get(missing.com) found an entry past the negative maximum")
+	}
+	if _, found := manager.GetMX("nomx.com"); found {
+		t.Error("GetMX(nomx.com) found an entry past the negative maximum")
+	}
+
+	disabled := validator.NewDomainCacheManager(0)
+	disabled.SetWithTTL("found.com", true, "", time.Hour)
+	if _, found := disabled.Get("found.com"); found {
+		t.Error("Get() found an entry with caching disabled")
+	}
+}
+
+func TestDomainValidatorCachesForAnswerTTL(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeDNSServer(t)
+	cacheManager := validator.NewDomainCacheManager(time.Hour)
+	cacheManager.SetTTLConfig(validator.CacheTTLConfig{NegativeMaxTTL: time.Hour})
+	domainValidator := validator.NewDomainValidator(newTestUpstreamResolver(t, server.addr()), cacheManager)
+
+	domainValidator.CheckMX(context.Background(), "nomx.net")
+	domainValidator.CheckMX(context.Background(), "nomx.net")
+	if queries := server.udpQueries.Load(); queries != 1 {
+		t.Errorf("UDP queries = %d, want 1 while the negative answer is cached", queries)
+	}
+}
+
+// recoveringResolver fails the first lookup of each record type with err and answers the ones after it
+type recoveringResolver struct {
+	err         error
+	hostLookups atomic.Int32
+	mxLookups   atomic.Int32
+}
+
+func (r *recoveringResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	if r.hostLookups.Add(1) == 1 {
+		return nil, validator.UnknownTTL, r.err
+	}
+	return []string{"192.0.2.1"}, validator.UnknownTTL, nil
+}
+
+func (r *recoveringResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
+	if r.mxLookups.Add(1) == 1 {
+		return nil, validator.UnknownTTL, r.err
+	}
+	return []*net.MX{{Host: "mail.company.com.", Pref: 10}}, validator.UnknownTTL, nil
+}
+
+func TestDomainValidatorSkipsCachingTransientFailures(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantReason string
+	}{
+		{
+			name:       "Timeout",
+			err:        &net.DNSError{Err: "i/o timeout", Name: "company.com", IsTimeout: true},
+			wantReason: validator.ReasonDNSTimeout,
+		},
+		{
+			name:       "SERVFAIL",
+			err:        &net.DNSError{Err: "server misbehaving", Name: "company.com", IsTemporary: true},
+			wantReason: validator.ReasonServFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &recoveringResolver{err: tt.err}
+			cacheManager := validator.NewDomainCacheManager(time.Hour)
+			cacheManager.SetTTLConfig(validator.CacheTTLConfig{NegativeMinTTL: time.Hour})
+			domainValidator := validator.NewDomainValidator(resolver, cacheManager)
+
+			if exists, reason := domainValidator.Check(context.Background(), "company.com"); exists || reason != tt.wantReason {
+				t.Errorf("first Check() = %v, %q, want false, %q", exists, reason, tt.wantReason)
+			}
+			if exists, _ := domainValidator.Check(context.Background(), "company.com"); !exists {
+				t.Error("Check() after the failure = false, want the domain found by the next lookup")
+			}
+
+			if hasMX, reason := domainValidator.CheckMX(context.Background(), "company.com"); hasMX || reason != tt.wantReason {
+				t.Errorf("first CheckMX() = %v, %q, want false, %q", hasMX, reason, tt.wantReason)
+			}
+			if hasMX, _ := domainValidator.CheckMX(context.Background(), "company.com"); !hasMX {
+				t.Error("CheckMX() after the failure = false, want the MX records found by the next lookup")
+			}
+
+			// The answers that followed are cached as usual
+			domainValidator.Check(context.Background(), "company.com")
+			domainValidator.CheckMX(context.Background(), "company.com")
+			if hosts, mxs := resolver.hostLookups.Load(), resolver.mxLookups.Load(); hosts != 2 || mxs != 2 {
+				t.Errorf("lookups = %d host, %d MX, want 2 each", hosts, mxs)
+			}
+		})
+	}
+}
+
+func TestDomainValidatorCachesMXAnswers(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeDNSServer(t)
+	shared := cache.NewMockCache()
+	cacheManager := validator.NewDomainCacheManager(time.Hour)
+	cacheManager.SetRemoteCache(shared)
+	domainValidator := validator.NewDomainValidator(newTestUpstreamResolver(t, server.addr()), cacheManager)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		answer := domainValidator.ResolveMX(ctx, "acme.io")
+		if !answer.HasMX() || len(answer.Records) != 2 ||
+			answer.Records[0] != (validator.MXRecord{Host: "mail.acme.io.", Pref: 10}) ||
+			answer.Records[1] != (validator.MXRecord{Host: "backup.acme.io.", Pref: 20}) {
+			t.Fatalf("ResolveMX(acme.io) = %+v, want mail.acme.io. then backup.acme.io.", answer)
+		}
+	}
+	if queries := server.udpQueries.Load(); queries != 1 {
+		t.Errorf("UDP queries = %d, want 1 with the answer cached", queries)
+	}
+
+	answer := domainValidator.ResolveMX(ctx, "nullmx.net")
+	if answer.HasMX() || !answer.NullMX || answer.Reason != validator.ReasonNullMX {
+		t.Errorf("ResolveMX(nullmx.net) = %+v, want a null MX", answer)
+	}
+
+	// Another replica gets the full answer from the shared cache
+	replica := validator.NewDomainCacheManager(time.Hour)
+	replica.SetRemoteCache(shared)
+	cached, found := replica.GetMXAnswer("acme.io")
+	if !found || len(cached.Records) != 2 || cached.Records[0].Host != "mail.acme.io." {
+		t.Errorf("GetMXAnswer() = %+v, %v, want the answer from the shared cache", cached, found)
+	}
+	if cached, found := replica.GetMXAnswer("nullmx.net"); !found || !cached.NullMX {
+		t.Errorf("GetMXAnswer(nullmx.net) = %+v, %v, want a null MX from the shared cache", cached, found)
+	}
+}
+
+// gatedResolver counts lookups and holds each one until release is closed
+type gatedResolver struct {
+	release     chan struct{}
+	hostLookups atomic.Int32
+	mxLookups   atomic.Int32
+}
+
+func (r *gatedResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	r.hostLookups.Add(1)
+	<-r.release
+	return []string{"192.0.2.1"}, validator.UnknownTTL, nil
+}
+
+func (r *gatedResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
+	r.mxLookups.Add(1)
+	<-r.release
+	return []*net.MX{{Host: "mail.company.com.", Pref: 10}}, validator.UnknownTTL, nil
+}
+
+func TestDomainValidatorCoalescesLookups(t *testing.T) {
+	resolver := &gatedResolver{release: make(chan struct{})}
+	domainValidator := validator.NewDomainValidator(resolver, validator.NewDomainCacheManager(time.Hour))
+
+	// A caller giving up doesn't fail the lookup the others are waiting for
+	ctx, cancel := context.WithCancel(context.Background())
+	abandoned := make(chan string)
+	go func() {
+		_, reason := domainValidator.CheckMX(ctx, "company.com")
+		abandoned <- reason
+	}()
+
+	const callers = 50
+	var wg sync.WaitGroup
+	var failures atomic.Int32
+	for i := 0; i < callers; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if !domainValidator.Validate(context.Background(), "company.com") {
+				failures.Add(1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if !domainValidator.ValidateMX(context.Background(), "company.com") {
+				failures.Add(1)
+			}
+		}()
+	}
+
+	// Let every caller reach the lookup in flight before it is answered
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	if reason := <-abandoned; reason != validator.ReasonDNSTimeout {
+		t.Errorf("CheckMX() after cancel reason = %q, want %q", reason, validator.ReasonDNSTimeout)
+	}
+	close(resolver.release)
+	wg.Wait()
+
+	if failures.Load() != 0 {
+		t.Errorf("%d callers got a failed result from the shared lookup", failures.Load())
+	}
+	if host, mx := resolver.hostLookups.Load(), resolver.mxLookups.Load(); host != 1 || mx != 1 {
+		t.Errorf("Resolver lookups = %d host, %d MX, want 1 of each", host, mx)
+	}
+}
@@ -0,0 +1,70 @@
+package validatortest
+
+import (
+	"errors"
+	"testing"
+
+	"emailvalidator/pkg/validator"
+)
+
+func TestFreeProviderValidatorWithFileReader(t *testing.T) {
+	// Create a file reader with the config file
+	reader := validator.NewFileDomainReader("../../../config/free_email_providers.txt")
+
+	// Create validator with the file reader
+	v, err := validator.NewFreeProviderValidatorWithReader(reader)
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+
+	// Test cases
+	tests := []struct {
+		domain string
+		want   bool
+	}{
+		{"gmail.com", true},
+		{"yahoo.com", true},
+		{"outlook.com", true},
+		{"Gmail.COM", true},
+		{"acme-corp.io", false},
+		{"company.io", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			got := v.Validate(tt.domain)
+			if got != tt.want {
+				t.Errorf("Validate(%q) = %v, want %v", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeProviderValidatorWithMockReader(t *testing.T) {
+	v, err := validator.NewFreeProviderValidatorWithReader(NewMockDomainReader([]string{"free.example"}, nil))
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	if !v.Validate("free.example") {
+		t.Error("Validate(\"free.example\") = false, want true")
+	}
+
+	readErr := errors.New("read failed")
+	if _, err := validator.NewFreeProviderValidatorWithReader(NewMockDomainReader(nil, readErr)); !errors.Is(err, readErr) {
+		t.Errorf("Expected reader error %v, got %v", readErr, err)
+	}
+}
+
+func TestEmailValidatorIsFreeProvider(t *testing.T) {
+	emailValidator, err := validator.NewEmailValidator()
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+
+	if !emailValidator.IsFreeProvider("gmail.com") {
+		t.Error("IsFreeProvider(\"gmail.com\") = false, want true")
+	}
+	if emailValidator.IsFreeProvider("acme-corp.io") {
+		t.Error("IsFreeProvider(\"acme-corp.io\") = true, want false")
+	}
+}
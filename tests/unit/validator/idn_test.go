@@ -0,0 +1,66 @@
+package validatortest
+
+import (
+	"testing"
+
+	"emailvalidator/pkg/validator"
+)
+
+func TestToASCIIDomain(t *testing.T) {
+	tests := []struct {
+		domain  string
+		want    string
+		wantErr bool
+	}{
+		{"example.com", "example.com", false},
+		{"EXAMPLE.COM.", "example.com", false},
+		{"bücher.de", "xn--bcher-kva.de", false},
+		{"BÜCHER.de", "xn--bcher-kva.de", false},
+		// IDNA2008 keeps ß instead of mapping it to ss as IDNA2003 did
+		{"straße.de", "xn--strae-oqa.de", false},
+		{"пример.рф", "xn--e1afmkfd.xn--p1ai", false},
+		{"xn--bcher-kva.de", "xn--bcher-kva.de", false},
+		{"exa_mple.com", "", true},
+		{"-example.com", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			got, err := validator.ToASCIIDomain(tt.domain)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToASCIIDomain(%q) error = %v, wantErr %v", tt.domain, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ToASCIIDomain(%q) = %q, want %q", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailForms(t *testing.T) {
+	tests := []struct {
+		email       string
+		wantUnicode string
+		wantASCII   string
+		wantUTF8    bool
+	}{
+		{"user@example.com", "user@example.com", "user@example.com", false},
+		{"user@bücher.de", "user@bücher.de", "user@xn--bcher-kva.de", false},
+		{"user@xn--bcher-kva.de", "user@bücher.de", "user@xn--bcher-kva.de", false},
+		{"José@bücher.de", "José@bücher.de", "José@xn--bcher-kva.de", true},
+		{"用户@例子.广告", "用户@例子.广告", "用户@xn--fsqu00a.xn--4rr70v", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			unicodeForm, asciiForm, ok := validator.EmailForms(tt.email)
+			if !ok || unicodeForm != tt.wantUnicode || asciiForm != tt.wantASCII {
+				t.Errorf("EmailForms(%q) = %q, %q, %v, want %q, %q, true",
+					tt.email, unicodeForm, asciiForm, ok, tt.wantUnicode, tt.wantASCII)
+			}
+			if got := validator.RequiresSMTPUTF8(tt.email); got != tt.wantUTF8 {
+				t.Errorf("RequiresSMTPUTF8(%q) = %v, want %v", tt.email, got, tt.wantUTF8)
+			}
+		})
+	}
+}
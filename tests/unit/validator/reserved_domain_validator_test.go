@@ -0,0 +1,56 @@
+package validatortest
+
+import (
+	"testing"
+
+	"emailvalidator/pkg/validator"
+)
+
+func TestReservedDomainValidator(t *testing.T) {
+	v := validator.NewReservedDomainValidator()
+
+	tests := []struct {
+		domain string
+		want   bool
+	}{
+		{"example.com", true},
+		{"mail.example.org", true},
+		{"EXAMPLE.NET.", true},
+		{"example", true},
+		{"mail.test", true},
+		{"x.invalid", true},
+		{"localhost", true},
+		{"host.localhost", true},
+		{"printer.local", true},
+		{"facebookcorewwwi.onion", true},
+		{"gmail.com", false},
+		{"example.co.uk", false},
+		{"myexample.com", false},
+		{"testing.io", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			if got := v.Validate(tt.domain); got != tt.want {
+				t.Errorf("Validate(%q) = %v, want %v", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReservedDomainValidatorAllow(t *testing.T) {
+	v := validator.NewReservedDomainValidator()
+	v.Allow(".TEST")
+
+	if v.Validate("mail.test") {
+		t.Error("Validate(\"mail.test\") = true after allowing test, want false")
+	}
+	if !v.Validate("example.com") {
+		t.Error("Validate(\"example.com\") = false, want true")
+	}
+
+	custom := validator.NewReservedDomainValidatorWithDomains([]string{"internal.corp"})
+	if !custom.Validate("mail.internal.corp") || custom.Validate("example.com") {
+		t.Error("custom domains should replace the default reserved domains")
+	}
+}
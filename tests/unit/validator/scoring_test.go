@@ -0,0 +1,91 @@
+package validatortest
+
+import (
+	"strings"
+	"testing"
+
+	"emailvalidator/pkg/validator"
+)
+
+func TestParseScoringProfiles(t *testing.T) {
+	data := `{
+		"strict": {"thresholds": {"valid": 95, "probably_valid": 80}},
+		"no-roles": {"weights": {"syntax": 30, "is_role_based": 0}, "penalties": {"typo": 5}}
+	}`
+
+	profiles, err := validator.ParseScoringProfiles(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseScoringProfiles() error = %v", err)
+	}
+
+	strict := profiles["strict"]
+	if strict.Thresholds.Valid != 95 || strict.Thresholds.ProbablyValid != 80 {
+		t.Errorf("strict thresholds = %+v, want 95/80", strict.Thresholds)
+	}
+	if strict.Penalties != validator.DefaultScoringProfile().Penalties || strict.NoMXScore != 40 {
+		t.Errorf("strict should keep the default penalties and no-MX score, got %+v", strict)
+	}
+
+	noRoles := profiles["no-roles"]
+	if noRoles.Weights["syntax"] != 30 || noRoles.Weights["is_role_based"] != 0 || noRoles.Weights["mx_records"] != 20 {
+		t.Errorf("no-roles weights = %v, want syntax 30, is_role_based 0 and the other defaults", noRoles.Weights)
+	}
+	if noRoles.Penalties.Typo != 5 || noRoles.Penalties.CatchAll != 15 {
+		t.Errorf("no-roles penalties = %+v, want typo 5 and the other defaults", noRoles.Penalties)
+	}
+
+	invalid := []string{
+		`{"bad": {"weights": {"syntax": 30}}}`,
+		`{"bad": {"weights": {"syntax": 10, "is_free": 10}}}`,
+		`{"bad": {"thresholds": {"valid": 60, "probably_valid": 70}}}`,
+		`{"bad": {"penalties": {"typo": -1}}}`,
+		`{"bad": {"treshold": 10}}`,
+		`[]`,
+	}
+	for _, data := range invalid {
+		if _, err := validator.ParseScoringProfiles(strings.NewReader(data)); err == nil {
+			t.Errorf("ParseScoringProfiles(%s) should fail", data)
+		}
+	}
+}
+
+func TestScoringProfileScore(t *testing.T) {
+	profile := validator.DefaultScoringProfile()
+	validations := map[string]bool{
+		"syntax":         true,
+		"domain_exists":  true,
+		"mx_records":     true,
+		"mailbox_exists": false,
+		"is_disposable":  false,
+		"is_role_based":  true,
+	}
+	if got := profile.Score(validations); got != 70 {
+		t.Errorf("Score() = %d, want 70", got)
+	}
+}
+
+func TestNewScoringPolicy(t *testing.T) {
+	policy, err := validator.NewScoringPolicy()
+	if err != nil {
+		t.Fatalf("Failed to load scoring profiles: %v", err)
+	}
+
+	want := []string{"default", "lenient-newsletter", "strict-signup"}
+	if got := policy.Names(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+
+	if err := policy.SetDefaultProfile("strict-signup"); err != nil {
+		t.Fatalf("SetDefaultProfile() error = %v", err)
+	}
+	profile, ok := policy.Profile("")
+	if !ok || profile.Thresholds.Valid != 95 {
+		t.Errorf("Profile(\"\") = %+v, want the strict-signup profile", profile)
+	}
+	if err := policy.SetDefaultProfile("missing"); err == nil {
+		t.Error("SetDefaultProfile() with an unknown profile should fail")
+	}
+	if _, ok := policy.Profile("missing"); ok {
+		t.Error("Profile(\"missing\") should not be found")
+	}
+}
@@ -0,0 +1,478 @@
+package validatortest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"emailvalidator/pkg/validator"
+)
+
+// fakeSMTPServer is a minimal in-process SMTP server for exercising the verifier
+type fakeSMTPServer struct {
+	listener net.Listener
+	// rcptReply returns the reply code and text for a RCPT TO address
+	rcptReply func(address string) (int, string)
+	// silent makes the server accept connections without ever greeting
+	silent bool
+	// smtputf8 makes the server announce the SMTPUTF8 extension
+	smtputf8 bool
+
+	mu       sync.Mutex
+	commands []string
+}
+
+func newFakeSMTPServer(t *testing.T, rcptReply func(address string) (int, string)) *fakeSMTPServer {
+	t.Helper()
+	return startFakeSMTPServer(t, &fakeSMTPServer{rcptReply: rcptReply})
+}
+
+func startFakeSMTPServer(t *testing.T, server *fakeSMTPServer) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start fake SMTP server: %v", err)
+	}
+
+	server.listener = listener
+	go server.serve()
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	return server
+}
+
+func (s *fakeSMTPServer) port() string {
+	return fmt.Sprint(s.listener.Addr().(*net.TCPAddr).Port)
+}
+
+func (s *fakeSMTPServer) recorded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if s.silent {
+		_, _ = bufio.NewReader(conn).ReadString('\n')
+		return
+	}
+
+	reader := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) {
+		_, _ = fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	reply("220 fake.test ESMTP ready")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		s.mu.Lock()
+		s.commands = append(s.commands, line)
+		s.mu.Unlock()
+
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO":
+			reply("250-fake.test")
+			if s.smtputf8 {
+				reply("250-SMTPUTF8")
+			}
+			reply("250 8BITMIME")
+		case "HELO", "MAIL", "RSET", "NOOP":
+			reply("250 OK")
+		case "RCPT":
+			address := line[strings.Index(line, "<")+1 : strings.LastIndex(line, ">")]
+			code, text := s.rcptReply(address)
+			reply("%d %s", code, text)
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// smtpTestResolver resolves every domain to the given MX records
+type smtpTestResolver struct {
+	mx []*net.MX
+}
+
+func (r *smtpTestResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	return []string{"127.0.0.1"}, validator.UnknownTTL, nil
+}
+
+func (r *smtpTestResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
+	return r.mx, validator.UnknownTTL, nil
+}
+
+func newTestSMTPVerifier(server *fakeSMTPServer, config validator.SMTPConfig) *validator.SMTPVerifier {
+	resolver := &smtpTestResolver{mx: []*net.MX{{Host: "127.0.0.1.", Pref: 10}}}
+	config.Port = server.port()
+	return validator.NewSMTPVerifier(resolver, validator.NewDomainCacheManager(time.Hour), config)
+}
+
+func TestSMTPVerifierReplyCodes(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeSMTPServer(t, func(address string) (int, string) {
+		switch address {
+		case "known@example.com":
+			return 250, "2.1.5 OK"
+		case "full@example.com":
+			return 452, "4.2.2 Mailbox full"
+		default:
+			return 550, "5.1.1 No such user"
+		}
+	})
+	verifier := newTestSMTPVerifier(server, validator.SMTPConfig{})
+
+	tests := []struct {
+		name             string
+		email            string
+		wantExists       bool
+		wantCode         int
+		wantInconclusive bool
+	}{
+		{
+			name:       "Accepted recipient",
+			email:      "known@example.com",
+			wantExists: true,
+			wantCode:   250,
+		},
+		{
+			name:       "Unknown recipient",
+			email:      "unknown@example.com",
+			wantExists: false,
+			wantCode:   550,
+		},
+		{
+			name:             "Temporary failure",
+			email:            "full@example.com",
+			wantExists:       false,
+			wantCode:         452,
+			wantInconclusive: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := verifier.Verify(context.Background(), tt.email)
+			if result.Err != nil {
+				t.Fatalf("Verify(%q) returned error: %v", tt.email, result.Err)
+			}
+			if result.Exists != tt.wantExists {
+				t.Errorf("Exists = %v, want %v", result.Exists, tt.wantExists)
+			}
+			if result.Code != tt.wantCode {
+				t.Errorf("Code = %d, want %d", result.Code, tt.wantCode)
+			}
+			if result.Inconclusive() != tt.wantInconclusive {
+				t.Errorf("Inconclusive() = %v, want %v", result.Inconclusive(), tt.wantInconclusive)
+			}
+			if result.MXHost != "127.0.0.1" {
+				t.Errorf("MXHost = %q, want %q", result.MXHost, "127.0.0.1")
+			}
+		})
+	}
+}
+
+func TestSMTPVerifierDialogue(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeSMTPServer(t, func(address string) (int, string) {
+		if address == "user@example.com" {
+			return 250, "OK"
+		}
+		return 550, "No such user"
+	})
+	verifier := newTestSMTPVerifier(server, validator.SMTPConfig{
+		HeloName: "verifier.example.org",
+		MailFrom: "probe@example.org",
+	})
+
+	if result := verifier.Verify(context.Background(), "user@example.com"); !result.Exists {
+		t.Fatalf("Expected mailbox to exist, got %+v", result)
+	}
+
+	commands := server.recorded()
+	want := []string{
+		"EHLO verifier.example.org",
+		"MAIL FROM:<probe@example.org> BODY=8BITMIME",
+		"RCPT TO:<user@example.com>",
+		"RCPT TO:<", // catch-all probe with a random local part
+		"QUIT",
+	}
+	if len(commands) != len(want) {
+		t.Fatalf("Commands = %q, want %q", commands, want)
+	}
+	for i := range want {
+		if !strings.HasPrefix(commands[i], want[i]) {
+			t.Errorf("Command %d = %q, want prefix %q", i, commands[i], want[i])
+		}
+	}
+	for _, command := range commands {
+		if strings.HasPrefix(command, "DATA") {
+			t.Errorf("Verifier must never send DATA")
+		}
+	}
+}
+
+func TestSMTPVerifierSMTPUTF8(t *testing.T) {
+	t.Parallel()
+
+	accept := func(string) (int, string) { return 250, "OK" }
+
+	// Without SMTPUTF8 a UTF-8 local part must not be sent at all
+	plain := newFakeSMTPServer(t, accept)
+	result := newTestSMTPVerifier(plain, validator.SMTPConfig{HeloName: "verifier.example.org"}).Verify(context.Background(), "José@bücher.de")
+	if result.Err != validator.ErrSMTPUTF8Unsupported || !result.Inconclusive() {
+		t.Errorf("Verify without SMTPUTF8 = %+v, want ErrSMTPUTF8Unsupported", result)
+	}
+	for _, command := range plain.recorded() {
+		if strings.HasPrefix(command, "MAIL") || strings.HasPrefix(command, "RCPT") {
+			t.Errorf("Unexpected command %q to a server without SMTPUTF8", command)
+		}
+	}
+
+	// With SMTPUTF8 the transaction requests it and the domain is sent in ASCII form
+	utf8Server := startFakeSMTPServer(t, &fakeSMTPServer{rcptReply: accept, smtputf8: true})
+	result = newTestSMTPVerifier(utf8Server, validator.SMTPConfig{HeloName: "verifier.example.org"}).Verify(context.Background(), "José@bücher.de")
+	if !result.Exists {
+		t.Fatalf("Verify with SMTPUTF8 = %+v, want an existing mailbox", result)
+	}
+	commands := utf8Server.recorded()
+	if len(commands) < 3 || !strings.Contains(commands[1], "SMTPUTF8") || commands[2] != "RCPT TO:<José@xn--bcher-kva.de>" {
+		t.Errorf("Commands = %q, want MAIL with SMTPUTF8 and RCPT TO:<José@xn--bcher-kva.de>", commands)
+	}
+}
+
+func TestSMTPVerifierCatchAllDetection(t *testing.T) {
+	t.Parallel()
+
+	catchAllServer := newFakeSMTPServer(t, func(address string) (int, string) {
+		return 250, "OK"
+	})
+	verifier := newTestSMTPVerifier(catchAllServer, validator.SMTPConfig{})
+
+	result := verifier.Verify(context.Background(), "anyone@example.com")
+	if !result.Exists || !result.CatchAll {
+		t.Fatalf("Expected an accepted catch-all result, got %+v", result)
+	}
+
+	// The second probe for the same domain must reuse the cached answer
+	result = verifier.Verify(context.Background(), "someone@example.com")
+	if !result.CatchAll {
+		t.Errorf("Expected cached catch-all result, got %+v", result)
+	}
+	rcptCount := 0
+	for _, command := range catchAllServer.recorded() {
+		if strings.HasPrefix(command, "RCPT") {
+			rcptCount++
+		}
+	}
+	if rcptCount != 3 {
+		t.Errorf("RCPT count = %d, want 3 (two probes plus one catch-all check)", rcptCount)
+	}
+
+	strictServer := newFakeSMTPServer(t, func(address string) (int, string) {
+		if address == "known@example.org" {
+			return 250, "OK"
+		}
+		return 550, "No such user"
+	})
+	verifier = newTestSMTPVerifier(strictServer, validator.SMTPConfig{})
+
+	result = verifier.Verify(context.Background(), "known@example.org")
+	if !result.Exists || result.CatchAll {
+		t.Errorf("Expected an accepted non catch-all result, got %+v", result)
+	}
+}
+
+func TestSMTPVerifierGreylistingRetry(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	attempts := 0
+	server := newFakeSMTPServer(t, func(address string) (int, string) {
+		if address != "greylisted@example.com" {
+			return 550, "No such user"
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			return 450, "4.7.1 Greylisted, try again later"
+		}
+		return 250, "OK"
+	})
+	verifier := newTestSMTPVerifier(server, validator.SMTPConfig{
+		MaxRetries:   2,
+		RetryBackoff: 20 * time.Millisecond,
+	})
+
+	result := verifier.Verify(context.Background(), "greylisted@example.com")
+	if !result.Temporary() || !result.Pending {
+		t.Fatalf("Expected a pending temporary failure, got %+v", result)
+	}
+
+	result = waitForRetry(t, verifier, "greylisted@example.com")
+	if !result.Exists || result.Inconclusive() {
+		t.Errorf("Expected the retry to accept the mailbox, got %+v", result)
+	}
+}
+
+func TestSMTPVerifierRetriesExhausted(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeSMTPServer(t, func(address string) (int, string) {
+		return 451, "4.3.0 Temporary local problem"
+	})
+	verifier := newTestSMTPVerifier(server, validator.SMTPConfig{
+		MaxRetries:   2,
+		RetryBackoff: 10 * time.Millisecond,
+	})
+
+	if result := verifier.Verify(context.Background(), "user@example.com"); !result.Pending {
+		t.Fatalf("Expected a pending result, got %+v", result)
+	}
+
+	result := waitForRetry(t, verifier, "user@example.com")
+	if !result.Temporary() || result.Permanent() || result.Exists {
+		t.Errorf("Expected a final temporary failure, got %+v", result)
+	}
+
+	rcptCount := 0
+	for _, command := range server.recorded() {
+		if strings.HasPrefix(command, "RCPT") {
+			rcptCount++
+		}
+	}
+	if rcptCount != 3 {
+		t.Errorf("RCPT count = %d, want 3 (initial probe plus two retries)", rcptCount)
+	}
+}
+
+func TestSMTPVerifierTrackedRetriesCap(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeSMTPServer(t, func(address string) (int, string) {
+		return 450, "4.7.1 Greylisted, try again later"
+	})
+	verifier := newTestSMTPVerifier(server, validator.SMTPConfig{
+		MaxRetries:        2,
+		RetryBackoff:      time.Hour,
+		MaxTrackedRetries: 2,
+	})
+
+	for _, email := range []string{"first@example.com", "second@example.com"} {
+		if result := verifier.Verify(context.Background(), email); !result.Pending {
+			t.Fatalf("Verify(%s) = %+v, want a pending result", email, result)
+		}
+	}
+
+	// Past the cap the failure isn't retried and stays inconclusive
+	result := verifier.Verify(context.Background(), "third@example.com")
+	if result.Pending || !result.Temporary() || !result.Inconclusive() {
+		t.Errorf("Verify past the cap = %+v, want a temporary failure that isn't pending", result)
+	}
+
+	// Emails already tracked keep their pending retry
+	if result := verifier.Verify(context.Background(), "first@example.com"); !result.Pending {
+		t.Errorf("Verify of a tracked email = %+v, want a pending result", result)
+	}
+}
+
+// waitForRetry polls the verifier until the scheduled retry for the email has finished
+func waitForRetry(t *testing.T, verifier *validator.SMTPVerifier, email string) validator.SMTPResult {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if result := verifier.Verify(context.Background(), email); !result.Pending {
+			return result
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Retry for %s did not finish in time", email)
+	return validator.SMTPResult{}
+}
+
+func TestSMTPVerifierUsesHighestPriorityMX(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeSMTPServer(t, func(address string) (int, string) {
+		return 250, "OK"
+	})
+	resolver := &smtpTestResolver{mx: []*net.MX{
+		{Host: "127.0.0.2", Pref: 20},
+		{Host: "127.0.0.1", Pref: 5},
+	}}
+	verifier := validator.NewSMTPVerifier(resolver, nil, validator.SMTPConfig{Port: server.port()})
+
+	result := verifier.Verify(context.Background(), "user@example.com")
+	if result.MXHost != "127.0.0.1" {
+		t.Errorf("MXHost = %q, want lowest preference host 127.0.0.1", result.MXHost)
+	}
+	if !result.Exists {
+		t.Errorf("Expected mailbox to exist, got %+v", result)
+	}
+}
+
+func TestSMTPVerifierCommandTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := startFakeSMTPServer(t, &fakeSMTPServer{silent: true})
+	verifier := newTestSMTPVerifier(server, validator.SMTPConfig{
+		CommandTimeout: 100 * time.Millisecond,
+	})
+
+	start := time.Now()
+	result := verifier.Verify(context.Background(), "user@example.com")
+	if time.Since(start) > time.Second {
+		t.Errorf("Verify took %v, expected the command timeout to stop it", time.Since(start))
+	}
+	if result.Err == nil || !result.Inconclusive() {
+		t.Errorf("Expected an inconclusive result with an error, got %+v", result)
+	}
+}
+
+func TestEmailValidatorVerifyMailboxDisabled(t *testing.T) {
+	t.Parallel()
+
+	emailValidator, err := validator.NewEmailValidator()
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+
+	result := emailValidator.VerifyMailbox(context.Background(), "user@example.com")
+	if result.Err != validator.ErrSMTPVerificationDisabled {
+		t.Errorf("Err = %v, want %v", result.Err, validator.ErrSMTPVerificationDisabled)
+	}
+}
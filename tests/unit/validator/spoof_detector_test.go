@@ -0,0 +1,95 @@
+package validatortest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"emailvalidator/pkg/validator"
+)
+
+func TestParseConfusables(t *testing.T) {
+	data := "\ufeff# confusables.txt\n" +
+		"0430 ;\t0061 ;\tMA\t# ( а → a ) CYRILLIC SMALL LETTER A → LATIN SMALL LETTER A\t#\n" +
+		"\n" +
+		"006D ;\t0072 006E ;\tMA\t# ( m → rn )\n"
+
+	confusables, err := validator.ParseConfusables(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseConfusables() error = %v", err)
+	}
+	if len(confusables) != 2 || confusables['а'] != "a" || confusables['m'] != "rn" {
+		t.Errorf("ParseConfusables() = %q, want а → a and m → rn", confusables)
+	}
+
+	if _, err := validator.ParseConfusables(strings.NewReader("zz ; 0061 ; MA\n")); err == nil {
+		t.Error("ParseConfusables() with an invalid code point should fail")
+	}
+}
+
+func TestSpoofDetectorCheck(t *testing.T) {
+	detector, err := validator.NewSpoofDetector([]string{"gmail.com", "yahoo.com", "outlook.com"})
+	if err != nil {
+		t.Fatalf("Failed to create spoof detector: %v", err)
+	}
+
+	tests := []struct {
+		domain      string
+		wantTarget  string
+		wantSuspect bool
+	}{
+		{"gmail.com", "", false},
+		{"GMAIL.COM", "", false},
+		{"gmаil.com", "gmail.com", true},        // Cyrillic а
+		{"xn--gmil-63d.com", "gmail.com", true}, // the same in ASCII form
+		{"yаhοο.com", "yahoo.com", true},        // Cyrillic а and Greek ο
+		{"outlооk.com", "outlook.com", true},    // Cyrillic о
+		{"gmaɪl.com", "gmail.com", true},        // Latin small capital ɪ
+		{"ʏahoo.com", "yahoo.com", true},        // Latin small capital ʏ
+		{"oʋtlook.com", "outlook.com", true},    // Latin ʋ with hook
+		{"examplе.org", "", true},               // Latin mixed with Cyrillic, imitating nothing known
+		{"пример.рф", "", false},                // Cyrillic only
+		{"例子.广告", "", false},                    // Han only
+		{"ゲーム.jp", "", false},                   // Katakana only
+		{"bücher.de", "", false},                // Latin with a diacritic
+		{"gmial.com", "", false},                // a typo rather than a lookalike
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			target, suspect := detector.Check(tt.domain)
+			if target != tt.wantTarget || suspect != tt.wantSuspect {
+				t.Errorf("Check(%q) = %q, %v, want %q, %v", tt.domain, target, suspect, tt.wantTarget, tt.wantSuspect)
+			}
+		})
+	}
+}
+
+func TestSpoofDetectorSkeleton(t *testing.T) {
+	detector := validator.NewSpoofDetectorWithConfusables(nil, map[rune]string{'а': "a", 'm': "rn", '1': "l"})
+
+	if a, b := detector.Skeleton("pаypa1"), detector.Skeleton("paypal"); a != b {
+		t.Errorf("Skeleton(pаypa1) = %q, Skeleton(paypal) = %q, want equal", a, b)
+	}
+	if a, b := detector.Skeleton("rnicrosoft"), detector.Skeleton("microsoft"); a != b {
+		t.Errorf("Skeleton(rnicrosoft) = %q, Skeleton(microsoft) = %q, want equal", a, b)
+	}
+	if a, b := detector.Skeleton("gmail"), detector.Skeleton("gmall"); a == b {
+		t.Errorf("Skeleton(gmail) and Skeleton(gmall) = %q, want different", a)
+	}
+}
+
+func TestEmailValidatorSuggestsSpoofedDomain(t *testing.T) {
+	emailValidator, err := validator.NewEmailValidator()
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+
+	if !emailValidator.IsSpoofSuspect("gmаil.com") {
+		t.Error("IsSpoofSuspect(gmаil.com) = false, want true")
+	}
+	suggestions := emailValidator.RankTypoSuggestions(context.Background(), "user@gmаil.com")
+	if len(suggestions) != 1 || suggestions[0].Email != "user@gmail.com" || suggestions[0].Confidence != 1 {
+		t.Errorf("RankTypoSuggestions(user@gmаil.com) = %+v, want user@gmail.com with confidence 1", suggestions)
+	}
+}
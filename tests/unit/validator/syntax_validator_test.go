@@ -2,6 +2,7 @@ package validatortest
 
 import (
 	"emailvalidator/pkg/validator"
+	"strings"
 	"testing"
 )
 
@@ -94,3 +95,73 @@ func TestSyntaxValidatorValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestSyntaxValidatorDiagnose(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		want  string
+	}{
+		{"Valid email", "user@example.com", ""},
+		{"Valid unicode email", "用户@例子.广告", ""},
+		{"Empty", "", validator.ReasonEmptyEmail},
+		{"Too long", strings.Repeat("a", 64) + "@" + strings.Repeat("b", 190) + ".com", validator.ReasonEmailTooLong},
+		{"Quoted string", "\"john doe\"@example.com", validator.ReasonQuotedString},
+		{"Missing @", "user.example.com", validator.ReasonMissingAtSign},
+		{"Multiple @", "user@domain@example.com", validator.ReasonMultipleAtSigns},
+		{"Empty local part", "@example.com", validator.ReasonEmptyLocalPart},
+		{"Empty domain", "user@", validator.ReasonEmptyDomain},
+		{"Local part too long", strings.Repeat("a", 65) + "@example.com", validator.ReasonLocalPartTooLong},
+		{"Consecutive dots", "john..doe@example.com", validator.ReasonConsecutiveDots},
+		{"Leading dot", ".user@example.com", validator.ReasonLocalPartDot},
+		{"Trailing dot", "user.@example.com", validator.ReasonLocalPartDot},
+		{"Label with leading hyphen", "user@-example.com", validator.ReasonBadDomainLabel},
+		{"Label too long", "user@" + strings.Repeat("a", 64) + ".com", validator.ReasonBadDomainLabel},
+		{"Empty label", "user@example..com", validator.ReasonConsecutiveDots},
+		{"Invalid character", "us er@example.com", validator.ReasonInvalidSyntax},
+		{"UTF-8 local part", "José.Müller@example.com", ""},
+		{"Punycode domain", "user@xn--bcher-kva.de", ""},
+		{"Display name", "Bob <bob@example.com>", validator.ReasonInvalidSyntax},
+		{"Comment", "bob(comment)@example.com", validator.ReasonInvalidSyntax},
+		{"Invalid UTF-8", "us\xffer@example.com", validator.ReasonInvalidSyntax},
+		{"Unicode space", "us\u3000er@example.com", validator.ReasonInvalidSyntax},
+		{"Disallowed IDNA character", "user@ex\u2028ample.com", validator.ReasonBadDomainLabel},
+		{"Label too long once encoded", "user@" + strings.Repeat("ü", 60) + ".de", validator.ReasonBadDomainLabel},
+	}
+
+	syntaxValidator := validator.NewSyntaxValidator()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := syntaxValidator.Diagnose(tt.email); got != tt.want {
+				t.Errorf("SyntaxValidator.Diagnose(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyntaxValidatorUnknownTLD(t *testing.T) {
+	syntaxValidator := validator.NewSyntaxValidatorWithTLDs(validator.NewTLDValidatorWithDomains([]string{"COM", "UK", "XN--P1AI"}))
+
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"user@example.com", ""},
+		{"user@EXAMPLE.COM", ""},
+		{"user@example.co.uk", ""},
+		{"user@пример.рф", ""},
+		{"user@foo.notatld", validator.ReasonUnknownTLD},
+		{"user@localhost", ""},
+		{"user@mail.test", ""},
+		{"用户@例子.广告", validator.ReasonUnknownTLD},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := syntaxValidator.Diagnose(tt.email); got != tt.want {
+				t.Errorf("SyntaxValidator.Diagnose(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
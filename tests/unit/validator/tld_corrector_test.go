@@ -0,0 +1,83 @@
+package validatortest
+
+import (
+	"reflect"
+	"testing"
+
+	"emailvalidator/pkg/validator"
+)
+
+func TestTLDValidatorValidate(t *testing.T) {
+	tldValidator, err := validator.NewTLDValidator()
+	if err != nil {
+		t.Fatalf("Failed to create TLD validator: %v", err)
+	}
+
+	tests := []struct {
+		tld  string
+		want bool
+	}{
+		{"com", true},
+		{"COM", true},
+		{".uk", true},
+		{"co", true},
+		{"xn--p1ai", true},
+		{"con", false},
+		{"cmo", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tld, func(t *testing.T) {
+			if got := tldValidator.Validate(tt.tld); got != tt.want {
+				t.Errorf("TLDValidator.Validate(%q) = %v, want %v", tt.tld, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTLDCorrectorSuggest(t *testing.T) {
+	corrector := validator.NewTLDCorrector(validator.NewTLDValidatorWithDomains([]string{"com", "net", "org", "co", "uk", "om"}))
+	withMX := map[string]bool{
+		"acme.com":     true,
+		"acme.net":     true,
+		"shop.co.uk":   true,
+		"bakery.com":   true,
+		"bakery.co.uk": true,
+	}
+	hasMX := func(domain string) bool {
+		return withMX[domain]
+	}
+
+	tests := []struct {
+		name  string
+		email string
+		want  []string
+	}{
+		{"Unknown TLD", "ada@acme.con", []string{"ada@acme.com"}},
+		{"Swapped letters", "ada@acme.cmo", []string{"ada@acme.com"}},
+		{"Existing but unlikely TLD", "ada@acme.om", []string{"ada@acme.com"}},
+		{"Truncated TLD", "ada@acme.co", []string{"ada@acme.com"}},
+		{"Close to several popular TLDs", "ada@acme.nett", []string{"ada@acme.net"}},
+		{".com typed for .co.uk", "ada@shop.com", []string{"ada@shop.co.uk"}},
+		{".co.uk typed for .com", "ada@acme.co.uk", []string{"ada@acme.com"}},
+		{"Correction without MX", "ada@nomail.con", nil},
+		{"Domain as typed has MX", "ada@bakery.com", nil},
+		{"No TLD", "ada@localhost", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, suggestion := range corrector.Suggest(tt.email, hasMX) {
+				if suggestion.Confidence <= 0 || suggestion.Confidence > 1 {
+					t.Errorf("Suggest(%q) confidence = %v, want a value in (0, 1]", tt.email, suggestion.Confidence)
+				}
+				got = append(got, suggestion.Email)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest(%q) = %v, want %v", tt.email, got, tt.want)
+			}
+		})
+	}
+}
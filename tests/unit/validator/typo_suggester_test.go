@@ -0,0 +1,155 @@
+package validatortest
+
+import (
+	"reflect"
+	"testing"
+
+	"emailvalidator/pkg/validator"
+)
+
+func TestTypoSuggesterSuggest(t *testing.T) {
+	suggester := validator.NewTypoSuggesterWithDomains([]string{"gmail.com", "yahoo.com", "hotmail.com", "ymail.com", "protonmail.com"})
+
+	tests := []struct {
+		name  string
+		email string
+		want  []validator.TypoSuggestion
+	}{
+		{
+			name:  "Transposition",
+			email: "ada@gmial.com",
+			want:  []validator.TypoSuggestion{{Email: "ada@gmail.com", Domain: "gmail.com", Confidence: 0.89}},
+		},
+		{
+			name:  "Two edits on a long domain",
+			email: "ada@protnmial.com",
+			want:  []validator.TypoSuggestion{{Email: "ada@protonmail.com", Domain: "protonmail.com", Confidence: 0.86}},
+		},
+		{
+			name:  "Two edits on a short domain",
+			email: "ada@gmeil.co",
+			want:  nil,
+		},
+		{
+			name:  "Neighbouring key ranks above other substitutions",
+			email: "ada@gnail.com",
+			want: []validator.TypoSuggestion{
+				{Email: "ada@gmail.com", Domain: "gmail.com", Confidence: 0.94},
+				{Email: "ada@ymail.com", Domain: "ymail.com", Confidence: 0.89},
+			},
+		},
+		{
+			name:  "Ties go to the more popular domain",
+			email: "ada@xmail.com",
+			want: []validator.TypoSuggestion{
+				{Email: "ada@gmail.com", Domain: "gmail.com", Confidence: 0.89},
+				{Email: "ada@ymail.com", Domain: "ymail.com", Confidence: 0.89},
+			},
+		},
+		{
+			name:  "Domain is case-insensitive",
+			email: "Ada@YAHO.COM",
+			want:  []validator.TypoSuggestion{{Email: "Ada@yahoo.com", Domain: "yahoo.com", Confidence: 0.89}},
+		},
+		{
+			name:  "Known domain",
+			email: "ada@ymail.com",
+			want:  nil,
+		},
+		{
+			name:  "Unrelated domain",
+			email: "ada@acme.io",
+			want:  nil,
+		},
+		{
+			name:  "No domain",
+			email: "ada",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Ask twice so the cached answer is checked too
+			for i := 0; i < 2; i++ {
+				if got := suggester.Suggest(tt.email); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Suggest(%q) = %v, want %v", tt.email, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTypoSuggesterRealDomains(t *testing.T) {
+	// The provider list records common typos too, which stay typos
+	suggester := validator.NewTypoSuggesterWithProviders(
+		[]string{"hotmail.com", "aol.com", "rocketmail.com"},
+		[]string{"rickymail.com", "hotmial.com"},
+	)
+
+	tests := []struct {
+		name  string
+		email string
+		want  []validator.TypoSuggestion
+	}{
+		{
+			name:  "Listed provider",
+			email: "ada@rickymail.com",
+			want:  nil,
+		},
+		{
+			name:  "Listed provider is case-insensitive",
+			email: "ada@RickyMail.COM",
+			want:  nil,
+		},
+		{
+			name:  "Listed near miss of a popular domain",
+			email: "ada@hotmial.com",
+			want:  []validator.TypoSuggestion{{Email: "ada@hotmail.com", Domain: "hotmail.com", Confidence: 0.91}},
+		},
+		{
+			name:  "Neighbouring key of a short label",
+			email: "ada@sol.com",
+			want:  nil,
+		},
+		{
+			name:  "Substitution in a short label",
+			email: "ada@bol.com",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggester.Suggest(tt.email); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest(%q) = %v, want %v", tt.email, got, tt.want)
+			}
+		})
+	}
+
+	for domain, want := range map[string]bool{"aol.com": true, "RICKYMAIL.com": true, "hotmial.com": false, "sol.com": false} {
+		if got := suggester.IsProvider(domain); got != want {
+			t.Errorf("IsProvider(%q) = %v, want %v", domain, got, want)
+		}
+	}
+}
+
+func TestTypoSuggesterKeyboardWeighting(t *testing.T) {
+	suggester := validator.NewTypoSuggesterWithDomains([]string{"gmail.com", "ymail.com"})
+
+	// g and y, and n and m, are neighbouring keys, so ymail.com is one weighted edit away
+	suggester.SetKeyboardWeighted(true)
+	want := []validator.TypoSuggestion{
+		{Email: "ada@gmail.com", Domain: "gmail.com", Confidence: 0.94},
+		{Email: "ada@ymail.com", Domain: "ymail.com", Confidence: 0.89},
+	}
+	if got := suggester.Suggest("ada@gnail.com"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest with keyboard weighting = %v, want %v", got, want)
+	}
+
+	suggester.SetKeyboardWeighted(false)
+	want = []validator.TypoSuggestion{{Email: "ada@gmail.com", Domain: "gmail.com", Confidence: 0.89}}
+	if got := suggester.Suggest("ada@gnail.com"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest without keyboard weighting = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,452 @@
+package validatortest
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"emailvalidator/pkg/validator"
+)
+
+// fakeDNSServer is a minimal in-process DNS server answering over UDP and TCP on the same port
+type fakeDNSServer struct {
+	udp *net.UDPConn
+	tcp net.Listener
+	// rcode, when set, is returned for every query instead of an answer
+	rcode dnsmessage.RCode
+	// silent makes the server read queries without ever answering
+	silent bool
+
+	udpQueries atomic.Int32
+	tcpQueries atomic.Int32
+}
+
+func newFakeDNSServer(t *testing.T) *fakeDNSServer {
+	t.Helper()
+	return startFakeDNSServer(t, &fakeDNSServer{})
+}
+
+func startFakeDNSServer(t *testing.T, server *fakeDNSServer) *fakeDNSServer {
+	t.Helper()
+
+	// The TCP listener needs the port picked for UDP, which may already be taken for TCP
+	for attempt := 0; attempt < 10 && server.tcp == nil; attempt++ {
+		udp, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		if err != nil {
+			t.Fatalf("Failed to start fake DNS server: %v", err)
+		}
+		tcp, err := net.Listen("tcp", udp.LocalAddr().String())
+		if err != nil {
+			_ = udp.Close()
+			continue
+		}
+		server.udp, server.tcp = udp, tcp
+	}
+	if server.tcp == nil {
+		t.Fatal("Failed to find a free port for the fake DNS server")
+	}
+
+	go server.serveUDP()
+	go server.serveStream(server.tcp)
+	t.Cleanup(func() {
+		_ = server.udp.Close()
+		_ = server.tcp.Close()
+	})
+	return server
+}
+
+func (s *fakeDNSServer) addr() string {
+	return s.udp.LocalAddr().String()
+}
+
+func (s *fakeDNSServer) serveUDP() {
+	buffer := make([]byte, 65535)
+	for {
+		n, from, err := s.udp.ReadFromUDP(buffer)
+		if err != nil {
+			return
+		}
+		s.udpQueries.Add(1)
+		if s.silent {
+			continue
+		}
+		if response, ok := s.answer(buffer[:n], true); ok {
+			_, _ = s.udp.WriteToUDP(response, from)
+		}
+	}
+}
+
+// serveStream answers queries with the two-byte length prefix used over TCP and TLS
+func (s *fakeDNSServer) serveStream(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer func() {
+				_ = conn.Close()
+			}()
+			var length [2]byte
+			if _, err := io.ReadFull(conn, length[:]); err != nil {
+				return
+			}
+			query := make([]byte, binary.BigEndian.Uint16(length[:]))
+			if _, err := io.ReadFull(conn, query); err != nil {
+				return
+			}
+			s.tcpQueries.Add(1)
+			if s.silent {
+				return
+			}
+			if response, ok := s.answer(query, false); ok {
+				binary.BigEndian.PutUint16(length[:], uint16(len(response)))
+				_, _ = conn.Write(append(length[:], response...))
+			}
+		}()
+	}
+}
+
+// answer builds the response to a packed query from a small fixed zone.
+// big.net answers are too large for UDP, so they are truncated there
+func (s *fakeDNSServer) answer(packed []byte, udp bool) ([]byte, bool) {
+	var query dnsmessage.Message
+	if err := query.Unpack(packed); err != nil || len(query.Questions) != 1 {
+		return nil, false
+	}
+	question := query.Questions[0]
+	header := dnsmessage.Header{ID: query.ID, Response: true, RecursionDesired: true, RecursionAvailable: true}
+
+	domain := question.Name.String()
+	known := map[string]bool{"acme.io.": true, "nomx.net.": true, "nullmx.net.": true, "big.net.": true}
+	switch {
+	case s.rcode != dnsmessage.RCodeSuccess:
+		header.RCode = s.rcode
+	case !known[domain]:
+		header.RCode = dnsmessage.RCodeNameError
+	case udp && domain == "big.net." && question.Type == dnsmessage.TypeMX:
+		header.Truncated = true
+	}
+
+	builder := dnsmessage.NewBuilder(nil, header)
+	_ = builder.StartQuestions()
+	_ = builder.Question(question)
+	_ = builder.StartAnswers()
+	answered := false
+	if header.RCode == dnsmessage.RCodeSuccess && !header.Truncated {
+		resource := dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: 300}
+		mx := func(pref uint16, host string) {
+			_ = builder.MXResource(resource, dnsmessage.MXResource{Pref: pref, MX: dnsmessage.MustNewName(host)})
+			answered = true
+		}
+		switch question.Type {
+		case dnsmessage.TypeA:
+			_ = builder.AResource(resource, dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}})
+			answered = true
+		case dnsmessage.TypeAAAA:
+			if domain == "acme.io." {
+				_ = builder.AAAAResource(resource, dnsmessage.AAAAResource{AAAA: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}})
+				answered = true
+			}
+		case dnsmessage.TypeMX:
+			switch domain {
+			case "acme.io.":
+				mx(20, "backup.acme.io.")
+				mx(10, "mail.acme.io.")
+			case "nullmx.net.":
+				mx(0, ".")
+			case "big.net.":
+				mx(10, "mail.big.net.")
+			}
+		}
+	}
+
+	// Missing domains and records come with the zone's SOA, whose minimum sets the negative caching TTL
+	if !answered && !header.Truncated && (header.RCode == dnsmessage.RCodeSuccess || header.RCode == dnsmessage.RCodeNameError) {
+		_ = builder.StartAuthorities()
+		soa := dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName("net."), Class: dnsmessage.ClassINET, TTL: 900}
+		_ = builder.SOAResource(soa, dnsmessage.SOAResource{
+			NS:     dnsmessage.MustNewName("ns.net."),
+			MBox:   dnsmessage.MustNewName("hostmaster.net."),
+			Serial: 1, Refresh: 1800, Retry: 900, Expire: 604800, MinTTL: 60,
+		})
+	}
+	response, err := builder.Finish()
+	return response, err == nil
+}
+
+func newTestUpstreamResolver(t *testing.T, servers ...string) *validator.UpstreamResolver {
+	t.Helper()
+	resolver, err := validator.NewUpstreamResolver(validator.ResolverConfig{
+		Servers: servers,
+		Timeout: 100 * time.Millisecond,
+		Retries: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewUpstreamResolver() error = %v", err)
+	}
+	return resolver
+}
+
+func TestUpstreamResolverLookups(t *testing.T) {
+	server := newFakeDNSServer(t)
+	resolver := newTestUpstreamResolver(t, server.addr())
+	ctx := context.Background()
+
+	addresses, _, err := resolver.LookupHost(ctx, "acme.io")
+	if err != nil {
+		t.Fatalf("LookupHost() error = %v", err)
+	}
+	if len(addresses) != 2 || addresses[0] != "192.0.2.1" || addresses[1] != "2001:db8::1" {
+		t.Errorf("LookupHost() = %v, want [192.0.2.1 2001:db8::1]", addresses)
+	}
+
+	records, _, err := resolver.LookupMX(ctx, "acme.io")
+	if err != nil {
+		t.Fatalf("LookupMX() error = %v", err)
+	}
+	if len(records) != 2 || records[0].Host != "mail.acme.io." || records[0].Pref != 10 || records[1].Host != "backup.acme.io." {
+		t.Errorf("LookupMX() = %v, want mail.acme.io. first", records)
+	}
+
+	_, _, err = resolver.LookupHost(ctx, "missing.net")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("LookupHost(missing.net) error = %v, want a not found DNSError", err)
+	}
+
+	// Truncated UDP answers are asked again over TCP
+	records, _, err = resolver.LookupMX(ctx, "big.net")
+	if err != nil || len(records) != 1 || records[0].Host != "mail.big.net." {
+		t.Errorf("LookupMX(big.net) = %v, %v, want mail.big.net.", records, err)
+	}
+	if server.tcpQueries.Load() != 1 {
+		t.Errorf("TCP queries = %d, want 1", server.tcpQueries.Load())
+	}
+}
+
+func TestUpstreamResolverTTLs(t *testing.T) {
+	server := newFakeDNSServer(t)
+	resolver := newTestUpstreamResolver(t, server.addr())
+	ctx := context.Background()
+
+	if _, ttl, err := resolver.LookupMX(ctx, "acme.io"); err != nil || ttl != 300*time.Second {
+		t.Errorf("LookupMX(acme.io) TTL = %v, %v, want 5m0s", ttl, err)
+	}
+	if _, ttl, err := resolver.LookupHost(ctx, "acme.io"); err != nil || ttl != 300*time.Second {
+		t.Errorf("LookupHost(acme.io) TTL = %v, %v, want 5m0s", ttl, err)
+	}
+
+	// Negative answers are cached for the lower of the SOA TTL and its minimum (RFC 2308)
+	if _, ttl, err := resolver.LookupHost(ctx, "missing.net"); err == nil || ttl != time.Minute {
+		t.Errorf("LookupHost(missing.net) TTL = %v, %v, want 1m0s and an error", ttl, err)
+	}
+	if records, ttl, err := resolver.LookupMX(ctx, "nomx.net"); err != nil || len(records) != 0 || ttl != time.Minute {
+		t.Errorf("LookupMX(nomx.net) = %v, %v, %v, want no records for 1m0s", records, ttl, err)
+	}
+
+	failing := startFakeDNSServer(t, &fakeDNSServer{rcode: dnsmessage.RCodeServerFailure})
+	resolver = newTestUpstreamResolver(t, failing.addr())
+	if _, ttl, err := resolver.LookupMX(ctx, "acme.io"); err == nil || ttl != validator.UnknownTTL {
+		t.Errorf("LookupMX() on SERVFAIL TTL = %v, %v, want UnknownTTL and an error", ttl, err)
+	}
+}
+
+func TestUpstreamResolverReasons(t *testing.T) {
+	server := newFakeDNSServer(t)
+	emailValidator, err := validator.NewEmailValidatorWithResolver(newTestUpstreamResolver(t, server.addr()))
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+
+	tests := []struct {
+		domain     string
+		wantExists bool
+		wantMX     bool
+		wantReason string
+	}{
+		{"acme.io", true, true, ""},
+		{"missing.net", false, false, validator.ReasonNXDomain},
+		{"nomx.net", true, false, validator.ReasonNoMXRecords},
+		{"nullmx.net", true, false, validator.ReasonNullMX},
+	}
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			exists, _ := emailValidator.CheckDomain(context.Background(), tt.domain)
+			hasMX, reason := emailValidator.CheckMXRecords(context.Background(), tt.domain)
+			if exists != tt.wantExists || hasMX != tt.wantMX || reason != tt.wantReason {
+				t.Errorf("CheckDomain, CheckMXRecords(%q) = %v, %v, %q, want %v, %v, %q",
+					tt.domain, exists, hasMX, reason, tt.wantExists, tt.wantMX, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestUpstreamResolverFailover(t *testing.T) {
+	silent := startFakeDNSServer(t, &fakeDNSServer{silent: true})
+	failing := startFakeDNSServer(t, &fakeDNSServer{rcode: dnsmessage.RCodeServerFailure})
+	good := newFakeDNSServer(t)
+
+	// A server that times out is retried, one that fails is skipped, until one answers
+	resolver := newTestUpstreamResolver(t, silent.addr(), failing.addr(), good.addr())
+	if addresses, _, err := resolver.LookupHost(context.Background(), "nomx.net"); err != nil || len(addresses) != 1 {
+		t.Fatalf("LookupHost() = %v, %v, want one address", addresses, err)
+	}
+	// Both the A and AAAA queries try the silent server twice and the failing one once
+	if got := silent.udpQueries.Load(); got != 4 {
+		t.Errorf("silent server queries = %d, want 4", got)
+	}
+	if got := failing.udpQueries.Load(); got != 2 {
+		t.Errorf("failing server queries = %d, want 2", got)
+	}
+
+	tests := []struct {
+		name   string
+		server *fakeDNSServer
+		want   string
+	}{
+		{"timeout", silent, validator.ReasonDNSTimeout},
+		{"servfail", failing, validator.ReasonServFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emailValidator, err := validator.NewEmailValidatorWithResolver(newTestUpstreamResolver(t, tt.server.addr()))
+			if err != nil {
+				t.Fatalf("Failed to create validator: %v", err)
+			}
+			if exists, reason := emailValidator.CheckDomain(context.Background(), "acme.io"); exists || reason != tt.want {
+				t.Errorf("CheckDomain() = %v, %q, want false, %q", exists, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpstreamResolverContext(t *testing.T) {
+	silent := startFakeDNSServer(t, &fakeDNSServer{silent: true})
+	resolver, err := validator.NewUpstreamResolver(validator.ResolverConfig{Servers: []string{silent.addr()}, Timeout: time.Minute})
+	if err != nil {
+		t.Fatalf("NewUpstreamResolver() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, _, err := resolver.LookupMX(ctx, "acme.io"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("LookupMX() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("LookupMX() took %v, want it to stop with the context", elapsed)
+	}
+
+	if _, err := validator.NewUpstreamResolver(validator.ResolverConfig{}); !errors.Is(err, validator.ErrNoUpstreamServers) {
+		t.Errorf("NewUpstreamResolver() without servers error = %v, want ErrNoUpstreamServers", err)
+	}
+}
+
+// startEncryptedDNSServers starts stand-in DNS-over-HTTPS and DNS-over-TLS servers answering from the zone
+// of the fake DNS server, and returns their addresses and the TLS config trusting their certificate
+func startEncryptedDNSServers(t *testing.T, zone *fakeDNSServer) (dohURL, dotAddr string, config *tls.Config) {
+	t.Helper()
+
+	doh := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, err := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/dns-message" || err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		// RFC 8484 asks DoH clients for message ID 0 so responses can be cached
+		if len(query) < 2 || binary.BigEndian.Uint16(query) != 0 {
+			http.Error(w, "nonzero message ID", http.StatusBadRequest)
+			return
+		}
+		zone.tcpQueries.Add(1)
+		response, ok := zone.answer(query, false)
+		if !ok {
+			http.Error(w, "bad query", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/dns-message")
+		_, _ = w.Write(response)
+	}))
+	t.Cleanup(doh.Close)
+
+	dot, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: doh.TLS.Certificates})
+	if err != nil {
+		t.Fatalf("Failed to start DNS-over-TLS server: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = dot.Close()
+	})
+	go zone.serveStream(dot)
+
+	roots := doh.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	return doh.URL + "/dns-query", dot.Addr().String(), &tls.Config{RootCAs: roots}
+}
+
+func TestEncryptedUpstreamResolvers(t *testing.T) {
+	dohURL, dotAddr, trusted := startEncryptedDNSServers(t, &fakeDNSServer{})
+
+	tests := []struct {
+		protocol string
+		server   string
+	}{
+		{validator.DNSProtocolHTTPS, dohURL},
+		{validator.DNSProtocolTLS, dotAddr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			resolver, err := validator.NewUpstreamResolver(validator.ResolverConfig{
+				Servers:   []string{tt.server},
+				Protocol:  tt.protocol,
+				TLSConfig: trusted,
+				Timeout:   time.Second,
+			})
+			if err != nil {
+				t.Fatalf("NewUpstreamResolver() error = %v", err)
+			}
+
+			records, _, err := resolver.LookupMX(context.Background(), "acme.io")
+			if err != nil || len(records) != 2 || records[0].Host != "mail.acme.io." {
+				t.Errorf("LookupMX() = %v, %v, want mail.acme.io. first", records, err)
+			}
+
+			emailValidator, err := validator.NewEmailValidatorWithResolver(resolver)
+			if err != nil {
+				t.Fatalf("Failed to create validator: %v", err)
+			}
+			if exists, reason := emailValidator.CheckDomain(context.Background(), "missing.net"); exists || reason != validator.ReasonNXDomain {
+				t.Errorf("CheckDomain(missing.net) = %v, %q, want false, %q", exists, reason, validator.ReasonNXDomain)
+			}
+			if hasMX, reason := emailValidator.CheckMXRecords(context.Background(), "nullmx.net"); hasMX || reason != validator.ReasonNullMX {
+				t.Errorf("CheckMXRecords(nullmx.net) = %v, %q, want false, %q", hasMX, reason, validator.ReasonNullMX)
+			}
+
+			// Servers whose certificate isn't trusted are never asked
+			untrusted, err := validator.NewUpstreamResolver(validator.ResolverConfig{
+				Servers:  []string{tt.server},
+				Protocol: tt.protocol,
+				Timeout:  time.Second,
+			})
+			if err != nil {
+				t.Fatalf("NewUpstreamResolver() error = %v", err)
+			}
+			if _, _, err := untrusted.LookupHost(context.Background(), "acme.io"); err == nil {
+				t.Errorf("LookupHost() through a server with an untrusted certificate succeeded, want an error")
+			}
+		})
+	}
+
+	if _, err := validator.NewUpstreamResolver(validator.ResolverConfig{Servers: []string{dotAddr}, Protocol: "quic"}); !errors.Is(err, validator.ErrUnknownDNSProtocol) {
+		t.Errorf("NewUpstreamResolver() with protocol quic error = %v, want ErrUnknownDNSProtocol", err)
+	}
+}
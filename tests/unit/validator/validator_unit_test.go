@@ -2,8 +2,10 @@
 package validatortest
 
 import (
+	"context"
 	"emailvalidator/pkg/validator"
 	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -188,6 +190,54 @@ func TestGetTypoSuggestions(t *testing.T) {
 			want:    []string{"user@outlook.com"},
 			wantLen: 1,
 		},
+		{
+			name:    "Gmail typo - doubled letter",
+			email:   "user@gmaill.com",
+			want:    []string{"user@gmail.com"},
+			wantLen: 1,
+		},
+		{
+			name:    "Proton Mail typo",
+			email:   "user@protonmial.com",
+			want:    []string{"user@protonmail.com"},
+			wantLen: 1,
+		},
+		{
+			name:    "Unrelated domain",
+			email:   "user@example.com",
+			want:    nil,
+			wantLen: 0,
+		},
+		{
+			name:    "Real domain next to a popular one",
+			email:   "user@sol.com",
+			want:    nil,
+			wantLen: 0,
+		},
+		{
+			name:    "Real domain with a substitution",
+			email:   "user@bol.com",
+			want:    nil,
+			wantLen: 0,
+		},
+		{
+			name:    "Gmail typo - transposed letters",
+			email:   "user@gamil.com",
+			want:    []string{"user@gmail.com"},
+			wantLen: 1,
+		},
+		{
+			name:    "Typosquat that receives mail",
+			email:   "user@hotmial.com",
+			want:    []string{"user@hotmail.com"},
+			wantLen: 1,
+		},
+		{
+			name:    "Listed provider",
+			email:   "user@rickymail.com",
+			want:    nil,
+			wantLen: 0,
+		},
 		{
 			name:    "No typo",
 			email:   "user@gmail.com",
@@ -202,14 +252,17 @@ func TestGetTypoSuggestions(t *testing.T) {
 		},
 	}
 
-	validator, err := validator.NewEmailValidator()
+	// Typosquatters usually set up mail servers for the domains they register
+	resolver := NewMockResolver()
+	resolver.validMX["hotmial.com"] = true
+	validator, err := validator.NewEmailValidatorWithResolver(resolver)
 	if err != nil {
 		t.Fatalf("Failed to create validator: %v", err)
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := validator.GetTypoSuggestions(tt.email)
+			got := validator.GetTypoSuggestions(context.Background(), tt.email)
 			if len(got) != tt.wantLen {
 				t.Errorf("GetTypoSuggestions(%q) returned %d suggestions, want %d", tt.email, len(got), tt.wantLen)
 			}
@@ -302,28 +355,28 @@ func NewMockResolver() *MockResolver {
 	}
 }
 
-func (r *MockResolver) LookupHost(domain string) ([]string, error) {
+func (r *MockResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
 	if r.delay > 0 {
 		time.Sleep(r.delay)
 	}
 	if r.validDomains[domain] {
-		return []string{"192.0.2.1"}, nil
+		return []string{"192.0.2.1"}, validator.UnknownTTL, nil
 	}
-	return nil, &net.DNSError{
+	return nil, validator.UnknownTTL, &net.DNSError{
 		Err:        "no such host",
 		Name:       domain,
 		IsNotFound: true,
 	}
 }
 
-func (r *MockResolver) LookupMX(domain string) ([]*net.MX, error) {
+func (r *MockResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
 	if r.delay > 0 {
 		time.Sleep(r.delay)
 	}
 	if r.validMX[domain] {
-		return []*net.MX{{Host: "mail." + domain, Pref: 10}}, nil
+		return []*net.MX{{Host: "mail." + domain, Pref: 10}}, validator.UnknownTTL, nil
 	}
-	return nil, &net.DNSError{
+	return nil, validator.UnknownTTL, &net.DNSError{
 		Err:        "no such host",
 		Name:       domain,
 		IsNotFound: true,
@@ -390,12 +443,12 @@ func TestDomainValidation(t *testing.T) {
 
 			mockResolver.delay = tt.setupDelay
 
-			exists := validator.ValidateDomain(tt.domain)
+			exists := validator.ValidateDomain(context.Background(), tt.domain)
 			if exists != tt.wantExists {
 				t.Errorf("ValidateDomain(%q) = %v, want %v", tt.domain, exists, tt.wantExists)
 			}
 
-			mxExists := validator.ValidateMXRecords(tt.domain)
+			mxExists := validator.ValidateMXRecords(context.Background(), tt.domain)
 			if mxExists != tt.wantMXRecords {
 				t.Errorf("ValidateMXRecords(%q) = %v, want %v", tt.domain, mxExists, tt.wantMXRecords)
 			}
@@ -408,7 +461,7 @@ func TestDomainValidation(t *testing.T) {
 				mockResolver.delay = time.Second
 
 				start := time.Now()
-				exists = validator.ValidateDomain(tt.domain)
+				exists = validator.ValidateDomain(context.Background(), tt.domain)
 				duration := time.Since(start)
 
 				if duration > time.Millisecond*20 {
@@ -435,7 +488,7 @@ func TestCacheExpiration(t *testing.T) {
 
 	domain := "example.com"
 
-	exists := validator.ValidateDomain(domain)
+	exists := validator.ValidateDomain(context.Background(), domain)
 	if !exists {
 		t.Errorf("First check failed: domain should exist")
 	}
@@ -444,8 +497,86 @@ func TestCacheExpiration(t *testing.T) {
 
 	mockResolver.validDomains[domain] = false
 
-	exists = validator.ValidateDomain(domain)
+	exists = validator.ValidateDomain(context.Background(), domain)
 	if exists {
 		t.Error("Got cached result after expiration")
 	}
 }
+
+// hangingResolver answers every lookup, except while hang is set, when lookups wait for their context
+type hangingResolver struct {
+	hang    atomic.Bool
+	lookups atomic.Int32
+}
+
+func (r *hangingResolver) LookupHost(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	if err := r.wait(ctx); err != nil {
+		return nil, validator.UnknownTTL, err
+	}
+	return []string{"192.0.2.1"}, validator.UnknownTTL, nil
+}
+
+func (r *hangingResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
+	if err := r.wait(ctx); err != nil {
+		return nil, validator.UnknownTTL, err
+	}
+	return []*net.MX{{Host: "mail." + domain, Pref: 10}}, validator.UnknownTTL, nil
+}
+
+func (r *hangingResolver) wait(ctx context.Context) error {
+	r.lookups.Add(1)
+	if !r.hang.Load() {
+		return nil
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestDomainValidationContext(t *testing.T) {
+	t.Parallel()
+
+	resolver := &hangingResolver{}
+	resolver.hang.Store(true)
+	emailValidator, err := validator.NewEmailValidatorWithResolver(resolver)
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	exists, reason := emailValidator.CheckDomain(ctx, "acme.io")
+	hasMX, mxReason := emailValidator.CheckMXRecords(ctx, "acme.io")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("lookups took %v, want them to stop at the deadline", elapsed)
+	}
+	if exists || reason != validator.ReasonDNSTimeout {
+		t.Errorf("CheckDomain() = %v, %q, want false, %q", exists, reason, validator.ReasonDNSTimeout)
+	}
+	if hasMX || mxReason != validator.ReasonDNSTimeout {
+		t.Errorf("CheckMXRecords() = %v, %q, want false, %q", hasMX, mxReason, validator.ReasonDNSTimeout)
+	}
+
+	// Lookups cut short aren't cached, so the domain resolves once DNS answers
+	resolver.hang.Store(false)
+	if exists, reason := emailValidator.CheckDomain(context.Background(), "acme.io"); !exists {
+		t.Errorf("CheckDomain() after the timeout = false, %q, want true", reason)
+	}
+	if hasMX, reason := emailValidator.CheckMXRecords(context.Background(), "acme.io"); !hasMX {
+		t.Errorf("CheckMXRecords() after the timeout = false, %q, want true", reason)
+	}
+
+	// Resolvers without context support aren't called once the context is done
+	plain := NewMockResolver()
+	emailValidator.SetResolver(plain)
+	plain.delay = time.Second
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	start = time.Now()
+	if exists, reason := emailValidator.CheckDomain(ctx, "gmail.com"); exists || reason != validator.ReasonDNSTimeout {
+		t.Errorf("CheckDomain() with a cancelled context = %v, %q, want false, %q", exists, reason, validator.ReasonDNSTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("cancelled lookup took %v, want it skipped", elapsed)
+	}
+}